@@ -17,7 +17,10 @@ func NewCmdAdmin() *cobra.Command {
   doryctl admin apply -f users.yaml -f custom-steps.json
 
   # delete configuration items, admin permission required
-  doryctl admin delete step customStepName1`)
+  doryctl admin delete step customStepName1
+
+  # edit configuration item in editor, admin permission required
+  doryctl admin edit env test`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -36,5 +39,6 @@ func NewCmdAdmin() *cobra.Command {
 	cmd.AddCommand(NewCmdAdminGet())
 	cmd.AddCommand(NewCmdAdminApply())
 	cmd.AddCommand(NewCmdAdminDelete())
+	cmd.AddCommand(NewCmdAdminEdit())
 	return cmd
 }
@@ -9,7 +9,6 @@ import (
 	"gopkg.in/yaml.v3"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -669,218 +668,171 @@ func (o *OptionsAdminApply) Run(args []string) error {
 
 	if !o.Try {
 		for _, item := range o.Param.Items {
-			logHeader := fmt.Sprintf("%s/%s", item.Kind, item.Metadata.Name)
-
-			switch item.Kind {
-			case "user":
-				var user pkg.User
-				switch v := item.Spec.(type) {
-				case pkg.User:
-					user = v
-				}
-				param := map[string]interface{}{}
-				bs, _ := json.Marshal(user)
-				_ = json.Unmarshal(bs, &param)
-				result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/user"), http.MethodPut, "", param, false)
-				if err != nil {
-					return err
-				}
-				msg := result.Get("msg").String()
-				log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
-			case "customStepConf":
-				param := map[string]interface{}{
-					"customStepNames": []string{item.Metadata.Name},
-					"page":            1,
-					"perPage":         1000,
-				}
-				result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/customStepConfs"), http.MethodPost, "", param, false)
-				if err != nil {
-					return err
-				}
-				customStepNames := []string{}
-				err = json.Unmarshal([]byte(result.Get("data.customStepNames").Raw), &customStepNames)
-				if err != nil {
-					return err
-				}
-				var found bool
-				for _, name := range customStepNames {
-					if name == item.Metadata.Name {
-						found = true
-						break
-					}
-				}
+			err = o.ApplyAdminKind(item)
+			if err != nil {
+				return err
+			}
+		}
+	}
 
-				m := map[string]interface{}{}
-				bs, _ := json.Marshal(item.Spec)
-				_ = json.Unmarshal(bs, &m)
-				pm := pkg.RemoveMapEmptyItems(m)
-				bs, _ = pkg.YamlIndent(pm)
-				customStepConfYaml := string(bs)
-
-				var op string
-				if found {
-					// update
-					op = "update"
-					param := map[string]interface{}{
-						"customStepConfYaml": customStepConfYaml,
-					}
-					result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/customStepConf/%s", item.Metadata.Name), http.MethodPost, "", param, false)
-					if err != nil {
-						return err
-					}
-					msg := result.Get("msg").String()
-					log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
-				} else {
-					// add
-					op = "add"
-					param := map[string]interface{}{
-						"customStepConfYaml": customStepConfYaml,
-					}
-					result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/customStepConf"), http.MethodPost, "", param, false)
-					if err != nil {
-						return err
-					}
-					msg := result.Get("msg").String()
-					log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
-				}
-			case "envK8s":
-				param := map[string]interface{}{}
-				result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/envNames"), http.MethodGet, "", param, false)
-				if err != nil {
-					return err
-				}
-				envNames := []string{}
-				err = json.Unmarshal([]byte(result.Get("data.envNames").Raw), &envNames)
-				if err != nil {
-					return err
-				}
-				var found bool
-				for _, name := range envNames {
-					if name == item.Metadata.Name {
-						found = true
-						break
-					}
-				}
+	return err
+}
 
-				m := map[string]interface{}{}
-				bs, _ := json.Marshal(item.Spec)
-				_ = json.Unmarshal(bs, &m)
-				pm := pkg.RemoveMapEmptyItems(m)
-				bs, _ = pkg.YamlIndent(pm)
-				envK8sYaml := string(bs)
-
-				var auditID string
-				var op string
-				if found {
-					// update
-					op = "update"
-					param := map[string]interface{}{
-						"envK8sYaml": envK8sYaml,
-					}
-					result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/env/%s", item.Metadata.Name), http.MethodPost, "", param, false)
-					if err != nil {
-						return err
-					}
-					msg := result.Get("msg").String()
-					log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
-					auditID = result.Get("data.auditID").String()
-				} else {
-					// add
-					op = "add"
-					param := map[string]interface{}{
-						"envK8sYaml": envK8sYaml,
-					}
-					result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/env"), http.MethodPost, "", param, false)
-					if err != nil {
-						return err
-					}
-					msg := result.Get("msg").String()
-					log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
-					auditID = result.Get("data.auditID").String()
-				}
+// ApplyAdminKind add or update the configuration item in dory-core server
+func (o *OptionsCommon) ApplyAdminKind(item pkg.AdminKind) error {
+	var err error
+	logHeader := fmt.Sprintf("%s/%s", item.Kind, item.Metadata.Name)
 
-				if auditID == "" {
-					err = fmt.Errorf("can not get auditID")
-					return err
-				}
+	switch item.Kind {
+	case "user":
+		var user pkg.User
+		switch v := item.Spec.(type) {
+		case pkg.User:
+			user = v
+		}
+		msg, err := o.Client().ApplyUser(user)
+		if err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
+	case "customStepConf":
+		customStepNames, err := o.Client().GetCustomStepNames()
+		if err != nil {
+			return err
+		}
+		var found bool
+		for _, name := range customStepNames {
+			if name == item.Metadata.Name {
+				found = true
+				break
+			}
+		}
 
-				url := fmt.Sprintf("api/ws/log/audit/admin/%s", auditID)
-				err = o.QueryWebsocket(url, "", []string{})
-				if err != nil {
-					return err
-				}
-				log.Info(fmt.Sprintf("##############################"))
-				log.Success(fmt.Sprintf("# %s %s finish", logHeader, op))
-			case "componentTemplate":
-				param := map[string]interface{}{
-					"page":    1,
-					"perPage": 1000,
-				}
-				result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/componentTemplates"), http.MethodPost, "", param, false)
-				if err != nil {
-					return err
-				}
-				componentTemplates := []pkg.ComponentTemplate{}
-				err = json.Unmarshal([]byte(result.Get("data.componentTemplates").Raw), &componentTemplates)
-				if err != nil {
-					return err
-				}
-				var found bool
-				for _, tpl := range componentTemplates {
-					if tpl.ComponentTemplateName == item.Metadata.Name {
-						found = true
-						break
-					}
-				}
+		m := map[string]interface{}{}
+		bs, _ := json.Marshal(item.Spec)
+		_ = json.Unmarshal(bs, &m)
+		pm := pkg.RemoveMapEmptyItems(m)
+		bs, _ = pkg.YamlIndent(pm)
+		customStepConfYaml := string(bs)
 
-				var componentTemplateDesc string
-				var deploySpecStatic pkg.DeploySpecStatic
-				switch tpl := item.Spec.(type) {
-				case pkg.ComponentTemplate:
-					componentTemplateDesc = tpl.ComponentTemplateDesc
-					deploySpecStatic = tpl.DeploySpecStatic
-				}
+		var op string
+		if found {
+			// update
+			op = "update"
+			msg, err := o.Client().UpdateCustomStepConf(item.Metadata.Name, customStepConfYaml)
+			if err != nil {
+				return err
+			}
+			log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
+		} else {
+			// add
+			op = "add"
+			msg, err := o.Client().AddCustomStepConf(customStepConfYaml)
+			if err != nil {
+				return err
+			}
+			log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
+		}
+	case "envK8s":
+		envNames, err := o.Client().GetEnvNames()
+		if err != nil {
+			return err
+		}
+		var found bool
+		for _, name := range envNames {
+			if name == item.Metadata.Name {
+				found = true
+				break
+			}
+		}
 
-				m := map[string]interface{}{}
-				bs, _ := json.Marshal(deploySpecStatic)
-				_ = json.Unmarshal(bs, &m)
-				pm := pkg.RemoveMapEmptyItems(m)
-				bs, _ = pkg.YamlIndent(pm)
-				componentTemplateYaml := string(bs)
-
-				var op string
-				if found {
-					// update
-					op = "update"
-					param := map[string]interface{}{
-						"componentTemplateName": item.Metadata.Name,
-						"componentTemplateDesc": componentTemplateDesc,
-						"componentTemplateYaml": componentTemplateYaml,
-					}
-					result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/componentTemplate/%s", item.Metadata.Name), http.MethodPost, "", param, false)
-					if err != nil {
-						return err
-					}
-					msg := result.Get("msg").String()
-					log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
-				} else {
-					// add
-					op = "add"
-					param := map[string]interface{}{
-						"componentTemplateName": item.Metadata.Name,
-						"componentTemplateDesc": componentTemplateDesc,
-						"componentTemplateYaml": componentTemplateYaml,
-					}
-					result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/componentTemplate"), http.MethodPost, "", param, false)
-					if err != nil {
-						return err
-					}
-					msg := result.Get("msg").String()
-					log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
-				}
+		m := map[string]interface{}{}
+		bs, _ := json.Marshal(item.Spec)
+		_ = json.Unmarshal(bs, &m)
+		pm := pkg.RemoveMapEmptyItems(m)
+		bs, _ = pkg.YamlIndent(pm)
+		envK8sYaml := string(bs)
+
+		var auditID string
+		var op string
+		if found {
+			// update
+			op = "update"
+			msg, id, err := o.Client().UpdateEnv(item.Metadata.Name, envK8sYaml)
+			if err != nil {
+				return err
 			}
+			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
+			auditID = id
+		} else {
+			// add
+			op = "add"
+			msg, id, err := o.Client().AddEnv(envK8sYaml)
+			if err != nil {
+				return err
+			}
+			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
+			auditID = id
+		}
+
+		if auditID == "" {
+			err = fmt.Errorf("can not get auditID")
+			return err
 		}
-	}
 
+		url := fmt.Sprintf("api/ws/log/audit/admin/%s", auditID)
+		err = o.QueryWebsocket(url, "", []string{})
+		if err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("##############################"))
+		log.Success(fmt.Sprintf("# %s %s finish", logHeader, op))
+	case "componentTemplate":
+		componentTemplates, err := o.Client().ListAllComponentTemplates()
+		if err != nil {
+			return err
+		}
+		var found bool
+		for _, tpl := range componentTemplates {
+			if tpl.ComponentTemplateName == item.Metadata.Name {
+				found = true
+				break
+			}
+		}
+
+		var componentTemplateDesc string
+		var deploySpecStatic pkg.DeploySpecStatic
+		switch tpl := item.Spec.(type) {
+		case pkg.ComponentTemplate:
+			componentTemplateDesc = tpl.ComponentTemplateDesc
+			deploySpecStatic = tpl.DeploySpecStatic
+		}
+
+		m := map[string]interface{}{}
+		bs, _ := json.Marshal(deploySpecStatic)
+		_ = json.Unmarshal(bs, &m)
+		pm := pkg.RemoveMapEmptyItems(m)
+		bs, _ = pkg.YamlIndent(pm)
+		componentTemplateYaml := string(bs)
+
+		var op string
+		if found {
+			// update
+			op = "update"
+			msg, err := o.Client().UpdateComponentTemplate(item.Metadata.Name, componentTemplateDesc, componentTemplateYaml)
+			if err != nil {
+				return err
+			}
+			log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
+		} else {
+			// add
+			op = "add"
+			msg, err := o.Client().AddComponentTemplate(item.Metadata.Name, componentTemplateDesc, componentTemplateYaml)
+			if err != nil {
+				return err
+			}
+			log.Info(fmt.Sprintf("%s %s: %s", logHeader, op, msg))
+		}
+	}
 	return err
 }
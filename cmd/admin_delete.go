@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
-	"net/http"
 	"strings"
 )
 
@@ -160,36 +159,28 @@ func (o *OptionsAdminDelete) Run(args []string) error {
 		logHeader := fmt.Sprintf("delete %s/%s", pkg.AdminCmdKinds[o.Param.Kind], itemName)
 		switch o.Param.Kind {
 		case "user":
-			param := map[string]interface{}{}
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/user/%s", itemName), http.MethodDelete, "", param, false)
+			msg, err := o.Client().DeleteUser(itemName)
 			if err != nil {
 				return err
 			}
-			msg := result.Get("msg").String()
 			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
 		case "step":
-			param := map[string]interface{}{}
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/customStepConf/%s", itemName), http.MethodDelete, "", param, false)
+			msg, err := o.Client().DeleteCustomStepConf(itemName)
 			if err != nil {
 				return err
 			}
-			msg := result.Get("msg").String()
 			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
 		case "env":
-			param := map[string]interface{}{}
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/env/%s", itemName), http.MethodDelete, "", param, false)
+			msg, err := o.Client().DeleteEnv(itemName)
 			if err != nil {
 				return err
 			}
-			msg := result.Get("msg").String()
 			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
 		case "comtpl":
-			param := map[string]interface{}{}
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/componentTemplate/%s", itemName), http.MethodDelete, "", param, false)
+			msg, err := o.Client().DeleteComponentTemplate(itemName)
 			if err != nil {
 				return err
 			}
-			msg := result.Get("msg").String()
 			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
 		}
 
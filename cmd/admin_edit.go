@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type OptionsAdminEdit struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Param          struct {
+		Kind     string `yaml:"kind" json:"kind" bson:"kind" validate:""`
+		ItemName string `yaml:"itemName" json:"itemName" bson:"itemName" validate:""`
+	}
+}
+
+func NewOptionsAdminEdit() *OptionsAdminEdit {
+	var o OptionsAdminEdit
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdAdminEdit() *cobra.Command {
+	o := NewOptionsAdminEdit()
+
+	adminCmdKinds := []string{}
+	for k, v := range pkg.AdminCmdKinds {
+		if v != "" {
+			adminCmdKinds = append(adminCmdKinds, k)
+		}
+	}
+
+	msgUse := fmt.Sprintf(`edit [kind] [itemName]
+# kind options: %s`, strings.Join(adminCmdKinds, " / "))
+	msgShort := fmt.Sprintf("edit configuration in editor, admin permission required")
+	msgLong := fmt.Sprintf(`edit configuration in dory-core server with the editor defined by $EDITOR environment variable, default editor is %s, admin permission required
+# the configuration is opened in YAML format, after the editor exits, the configuration is checked the same as admin apply,
+# if check failed, the configuration is re-opened with the error as comments at the top, save without changes to abort the edit.
+# the configuration is applied to dory-core server only if it is changed, save an empty file to cancel the edit.`, pkg.EditorDefault)
+	msgExample := fmt.Sprintf(`  # edit user, admin permission required
+  doryctl admin edit user test-user01
+
+  # edit custom step configuration with vim, admin permission required
+  EDITOR=vim doryctl admin edit step customStepName1
+
+  # edit kubernetes environment configuration, admin permission required
+  doryctl admin edit env test
+
+  # edit component template configuration, admin permission required
+  doryctl admin edit comtpl mysql-v8`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsAdminEdit) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	adminCmdKinds := []string{}
+	for k, v := range pkg.AdminCmdKinds {
+		if v != "" {
+			adminCmdKinds = append(adminCmdKinds, k)
+		}
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return adminCmdKinds, cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) == 1 {
+			kind := args[0]
+			itemNames := []string{}
+			switch kind {
+			case "user":
+				itemNames, err = o.GetUserNames()
+			case "step":
+				itemNames, err = o.GetStepNames()
+			case "env":
+				itemNames, err = o.GetEnvNames()
+			case "comtpl":
+				itemNames, err = o.GetComponentTemplateNames()
+			default:
+				err = fmt.Errorf("kind not correct")
+			}
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return itemNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return err
+}
+
+func (o *OptionsAdminEdit) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		err = fmt.Errorf("kind required")
+		return err
+	}
+	var kind string
+	kind = args[0]
+
+	adminCmdKinds := []string{}
+	for k, v := range pkg.AdminCmdKinds {
+		if v != "" {
+			adminCmdKinds = append(adminCmdKinds, k)
+		}
+	}
+
+	var found bool
+	for _, cmdKind := range adminCmdKinds {
+		if kind == cmdKind {
+			found = true
+			break
+		}
+	}
+	if !found {
+		err = fmt.Errorf("kind %s not correct: kind options: %s", kind, strings.Join(adminCmdKinds, " / "))
+		return err
+	}
+	o.Param.Kind = kind
+
+	if len(args) < 2 {
+		err = fmt.Errorf("itemName to edit required")
+		return err
+	}
+	if len(args) > 2 {
+		err = fmt.Errorf("only one itemName can be edited")
+		return err
+	}
+	o.Param.ItemName = args[1]
+
+	return err
+}
+
+// GetAdminKind return the configuration item in dory-core server by kind and name, kind is admin command kind
+func (o *OptionsCommon) GetAdminKind(kind, itemName string) (pkg.AdminKind, error) {
+	var err error
+	var adminKind pkg.AdminKind
+	var found bool
+	switch kind {
+	case "user":
+		items := []pkg.UserDetail{}
+		err = client.ListPages(client.PageQuery{All: true}, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListUsers(page, perPage)
+			return err
+		}, func() error {
+			for _, user := range items {
+				if user.Username == itemName {
+					adminKind = UserAdminKind(user)
+					found = true
+				}
+			}
+			return nil
+		})
+	case "step":
+		items := []pkg.CustomStepConfDetail{}
+		err = client.ListPages(client.PageQuery{All: true}, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListCustomStepConfs([]string{itemName}, page, perPage)
+			return err
+		}, func() error {
+			for _, csc := range items {
+				if csc.CustomStepName == itemName {
+					adminKind = CustomStepConfAdminKind(csc)
+					found = true
+				}
+			}
+			return nil
+		})
+	case "env":
+		items := []pkg.EnvK8sDetail{}
+		err = client.ListPages(client.PageQuery{All: true}, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListEnvs([]string{itemName}, page, perPage)
+			return err
+		}, func() error {
+			for _, envK8s := range items {
+				if envK8s.EnvName == itemName {
+					adminKind = EnvK8sAdminKind(envK8s)
+					found = true
+				}
+			}
+			return nil
+		})
+	case "comtpl":
+		items := []pkg.ComponentTemplate{}
+		err = client.ListPages(client.PageQuery{All: true}, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListComponentTemplates(page, perPage)
+			return err
+		}, func() error {
+			for _, comtpl := range items {
+				if comtpl.ComponentTemplateName == itemName {
+					adminKind = ComponentTemplateAdminKind(comtpl)
+					found = true
+				}
+			}
+			return nil
+		})
+	default:
+		err = fmt.Errorf("kind %s not correct", kind)
+	}
+	if err != nil {
+		return adminKind, err
+	}
+	if !found {
+		err = fmt.Errorf("%s %s not found", pkg.AdminCmdKinds[kind], itemName)
+		return adminKind, err
+	}
+	return adminKind, err
+}
+
+// AdminKindYaml return the yaml of configuration item without empty items
+func AdminKindYaml(item interface{}) ([]byte, error) {
+	var err error
+	m := map[string]interface{}{}
+	bs, err := json.Marshal(item)
+	if err != nil {
+		return bs, err
+	}
+	err = json.Unmarshal(bs, &m)
+	if err != nil {
+		return bs, err
+	}
+	return pkg.YamlIndent(pkg.RemoveMapEmptyItems(m))
+}
+
+func (o *OptionsAdminEdit) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	adminKind, err := o.GetAdminKind(o.Param.Kind, o.Param.ItemName)
+	if err != nil {
+		return err
+	}
+	bs, err = AdminKindYaml(adminKind)
+	if err != nil {
+		return err
+	}
+
+	header := fmt.Sprintf(`# edit %s %s, comments at the top are ignored, an empty file cancels the edit.
+# metadata annotations are read only, only spec is applied.
+#
+`, adminKind.Kind, adminKind.Metadata.Name)
+
+	var editedKind pkg.AdminKind
+	edited, err := EditYaml(header, bs, func(fileName string, bs []byte) error {
+		var err error
+		items, err := GetAdminKinds(fileName, bs)
+		if err != nil {
+			return err
+		}
+		if len(items) != 1 {
+			err = fmt.Errorf("only one %s can be edited, but %d items found", adminKind.Kind, len(items))
+			return err
+		}
+		editedKind = items[0]
+		if editedKind.Kind != adminKind.Kind {
+			err = fmt.Errorf("kind %s not correct, must be %s", editedKind.Kind, adminKind.Kind)
+			return err
+		}
+		if editedKind.Metadata.Name != adminKind.Metadata.Name {
+			err = fmt.Errorf("metadata.name %s not correct, must be %s", editedKind.Metadata.Name, adminKind.Metadata.Name)
+			return err
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if edited == nil {
+		return err
+	}
+
+	bsCurrent, err := AdminKindYaml(adminKind.Spec)
+	if err != nil {
+		return err
+	}
+	bsEdited, err := AdminKindYaml(editedKind.Spec)
+	if err != nil {
+		return err
+	}
+	if string(bsCurrent) == string(bsEdited) {
+		log.Success(fmt.Sprintf("%s/%s not changed, nothing to apply", adminKind.Kind, adminKind.Metadata.Name))
+		return err
+	}
+
+	err = o.ApplyAdminKind(editedKind)
+	if err != nil {
+		return err
+	}
+	log.Success(fmt.Sprintf("edit %s/%s success", adminKind.Kind, adminKind.Metadata.Name))
+
+	return err
+}
@@ -4,21 +4,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
-	"github.com/olekukonko/tablewriter"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/dory-engine/dory-ctl/pkg/printer"
 	"github.com/spf13/cobra"
-	"net/http"
 	"os"
 	"strings"
 )
 
 type OptionsAdminGet struct {
 	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
-	Full           bool   `yaml:"full" json:"full" bson:"full" validate:""`
-	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	Full           bool            `yaml:"full" json:"full" bson:"full" validate:""`
+	Page           int             `yaml:"page" json:"page" bson:"page" validate:""`
+	Number         int             `yaml:"number" json:"number" bson:"number" validate:""`
+	All            bool            `yaml:"all" json:"all" bson:"all" validate:""`
+	Limit          int             `yaml:"limit" json:"limit" bson:"limit" validate:""`
+	PrinterOptions printer.Options `yaml:"printerOptions" json:"printerOptions" bson:"printerOptions" validate:""`
 	Param          struct {
-		Kinds     []string `yaml:"kinds" json:"kinds" bson:"kinds" validate:""`
-		ItemNames []string `yaml:"itemNames" json:"itemNames" bson:"itemNames" validate:""`
-		IsAllKind bool     `yaml:"isAllKind" json:"isAllKind" bson:"isAllKind" validate:""`
+		Kinds     []string         `yaml:"kinds" json:"kinds" bson:"kinds" validate:""`
+		ItemNames []string         `yaml:"itemNames" json:"itemNames" bson:"itemNames" validate:""`
+		IsAllKind bool             `yaml:"isAllKind" json:"isAllKind" bson:"isAllKind" validate:""`
+		Printer   *printer.Printer `yaml:"-" json:"-" bson:"-" validate:""`
 	}
 }
 
@@ -36,10 +41,12 @@ func NewCmdAdminGet() *cobra.Command {
 		adminCmdKinds = append(adminCmdKinds, k)
 	}
 
-	msgUse := fmt.Sprintf(`get [kind],[kind]... [itemName1] [itemName2]... [--output=json|yaml]
+	msgUse := fmt.Sprintf(`get [kind],[kind]... [itemName1] [itemName2]... [--output=json|yaml|wide|name|csv|jsonpath=...|go-template=...|custom-columns=...]
   # kind options: %s`, strings.Join(adminCmdKinds, " / "))
 	msgShort := fmt.Sprintf("get configurations, admin permission required")
-	msgLong := fmt.Sprintf(`get users, custom steps, kubernetes environments and component templates configurations in dory-core server, admin permission required`)
+	msgLong := fmt.Sprintf(`get users, custom steps, kubernetes environments and component templates configurations in dory-core server, admin permission required
+# each kind of configurations is listed from --page, only one page is listed without --all or --limit
+# users and component templates are filtered by itemNames after listed, all pages are listed if itemNames specified`)
 	msgExample := fmt.Sprintf(`  # get all configurations, admin permission required
   doryctl admin get all --output=yaml
 
@@ -49,11 +56,23 @@ func NewCmdAdminGet() *cobra.Command {
   # get custom steps and component templates configurations, admin permission required
   doryctl admin get step,comtpl
 
+  # get all users configurations of all pages, admin permission required
+  doryctl admin get user --all
+
+  # get the first 500 users configurations, admin permission required
+  doryctl admin get user --limit=500
+
   # get users configurations, and filter by userNames, admin permission required
   doryctl admin get user test-user1 test-user2
 
   # get kubernetes environments configurations, and filter by envNames, admin permission required
-  doryctl admin get env test uat prod`)
+  doryctl admin get env test uat prod
+
+  # get usernames and mails of users, sorted by mail, admin permission required
+  doryctl admin get user -o custom-columns=USERNAME:.metadata.name,MAIL:.spec.mail --sort-by=.spec.mail
+
+  # get names of all configurations, admin permission required
+  doryctl admin get all -o name`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -66,8 +85,12 @@ func NewCmdAdminGet() *cobra.Command {
 			CheckError(o.Run(args))
 		},
 	}
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+	AddPrinterFlags(cmd, &o.PrinterOptions)
 	cmd.Flags().BoolVar(&o.Full, "full", false, "output project configurations in full version, use with --output option")
+	cmd.Flags().IntVar(&o.Page, "page", 1, "pagination number")
+	cmd.Flags().IntVarP(&o.Number, "number", "n", 200, "show how many items of each kind each page")
+	cmd.Flags().BoolVar(&o.All, "all", false, "get configurations of all pages from --page")
+	cmd.Flags().IntVar(&o.Limit, "limit", 0, "max number of configurations of each kind to get from --page, pages are iterated until the limit reached, 0 means no limit")
 
 	CheckError(o.Complete(cmd))
 	return cmd
@@ -125,9 +148,7 @@ func (o *OptionsAdminGet) Complete(cmd *cobra.Command) error {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
-	})
+	err = RegisterPrinterFlagCompletion(cmd)
 	if err != nil {
 		return err
 	}
@@ -188,15 +209,95 @@ func (o *OptionsAdminGet) Validate(args []string) error {
 		o.Param.ItemNames = args[1:]
 	}
 
-	if o.Output != "" {
-		if o.Output != "yaml" && o.Output != "json" {
-			err = fmt.Errorf("--output must be yaml or json")
-			return err
-		}
+	if o.Page < 1 {
+		err = fmt.Errorf("--page must greater than 1")
+		return err
+	}
+
+	if o.Number < 1 {
+		err = fmt.Errorf("--number must greater than 1")
+		return err
+	}
+
+	if o.Limit < 0 {
+		err = fmt.Errorf("--limit must greater than or equal to 0")
+		return err
+	}
+
+	o.Param.Printer, err = printer.NewPrinter(o.PrinterOptions)
+	if err != nil {
+		return err
 	}
 	return err
 }
 
+// UserAdminKind convert the user detail to user configuration item
+func UserAdminKind(user pkg.UserDetail) pkg.AdminKind {
+	var adminKind pkg.AdminKind
+	adminKind.Kind = "user"
+	adminKind.Metadata.Name = user.Username
+	var userProjects []string
+	for _, up := range user.UserProjects {
+		userProjects = append(userProjects, fmt.Sprintf("%s:%s", up.ProjectName, up.AccessLevel))
+	}
+	adminKind.Metadata.Annotations = map[string]string{
+		"avatarUrl":    user.AvatarUrl,
+		"createTime":   user.CreateTime,
+		"lastLogin":    user.LastLogin,
+		"userProjects": strings.Join(userProjects, ","),
+	}
+	spec := pkg.User{
+		Username: user.Username,
+		Name:     user.Name,
+		Mail:     user.Mail,
+		Mobile:   user.Mobile,
+		IsAdmin:  user.IsAdmin,
+		IsActive: user.IsActive,
+	}
+	adminKind.Spec = spec
+	return adminKind
+}
+
+// CustomStepConfAdminKind convert the custom step detail to customStepConf configuration item
+func CustomStepConfAdminKind(csc pkg.CustomStepConfDetail) pkg.AdminKind {
+	var adminKind pkg.AdminKind
+	adminKind.Kind = "customStepConf"
+	adminKind.Metadata.Name = csc.CustomStepName
+	adminKind.Metadata.Annotations = map[string]string{
+		"projectNames": strings.Join(csc.ProjectNames, ","),
+	}
+	var spec pkg.CustomStepConf
+	bs, _ := json.Marshal(csc)
+	_ = json.Unmarshal(bs, &spec)
+	adminKind.Spec = spec
+	return adminKind
+}
+
+// EnvK8sAdminKind convert the kubernetes environment detail to envK8s configuration item
+func EnvK8sAdminKind(envK8s pkg.EnvK8sDetail) pkg.AdminKind {
+	var adminKind pkg.AdminKind
+	adminKind.Kind = "envK8s"
+	adminKind.Metadata.Name = envK8s.EnvName
+	adminKind.Metadata.Annotations = map[string]string{
+		"ingressVersion": envK8s.ResourceVersion.IngressVersion,
+		"hpaVersion":     envK8s.ResourceVersion.HpaVersion,
+	}
+	var spec pkg.EnvK8s
+	bs, _ := json.Marshal(envK8s)
+	_ = json.Unmarshal(bs, &spec)
+	adminKind.Spec = spec
+	return adminKind
+}
+
+// ComponentTemplateAdminKind convert the component template to componentTemplate configuration item
+func ComponentTemplateAdminKind(comtpl pkg.ComponentTemplate) pkg.AdminKind {
+	var adminKind pkg.AdminKind
+	adminKind.Kind = "componentTemplate"
+	adminKind.Metadata.Name = comtpl.ComponentTemplateName
+	adminKind.Spec = comtpl
+	return adminKind
+}
+
 func (o *OptionsAdminGet) Run(args []string) error {
 	var err error
 
@@ -251,23 +352,46 @@ func (o *OptionsAdminGet) Run(args []string) error {
 		Kind: "list",
 	}
 	adminKinds := []pkg.AdminKind{}
+	p := o.Param.Printer
+
+	page := client.PageQuery{
+		Page:    o.Page,
+		PerPage: o.Number,
+		All:     o.All,
+		Limit:   o.Limit,
+	}
+	// users and component templates are filtered by names after listed, all pages are listed to find the names
+	pageFilter := page
+	if len(o.Param.ItemNames) > 0 {
+		pageFilter = client.PageQuery{All: true}
+	}
+	// warn if only one page listed and the page is full, there may be more items in next pages
+	warnings := []string{}
+	checkPage := func(query client.PageQuery, kind string, count, lastCount int) {
+		if !query.All && query.Limit == 0 && lastCount == o.Number {
+			warnings = append(warnings, fmt.Sprintf("only %d %s of page %d are shown, use --all or --limit to get more %s", count, kind, o.Page, kind))
+		}
+	}
 
 	userFilters := []pkg.UserDetail{}
+	userKinds := []pkg.AdminKind{}
 	if foundKindUser {
-		param := map[string]interface{}{
-			"sortMode": "username",
-			"page":     1,
-			"perPage":  1000,
-		}
-		result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/users"), http.MethodPost, "", param, false)
-		if err != nil {
-			return err
-		}
 		users := []pkg.UserDetail{}
-		err = json.Unmarshal([]byte(result.Get("data.users").Raw), &users)
+		items := []pkg.UserDetail{}
+		var lastCount int
+		err = client.ListPages(pageFilter, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListUsers(page, perPage)
+			return err
+		}, func() error {
+			lastCount = len(items)
+			users = append(users, items...)
+			return nil
+		})
 		if err != nil {
 			return err
 		}
+		checkPage(pageFilter, "users", len(users), lastCount)
 
 		for _, user := range users {
 			var found bool
@@ -286,110 +410,92 @@ func (o *OptionsAdminGet) Run(args []string) error {
 			}
 		}
 		for _, user := range userFilters {
-			var adminKind pkg.AdminKind
-			adminKind.Kind = "user"
-			adminKind.Metadata.Name = user.Username
-			var userProjects []string
-			for _, up := range user.UserProjects {
-				userProjects = append(userProjects, fmt.Sprintf("%s:%s", up.ProjectName, up.AccessLevel))
-			}
-			adminKind.Metadata.Annotations = map[string]string{
-				"avatarUrl":    user.AvatarUrl,
-				"createTime":   user.CreateTime,
-				"lastLogin":    user.LastLogin,
-				"userProjects": strings.Join(userProjects, ","),
-			}
-			spec := pkg.User{
-				Username: user.Username,
-				Name:     user.Name,
-				Mail:     user.Mail,
-				Mobile:   user.Mobile,
-				IsAdmin:  user.IsAdmin,
-				IsActive: user.IsActive,
-			}
-			adminKind.Spec = spec
-			adminKinds = append(adminKinds, adminKind)
+			userKinds = append(userKinds, UserAdminKind(user))
 		}
+		err = p.Sort(userKinds, userFilters)
+		if err != nil {
+			return err
+		}
+		adminKinds = append(adminKinds, userKinds...)
 	}
 
 	stepFilters := []pkg.CustomStepConfDetail{}
+	stepKinds := []pkg.AdminKind{}
 	if foundKindStep {
-		param := map[string]interface{}{
-			"customStepNames": o.Param.ItemNames,
-			"page":            1,
-			"perPage":         1000,
-		}
-		result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/customStepConfs"), http.MethodPost, "", param, false)
-		if err != nil {
+		items := []pkg.CustomStepConfDetail{}
+		var lastCount int
+		err = client.ListPages(page, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListCustomStepConfs(o.Param.ItemNames, page, perPage)
 			return err
-		}
-		err = json.Unmarshal([]byte(result.Get("data.customStepConfs").Raw), &stepFilters)
+		}, func() error {
+			lastCount = len(items)
+			stepFilters = append(stepFilters, items...)
+			return nil
+		})
 		if err != nil {
 			return err
 		}
+		checkPage(page, "custom steps", len(stepFilters), lastCount)
 
 		for _, csc := range stepFilters {
-			var adminKind pkg.AdminKind
-			adminKind.Kind = "customStepConf"
-			adminKind.Metadata.Name = csc.CustomStepName
-			adminKind.Metadata.Annotations = map[string]string{
-				"projectNames": strings.Join(csc.ProjectNames, ","),
-			}
-			var spec pkg.CustomStepConf
-			bs, _ := json.Marshal(csc)
-			_ = json.Unmarshal(bs, &spec)
-			adminKind.Spec = spec
-			adminKinds = append(adminKinds, adminKind)
+			stepKinds = append(stepKinds, CustomStepConfAdminKind(csc))
+		}
+		err = p.Sort(stepKinds, stepFilters)
+		if err != nil {
+			return err
 		}
+		adminKinds = append(adminKinds, stepKinds...)
 	}
 
 	envFilters := []pkg.EnvK8sDetail{}
+	envKinds := []pkg.AdminKind{}
 	if foundKindEnv {
-		param := map[string]interface{}{
-			"envNames": o.Param.ItemNames,
-			"page":     1,
-			"perPage":  1000,
-		}
-		result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/envs"), http.MethodPost, "", param, false)
-		if err != nil {
+		items := []pkg.EnvK8sDetail{}
+		var lastCount int
+		err = client.ListPages(page, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListEnvs(o.Param.ItemNames, page, perPage)
 			return err
-		}
-		err = json.Unmarshal([]byte(result.Get("data.envK8ss").Raw), &envFilters)
+		}, func() error {
+			lastCount = len(items)
+			envFilters = append(envFilters, items...)
+			return nil
+		})
 		if err != nil {
 			return err
 		}
+		checkPage(page, "kubernetes environments", len(envFilters), lastCount)
 
 		for _, envK8s := range envFilters {
-			var adminKind pkg.AdminKind
-			adminKind.Kind = "envK8s"
-			adminKind.Metadata.Name = envK8s.EnvName
-			adminKind.Metadata.Annotations = map[string]string{
-				"ingressVersion": envK8s.ResourceVersion.IngressVersion,
-				"hpaVersion":     envK8s.ResourceVersion.HpaVersion,
-			}
-			var spec pkg.EnvK8s
-			bs, _ := json.Marshal(envK8s)
-			_ = json.Unmarshal(bs, &spec)
-			adminKind.Spec = spec
-			adminKinds = append(adminKinds, adminKind)
+			envKinds = append(envKinds, EnvK8sAdminKind(envK8s))
+		}
+		err = p.Sort(envKinds, envFilters)
+		if err != nil {
+			return err
 		}
+		adminKinds = append(adminKinds, envKinds...)
 	}
 
 	comtplFilters := []pkg.ComponentTemplate{}
+	comtplKinds := []pkg.AdminKind{}
 	if foundKindComtpl {
-		param := map[string]interface{}{
-			"page":    1,
-			"perPage": 1000,
-		}
-		result, _, err := o.QueryAPI(fmt.Sprintf("api/admin/componentTemplates"), http.MethodPost, "", param, false)
-		if err != nil {
-			return err
-		}
 		comtpls := []pkg.ComponentTemplate{}
-		err = json.Unmarshal([]byte(result.Get("data.componentTemplates").Raw), &comtpls)
+		items := []pkg.ComponentTemplate{}
+		var lastCount int
+		err = client.ListPages(pageFilter, &items, func(page, perPage int) error {
+			var err error
+			items, err = o.Client().ListComponentTemplates(page, perPage)
+			return err
+		}, func() error {
+			lastCount = len(items)
+			comtpls = append(comtpls, items...)
+			return nil
+		})
 		if err != nil {
 			return err
 		}
+		checkPage(pageFilter, "component templates", len(comtpls), lastCount)
 
 		for _, comtpl := range comtpls {
 			var found bool
@@ -408,12 +514,13 @@ func (o *OptionsAdminGet) Run(args []string) error {
 		}
 
 		for _, comtpl := range comtplFilters {
-			var adminKind pkg.AdminKind
-			adminKind.Kind = "componentTemplate"
-			adminKind.Metadata.Name = comtpl.ComponentTemplateName
-			adminKind.Spec = comtpl
-			adminKinds = append(adminKinds, adminKind)
+			comtplKinds = append(comtplKinds, ComponentTemplateAdminKind(comtpl))
 		}
+		err = p.Sort(comtplKinds, comtplFilters)
+		if err != nil {
+			return err
+		}
+		adminKinds = append(adminKinds, comtplKinds...)
 	}
 
 	adminKindList.Items = adminKinds
@@ -428,125 +535,77 @@ func (o *OptionsAdminGet) Run(args []string) error {
 		dataOutput = pkg.RemoveMapEmptyItems(m)
 	}
 
-	switch o.Output {
-	case "json":
-		bs, _ = json.MarshalIndent(dataOutput, "", "  ")
-		fmt.Println(string(bs))
-	case "yaml":
-		bs, _ = pkg.YamlIndent(dataOutput)
-		fmt.Println(string(bs))
-	default:
-		if len(userFilters) > 0 {
-			dataRows := [][]string{}
-			for _, item := range userFilters {
-				ups := []string{}
-				for _, up := range item.UserProjects {
-					ups = append(ups, fmt.Sprintf("%s:%s", up.ProjectName, up.AccessLevel))
-				}
-				dataRow := []string{fmt.Sprintf("user/%s", item.Username), item.Name, item.Mail, fmt.Sprintf("%v", item.IsAdmin), fmt.Sprintf("%v", item.IsActive), strings.Join(ups, "\n")}
-				dataRows = append(dataRows, dataRow)
-			}
-
-			dataHeader := []string{"Username", "Name", "Mail", "Admin", "Active", "Projects"}
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader(dataHeader)
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(dataRows)
-			table.Render()
-			fmt.Println("------------")
-			fmt.Println()
+	tables := []printer.Table{}
+	if len(userFilters) > 0 {
+		table := printer.Table{
+			Headers:     []string{"Username", "Name", "Mail", "Admin", "Active", "Projects"},
+			WideHeaders: []string{"Mobile", "LastLogin"},
 		}
-
-		if len(stepFilters) > 0 {
-			dataRows := [][]string{}
-			for _, item := range stepFilters {
-				dataRow := []string{fmt.Sprintf("customStepConf/%s", item.CustomStepName), item.CustomStepActionDesc, fmt.Sprintf("%v", item.IsEnvDiff), strings.Join(item.ProjectNames, ","), item.ParamInputYamlDef}
-				dataRows = append(dataRows, dataRow)
+		for i, item := range userFilters {
+			ups := []string{}
+			for _, up := range item.UserProjects {
+				ups = append(ups, fmt.Sprintf("%s:%s", up.ProjectName, up.AccessLevel))
 			}
+			name := fmt.Sprintf("user/%s", item.Username)
+			dataRow := []string{name, item.Name, item.Mail, fmt.Sprintf("%v", item.IsAdmin), fmt.Sprintf("%v", item.IsActive), strings.Join(ups, "\n")}
+			table.Rows = append(table.Rows, printer.Row{Name: name, Cells: dataRow, WideCells: []string{item.Mobile, item.LastLogin}, Object: userKinds[i]})
+		}
+		tables = append(tables, table)
+	}
 
-			dataHeader := []string{"Name", "Desc", "EnvDiff", "Projects", "Input"}
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader(dataHeader)
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(dataRows)
-			table.Render()
-			fmt.Println("------------")
-			fmt.Println()
+	if len(stepFilters) > 0 {
+		table := printer.Table{
+			Headers: []string{"Name", "Desc", "EnvDiff", "Projects", "Input"},
+		}
+		for i, item := range stepFilters {
+			name := fmt.Sprintf("customStepConf/%s", item.CustomStepName)
+			dataRow := []string{name, item.CustomStepActionDesc, fmt.Sprintf("%v", item.IsEnvDiff), strings.Join(item.ProjectNames, ","), item.ParamInputYamlDef}
+			table.Rows = append(table.Rows, printer.Row{Name: name, Cells: dataRow, Object: stepKinds[i]})
 		}
+		tables = append(tables, table)
+	}
 
-		if len(envFilters) > 0 {
-			dataRows := [][]string{}
-			for _, item := range envFilters {
-				dataRow := []string{fmt.Sprintf("envK8s/%s", item.EnvName), item.EnvDesc, fmt.Sprintf("https://%s:%d", item.Host, item.Port), item.ResourceVersion.IngressVersion, item.ResourceVersion.HpaVersion}
-				dataRows = append(dataRows, dataRow)
-			}
+	if len(envFilters) > 0 {
+		table := printer.Table{
+			Headers: []string{"Name", "Desc", "Host", "ingress", "hpa"},
+		}
+		for i, item := range envFilters {
+			name := fmt.Sprintf("envK8s/%s", item.EnvName)
+			dataRow := []string{name, item.EnvDesc, fmt.Sprintf("https://%s:%d", item.Host, item.Port), item.ResourceVersion.IngressVersion, item.ResourceVersion.HpaVersion}
+			table.Rows = append(table.Rows, printer.Row{Name: name, Cells: dataRow, Object: envKinds[i]})
+		}
+		tables = append(tables, table)
+	}
 
-			dataHeader := []string{"Name", "Desc", "Host", "ingress", "hpa"}
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader(dataHeader)
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(dataRows)
-			table.Render()
-			fmt.Println("------------")
-			fmt.Println()
+	if len(comtplFilters) > 0 {
+		table := printer.Table{
+			Headers: []string{"Name", "Desc", "Image", "Replicas"},
 		}
+		for i, item := range comtplFilters {
+			name := fmt.Sprintf("componentTemplate/%s", item.ComponentTemplateName)
+			dataRow := []string{name, item.ComponentTemplateDesc, item.DeploySpecStatic.DeployImage, fmt.Sprintf("%d", item.DeploySpecStatic.DeployReplicas)}
+			table.Rows = append(table.Rows, printer.Row{Name: name, Cells: dataRow, Object: comtplKinds[i]})
+		}
+		tables = append(tables, table)
+	}
 
-		if len(comtplFilters) > 0 {
-			dataRows := [][]string{}
-			for _, item := range comtplFilters {
-				dataRow := []string{fmt.Sprintf("componentTemplate/%s", item.ComponentTemplateName), item.ComponentTemplateDesc, item.DeploySpecStatic.DeployImage, fmt.Sprintf("%d", item.DeploySpecStatic.DeployReplicas)}
-				dataRows = append(dataRows, dataRow)
+	if p.IsTable() {
+		for _, table := range tables {
+			err = p.PrintTable(os.Stdout, table, true)
+			if err != nil {
+				return err
 			}
-
-			dataHeader := []string{"Name", "Desc", "Image", "Replicas"}
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader(dataHeader)
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(dataRows)
-			table.Render()
 			fmt.Println("------------")
 			fmt.Println()
 		}
+		for _, warning := range warnings {
+			log.Warning(warning)
+		}
+	} else {
+		err = p.Print(os.Stdout, dataOutput, tables)
+		if err != nil {
+			return err
+		}
 	}
 	return err
 }
@@ -3,76 +3,101 @@ package cmd
 import (
 	"bufio"
 	"bytes"
-	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/Xuanwo/go-locale"
 	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/dory-engine/dory-ctl/pkg/printer"
 	"github.com/fatih/color"
 	"github.com/gorilla/websocket"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/tidwall/gjson"
 	"gopkg.in/yaml.v3"
 	"io/fs"
-	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
 type OptionsCommon struct {
-	ServerURL    string `yaml:"serverURL" json:"serverURL" bson:"serverURL" validate:""`
-	Insecure     bool   `yaml:"insecure" json:"insecure" bson:"insecure" validate:""`
-	Timeout      int    `yaml:"timeout" json:"timeout" bson:"timeout" validate:""`
-	AccessToken  string `yaml:"accessToken" json:"accessToken" bson:"accessToken" validate:""`
-	Language     string `yaml:"language" json:"language" bson:"language" validate:""`
-	ConfigFile   string `yaml:"configFile" json:"configFile" bson:"configFile" validate:""`
-	Verbose      bool   `yaml:"verbose" json:"verbose" bson:"verbose" validate:""`
-	ConfigExists bool   `yaml:"configExists" json:"configExists" bson:"configExists" validate:""`
+	ServerURL            string   `yaml:"serverURL" json:"serverURL" bson:"serverURL" validate:""`
+	Insecure             bool     `yaml:"insecure" json:"insecure" bson:"insecure" validate:""`
+	CertificateAuthority string   `yaml:"certificateAuthority" json:"certificateAuthority" bson:"certificateAuthority" validate:""`
+	ClientCertificate    string   `yaml:"clientCertificate" json:"clientCertificate" bson:"clientCertificate" validate:""`
+	ClientKey            string   `yaml:"clientKey" json:"clientKey" bson:"clientKey" validate:""`
+	Timeout              int      `yaml:"timeout" json:"timeout" bson:"timeout" validate:""`
+	Retries              int      `yaml:"retries" json:"retries" bson:"retries" validate:""`
+	RetryMaxWait         int      `yaml:"retryMaxWait" json:"retryMaxWait" bson:"retryMaxWait" validate:""`
+	RetryMethods         []string `yaml:"retryMethods" json:"retryMethods" bson:"retryMethods" validate:""`
+	AccessToken          string   `yaml:"accessToken" json:"accessToken" bson:"accessToken" validate:""`
+	Language             string   `yaml:"language" json:"language" bson:"language" validate:""`
+	ConfigFile           string   `yaml:"configFile" json:"configFile" bson:"configFile" validate:""`
+	Verbose              bool     `yaml:"verbose" json:"verbose" bson:"verbose" validate:""`
+	ConfigExists         bool     `yaml:"configExists" json:"configExists" bson:"configExists" validate:""`
+	Context              string   `yaml:"context" json:"context" bson:"context" validate:""`
+	// flags is the root persistent flags, values set by flags take precedence over the context values
+	flags *pflag.FlagSet
+	// allowNewContext is true when --context can be a new context, for example login
+	allowNewContext bool
 }
 
 type Log struct {
 	Verbose bool `yaml:"verbose" json:"verbose" bson:"verbose" validate:""`
+	// Stderr print the logs to stderr, when stdout is the machine-readable output
+	Stderr bool `yaml:"stderr" json:"stderr" bson:"stderr" validate:""`
 }
 
 func (log *Log) SetVerbose(verbose bool) {
 	log.Verbose = verbose
 }
 
+func (log *Log) SetStderr(stderr bool) {
+	log.Stderr = stderr
+}
+
+func (log *Log) println(attr color.Attribute, msg string) {
+	if log.Stderr {
+		_, _ = color.New(attr).Fprintln(os.Stderr, msg)
+		return
+	}
+	defer color.Unset()
+	color.Set(attr)
+	fmt.Println(msg)
+}
+
 func (log *Log) Debug(msg string) {
 	if log.Verbose {
-		defer color.Unset()
-		color.Set(color.FgBlack)
-		fmt.Println(fmt.Sprintf("[DEBU] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
+		log.println(color.FgBlack, fmt.Sprintf("[DEBU] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
 	}
 }
 
 func (log *Log) Success(msg string) {
-	defer color.Unset()
-	color.Set(color.FgGreen)
-	fmt.Println(fmt.Sprintf("[SUCC] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
+	log.println(color.FgGreen, fmt.Sprintf("[SUCC] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
 }
 
 func (log *Log) Info(msg string) {
-	defer color.Unset()
-	color.Set(color.FgBlue)
-	fmt.Println(fmt.Sprintf("[INFO] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
+	log.println(color.FgBlue, fmt.Sprintf("[INFO] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
 }
 
 func (log *Log) Warning(msg string) {
-	defer color.Unset()
-	color.Set(color.FgMagenta)
-	fmt.Println(fmt.Sprintf("[WARN] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
+	log.println(color.FgMagenta, fmt.Sprintf("[WARN] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
 }
 
 func (log *Log) Error(msg string) {
-	defer color.Unset()
-	color.Set(color.FgRed)
-	fmt.Println(fmt.Sprintf("[ERRO] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
+	log.println(color.FgRed, fmt.Sprintf("[ERRO] [%s]: %s", time.Now().Format("01-02 15:04:05"), msg))
+}
+
+// FormatRunLog return the pipeline run log line without color
+func FormatRunLog(msg pkg.WsRunLog) string {
+	return fmt.Sprintf("[%s] [%s]: %s", msg.LogType, msg.CreateTime, msg.Content)
 }
 
 func (log *Log) RunLog(msg pkg.WsRunLog) {
@@ -82,13 +107,13 @@ func (log *Log) RunLog(msg pkg.WsRunLog) {
 	switch msg.LogType {
 	case pkg.LogTypeInfo:
 		color.Set(color.FgBlue)
-		fmt.Println(fmt.Sprintf("[%s] [%s]: %s", msg.LogType, msg.CreateTime, msg.Content))
+		fmt.Println(FormatRunLog(msg))
 	case pkg.LogTypeWarning:
 		color.Set(color.FgMagenta)
-		fmt.Println(fmt.Sprintf("[%s] [%s]: %s", msg.LogType, msg.CreateTime, msg.Content))
+		fmt.Println(FormatRunLog(msg))
 	case pkg.LogTypeError:
 		color.Set(color.FgRed)
-		fmt.Println(fmt.Sprintf("[%s] [%s]: %s", msg.LogType, msg.CreateTime, msg.Content))
+		fmt.Println(FormatRunLog(msg))
 	}
 	if log.Verbose {
 		color.Set(color.FgBlack)
@@ -117,23 +142,37 @@ func (log *Log) AdminLog(msg pkg.WsAdminLog) {
 	}
 }
 
-func CheckError(err error) {
-	if err != nil {
-		log.Error(err.Error())
-		os.Exit(1)
+// ExitError is the error with process exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %d", e.Code)
 	}
+	return e.Err.Error()
 }
 
-func PrettyJson(strJson string) (string, error) {
-	var err error
-	var strPretty string
-	var buf bytes.Buffer
-	err = json.Indent(&buf, []byte(strJson), "", "  ")
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+func CheckError(err error) {
 	if err != nil {
-		return strPretty, err
+		code := pkg.ExitCodeError
+		var errExit *ExitError
+		if errors.As(err, &errExit) {
+			code = errExit.Code
+			// exit silently, the result is already printed
+			if errExit.Err == nil {
+				os.Exit(code)
+			}
+		}
+		log.Error(err.Error())
+		os.Exit(code)
 	}
-	strPretty = buf.String()
-	return strPretty, err
 }
 
 func NewOptionsCommon() *OptionsCommon {
@@ -169,19 +208,29 @@ func NewCmdRoot() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&o.ConfigFile, "config", "c", "", fmt.Sprintf("doryctl config.yaml config file, it can set by system environment variable %s (default is $HOME/%s/%s)", pkg.EnvVarConfigFile, pkg.ConfigDirDefault, pkg.ConfigFileDefault))
 	cmd.PersistentFlags().StringVarP(&o.ServerURL, "serverURL", "s", "", "dory-core server URL, example: https://dory.example.com:8080")
 	cmd.PersistentFlags().BoolVar(&o.Insecure, "insecure", false, "if true, the server's certificate will not be checked for validity. This will make your HTTPS connections insecure")
+	cmd.PersistentFlags().StringVar(&o.CertificateAuthority, "certificate-authority", "", "path to a cert file for the certificate authority to verify dory-core server certificate")
+	cmd.PersistentFlags().StringVar(&o.ClientCertificate, "client-certificate", "", "path to a client certificate file for TLS")
+	cmd.PersistentFlags().StringVar(&o.ClientKey, "client-key", "", "path to a client key file for TLS")
 	cmd.PersistentFlags().IntVar(&o.Timeout, "timeout", pkg.TimeoutDefault, "dory-core server connection timeout seconds settings")
+	cmd.PersistentFlags().IntVar(&o.Retries, "retries", pkg.RetriesDefault, "max retry times when dory-core server connection error or response 429 / 502 / 503 / 504, 0 means no retry")
+	cmd.PersistentFlags().IntVar(&o.RetryMaxWait, "retry-max-wait", pkg.RetryMaxWaitDefault, "max wait seconds between retries, retry wait time is exponential backoff with jitter")
+	cmd.PersistentFlags().StringSliceVar(&o.RetryMethods, "retry-methods", []string{}, "http methods can be retried, POST / PUT / PATCH / DELETE are not idempotent and only retried if set (default is GET)")
 	cmd.PersistentFlags().StringVar(&o.AccessToken, "token", "", fmt.Sprintf("dory-core server access token"))
 	cmd.PersistentFlags().StringVar(&o.Language, "language", "", fmt.Sprintf("language settings (options: ZH / EN)"))
+	cmd.PersistentFlags().StringVar(&o.Context, "context", "", fmt.Sprintf("the name of the doryctl config context to use (default is currentContext in config file)"))
 	cmd.PersistentFlags().BoolVarP(&o.Verbose, "verbose", "v", false, "show logs in verbose mode")
+	o.flags = cmd.PersistentFlags()
 
 	cmd.AddCommand(NewCmdLogin())
 	cmd.AddCommand(NewCmdLogout())
+	cmd.AddCommand(NewCmdConfig())
 	cmd.AddCommand(NewCmdProject())
 	cmd.AddCommand(NewCmdPipeline())
 	cmd.AddCommand(NewCmdRun())
 	cmd.AddCommand(NewCmdDef())
 	cmd.AddCommand(NewCmdAdmin())
 	cmd.AddCommand(NewCmdInstall())
+	cmd.AddCommand(NewCmdSchema())
 	cmd.AddCommand(NewCmdVersion())
 	return cmd
 }
@@ -240,23 +289,64 @@ func (o *OptionsCommon) CheckConfigFile() error {
 		return err
 	}
 
-	if doryConfig.AccessToken == "" {
-		bs, err = pkg.YamlIndent(doryConfig)
+	if doryConfig.MigrateLegacy() || len(doryConfig.Contexts) == 0 {
+		err = o.WriteDoryConfig(doryConfig)
 		if err != nil {
 			err = fmt.Errorf("%s: %s", errInfo, err.Error())
 			return err
 		}
+	}
 
-		err = os.WriteFile(o.ConfigFile, bs, 0600)
-		if err != nil {
-			err = fmt.Errorf("%s: %s", errInfo, err.Error())
-			return err
-		}
+	return err
+}
+
+func (o *OptionsCommon) ReadDoryConfig() (pkg.DoryConfig, error) {
+	errInfo := fmt.Sprintf("read config file error")
+	var err error
+	var doryConfig pkg.DoryConfig
+
+	bs, err := os.ReadFile(o.ConfigFile)
+	if err != nil {
+		err = fmt.Errorf("%s: %s", errInfo, err.Error())
+		return doryConfig, err
+	}
+	err = yaml.Unmarshal(bs, &doryConfig)
+	if err != nil {
+		err = fmt.Errorf("%s: %s", errInfo, err.Error())
+		return doryConfig, err
 	}
+	doryConfig.MigrateLegacy()
 
+	return doryConfig, err
+}
+
+func (o *OptionsCommon) WriteDoryConfig(doryConfig pkg.DoryConfig) error {
+	var err error
+	bs, err := pkg.YamlIndent(doryConfig)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(o.ConfigFile, bs, 0600)
+	if err != nil {
+		return err
+	}
+	log.Debug(fmt.Sprintf("update %s success", o.ConfigFile))
 	return err
 }
 
+// flagChanged check the root persistent flag is set in command line
+func (o *OptionsCommon) flagChanged(name string) bool {
+	return o.flags != nil && o.flags.Changed(name)
+}
+
+// ContextName return the context name set by --context, or the currentContext in config file
+func (o *OptionsCommon) ContextName(doryConfig pkg.DoryConfig) string {
+	if o.Context != "" {
+		return o.Context
+	}
+	return doryConfig.CurrentContext
+}
+
 func (o *OptionsCommon) GetOptionsCommon() error {
 	errInfo := fmt.Sprintf("get common option error")
 	var err error
@@ -266,31 +356,39 @@ func (o *OptionsCommon) GetOptionsCommon() error {
 		return err
 	}
 
-	bs, err := os.ReadFile(o.ConfigFile)
-	if err != nil {
-		err = fmt.Errorf("%s: %s", errInfo, err.Error())
-		return err
-	}
-	var doryConfig pkg.DoryConfig
-	err = yaml.Unmarshal(bs, &doryConfig)
+	doryConfig, err := o.ReadDoryConfig()
 	if err != nil {
-		err = fmt.Errorf("%s: %s", errInfo, err.Error())
 		return err
 	}
 
-	if o.ServerURL == "" && doryConfig.ServerURL != "" {
-		o.ServerURL = doryConfig.ServerURL
+	contextName := o.ContextName(doryConfig)
+	doryContext, idx := doryConfig.GetContext(contextName)
+	if o.Context != "" && idx < 0 && !o.allowNewContext {
+		err = fmt.Errorf("%s: context %s not exists", errInfo, o.Context)
+		return err
 	}
-
-	if o.AccessToken == "" && doryConfig.AccessToken != "" {
-		bs, err = base64.StdEncoding.DecodeString(doryConfig.AccessToken)
+	if doryContext.AccessToken != "" {
+		bs, err := base64.StdEncoding.DecodeString(doryContext.AccessToken)
 		if err != nil {
 			err = fmt.Errorf("%s: %s", errInfo, err.Error())
 			return err
 		}
-		o.AccessToken = string(bs)
+		doryContext.AccessToken = string(bs)
 	}
 
+	// values set by flags take precedence, otherwise use the context values or defaults,
+	// options are loaded before flags parsed, and loaded again after flags parsed
+	if !o.flagChanged("serverURL") {
+		o.ServerURL = doryContext.ServerURL
+	}
+
+	if !o.flagChanged("token") {
+		o.AccessToken = doryContext.AccessToken
+	}
+
+	if !o.flagChanged("language") {
+		o.Language = doryContext.Language
+	}
 	if o.Language == "" {
 		lang := "EN"
 		l, err := locale.Detect()
@@ -302,12 +400,59 @@ func (o *OptionsCommon) GetOptionsCommon() error {
 		}
 		o.Language = lang
 	}
-	if o.Language == "" && doryConfig.Language != "" {
-		o.Language = doryConfig.Language
+
+	if !o.flagChanged("timeout") {
+		o.Timeout = pkg.TimeoutDefault
+		if doryContext.Timeout > 0 {
+			o.Timeout = doryContext.Timeout
+		}
+	}
+
+	if !o.flagChanged("retries") {
+		o.Retries = pkg.RetriesDefault
+		if doryContext.Retries != nil && *doryContext.Retries >= 0 {
+			o.Retries = *doryContext.Retries
+		}
+	}
+
+	if !o.flagChanged("retry-max-wait") {
+		o.RetryMaxWait = pkg.RetryMaxWaitDefault
+		if doryContext.RetryMaxWait > 0 {
+			o.RetryMaxWait = doryContext.RetryMaxWait
+		}
 	}
 
-	if o.Timeout == 0 && doryConfig.Timeout != 0 && doryConfig.Timeout != pkg.TimeoutDefault {
-		o.Timeout = doryConfig.Timeout
+	if !o.flagChanged("retry-methods") {
+		o.RetryMethods = doryContext.RetryMethods
+	}
+
+	if !o.flagChanged("insecure") {
+		o.Insecure = doryContext.Insecure
+	}
+
+	if !o.flagChanged("certificate-authority") {
+		o.CertificateAuthority = doryContext.CertificateAuthority
+	}
+
+	if !o.flagChanged("client-certificate") && !o.flagChanged("client-key") {
+		o.ClientCertificate = doryContext.ClientCertificate
+		o.ClientKey = doryContext.ClientKey
+	}
+
+	if o.Retries < 0 {
+		err = fmt.Errorf("%s: --retries must be greater than or equal to 0", errInfo)
+		return err
+	}
+	if o.RetryMaxWait <= 0 {
+		err = fmt.Errorf("%s: --retry-max-wait must be greater than 0", errInfo)
+		return err
+	}
+	for _, method := range o.RetryMethods {
+		err = ValidateRetryMethod(method)
+		if err != nil {
+			err = fmt.Errorf("%s: --retry-methods %s", errInfo, err.Error())
+			return err
+		}
 	}
 
 	if o.Verbose {
@@ -317,326 +462,671 @@ func (o *OptionsCommon) GetOptionsCommon() error {
 	return err
 }
 
+// ValidateRetryMethod check the http method can be set in retry methods
+func ValidateRetryMethod(method string) error {
+	var err error
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		err = fmt.Errorf("%s not support, must be GET / POST / PUT / PATCH / DELETE", method)
+		return err
+	}
+	return err
+}
+
+// AbsPath return the absolute path of file, file paths store in config file must not depend on working directory
+func AbsPath(s string) string {
+	if s == "" {
+		return s
+	}
+	p, err := filepath.Abs(s)
+	if err != nil {
+		return s
+	}
+	return p
+}
+
+// Client return dory-core api client with common options
+func (o *OptionsCommon) Client() *client.Client {
+	c := client.NewClient(o.ServerURL, o.AccessToken)
+	c.Language = o.Language
+	c.Timeout = o.Timeout
+	c.Retries = o.Retries
+	c.RetryMaxWait = o.RetryMaxWait
+	if len(o.RetryMethods) > 0 {
+		c.RetryMethods = []string{}
+		for _, method := range o.RetryMethods {
+			c.RetryMethods = append(c.RetryMethods, strings.ToUpper(method))
+		}
+	}
+	c.Insecure = o.Insecure
+	c.CertificateAuthority = o.CertificateAuthority
+	c.ClientCertificate = o.ClientCertificate
+	c.ClientKey = o.ClientKey
+	c.Logger = &log
+	return c
+}
+
 func (o *OptionsCommon) QueryAPI(url, method, userToken string, param map[string]interface{}, showSuccess bool) (gjson.Result, string, error) {
+	return o.Client().Query(url, method, userToken, param, showSuccess)
+}
+
+func (o *OptionsCommon) QueryWebsocket(url, runName string, batches []string) error {
+	runInputs := &RunInputs{Batches: batches}
+	return o.QueryWebsocketRunLog(url, runName, runInputs, nil, func(msg pkg.WsRunLog) error {
+		log.RunLog(msg)
+		return nil
+	})
+}
+
+// QueryWebsocketRunLog query websocket logs, handleRunLog handle each pipeline run log if runName is not empty.
+// for pipeline run logs, reconnect with backoff when the connection lost before the pipeline run finished,
+// logs already received are skipped by ID, runInputs answer the pipeline run inputs,
+// closing stop closes the websocket connection and return without handling more logs and inputs, stop can be nil
+func (o *OptionsCommon) QueryWebsocketRunLog(url, runName string, runInputs *RunInputs, stop <-chan struct{}, handleRunLog func(msg pkg.WsRunLog) error) error {
 	var err error
-	var result gjson.Result
-	var strJson string
-	var statusCode int
-	var req *http.Request
-	var resp *http.Response
-	var bs []byte
-	var xUserToken string
-	client := &http.Client{
-		Timeout: time.Second * time.Duration(o.Timeout),
-	}
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-
-	if !strings.HasPrefix(url, "api/public/") && url != "api/account/accessToken" && (o.AccessToken == "" || o.ServerURL == "") {
-		err = fmt.Errorf("please login first")
-		return result, xUserToken, err
-	}
-	if o.ServerURL == "" {
-		err = fmt.Errorf("--serverURL required")
-		return result, xUserToken, err
-	}
-	url = fmt.Sprintf("%s/%s", o.ServerURL, url)
-
-	var strReqBody string
-	if len(param) > 0 {
-		bs, err = json.Marshal(param)
+
+	c := o.Client()
+	conn, _, err := c.DialWebsocket(url)
+	if err != nil {
+		return err
+	}
+
+	stopped := func() bool {
+		select {
+		case <-stop:
+			return true
+		default:
+			return false
+		}
+	}
+	// close the current connection when stop closed, the connection is replaced after reconnected
+	var mutex sync.Mutex
+	current := conn
+	setConn := func(conn *websocket.Conn) bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if stopped() {
+			conn.Close()
+			return false
+		}
+		current = conn
+		return true
+	}
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-stop:
+			mutex.Lock()
+			current.Close()
+			mutex.Unlock()
+		case <-finished:
+		}
+	}()
+
+	// check the pipeline run status before reconnect, network errors and temporary server errors are ignored,
+	// status is unknown if the check failed
+	checkRun := func() (string, bool, error) {
+		run, err := c.GetRun(runName)
 		if err != nil {
-			return result, xUserToken, err
+			if !client.IsTemporary(err) {
+				return "", false, err
+			}
+			log.Warning(fmt.Sprintf("get pipeline run %s status error: %s", runName, err.Error()))
+			return "unknown", false, nil
+		}
+		return run.Status.Result, IsRunFinished(run.Status.Result), nil
+	}
+
+	seenIDs := map[string]bool{}
+	var attempt int
+	for {
+		var received bool
+		err = o.readWebsocket(conn, func(msgData []byte) error {
+			var err error
+			if runName == "" {
+				var msg pkg.WsAdminLog
+				err = json.Unmarshal(msgData, &msg)
+				if err != nil {
+					err = fmt.Errorf("parse msg error: %s", err.Error())
+					return err
+				}
+				log.AdminLog(msg)
+				return err
+			}
+
+			var msg pkg.WsRunLog
+			err = json.Unmarshal(msgData, &msg)
+			if err != nil {
+				err = fmt.Errorf("parse msg error: %s", err.Error())
+				return err
+			}
+			if msg.ID != "" {
+				if seenIDs[msg.ID] {
+					return err
+				}
+				seenIDs[msg.ID] = true
+			}
+			if stopped() {
+				return err
+			}
+			received = true
+			err = handleRunLog(msg)
+			if err != nil {
+				return err
+			}
+			if msg.LogType == pkg.LogStatusInput && !stopped() {
+				err = o.inputRun(runName, msg, runInputs)
+				if err != nil {
+					return err
+				}
+			}
+			return err
+		})
+		conn.Close()
+		if stopped() {
+			return nil
+		}
+		var errWs *websocketError
+		if !errors.As(err, &errWs) {
+			// message handling error
+			return err
 		}
-		strReqBody = string(bs)
-		req, err = http.NewRequest(method, url, bytes.NewReader(bs))
+		if runName == "" {
+			if errWs.Unexpected() {
+				log.Warning(fmt.Sprintf("websocket connection lost: %s", errWs.Error()))
+			}
+			return nil
+		}
+
+		status, finished, err := checkRun()
 		if err != nil {
-			return result, xUserToken, err
+			return err
 		}
-	} else {
-		req, err = http.NewRequest(method, url, nil)
+		if finished {
+			return nil
+		}
+
+		// reset backoff if new logs received in last connection
+		if received {
+			attempt = 0
+		}
+		for {
+			wait := time.Second << attempt
+			maxWait := time.Second * time.Duration(o.RetryMaxWait)
+			if maxWait > 0 && wait > maxWait {
+				wait = maxWait
+			}
+			if attempt < 5 {
+				attempt++
+			}
+			log.Warning(fmt.Sprintf("pipeline run %s status is %s, websocket connection closed: %s, reconnect in %s", runName, status, errWs.Error(), wait))
+			select {
+			case <-stop:
+				return nil
+			case <-time.After(wait):
+			}
+			conn, _, err = c.DialWebsocket(url)
+			if err == nil {
+				if !setConn(conn) {
+					return nil
+				}
+				break
+			}
+			errWs = &websocketError{err: err}
+			status, finished, err = checkRun()
+			if err != nil {
+				return err
+			}
+			if finished {
+				return nil
+			}
+		}
+	}
+}
+
+// websocketError is the error of websocket connection closed or read failed
+type websocketError struct {
+	err error
+}
+
+func (e *websocketError) Error() string {
+	return e.err.Error()
+}
+
+// Unexpected check the websocket connection is not closed normally
+func (e *websocketError) Unexpected() bool {
+	return websocket.IsUnexpectedCloseError(e.err, websocket.CloseNormalClosure, websocket.CloseGoingAway)
+}
+
+// readWebsocket read websocket text messages until connection closed, handleMsg handle each text message.
+// ping the server periodically, the connection is treated as lost if no pong or message received in time.
+// return *websocketError if connection closed, or the error of handleMsg
+func (o *OptionsCommon) readWebsocket(conn *websocket.Conn, handleMsg func(msgData []byte) error) error {
+	pingPeriod := time.Second * 5
+	pongWait := pingPeriod * 3
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	done := make(chan struct{})
+	defer close(done)
+	go func(conn *websocket.Conn) {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := conn.WriteControl(websocket.PingMessage, []byte("ping"), time.Now().Add(pingPeriod))
+				if err != nil {
+					return
+				}
+			}
+		}
+	}(conn)
+
+	for {
+		msgType, msgData, err := conn.ReadMessage()
 		if err != nil {
-			return result, xUserToken, err
+			err = &websocketError{err: err}
+			return err
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		if msgType == websocket.TextMessage {
+			err = handleMsg(msgData)
+			if err != nil {
+				return err
+			}
 		}
 	}
-	headerMap := map[string]string{}
-	req.Header.Set("Language", o.Language)
-	headerMap["Language"] = o.Language
-	req.Header.Set("Content-Type", "application/json")
-	headerMap["Content-Type"] = "application/json"
-	if userToken != "" {
-		req.Header.Set("X-User-Token", userToken)
-		headerMap["X-User-Token"] = "******"
-	} else {
-		req.Header.Set("X-Access-Token", o.AccessToken)
-		headerMap["X-Access-Token"] = "******"
+}
+
+// RunInputs answer the pipeline run inputs, Batches values are used first in order,
+// then Answers matched by phaseID or title, inputs not answered are handled by Unanswered policy
+type RunInputs struct {
+	Batches    []string             `yaml:"batches" json:"batches" bson:"batches" validate:""`
+	Answers    []pkg.RunInputAnswer `yaml:"answers" json:"answers" bson:"answers" validate:""`
+	Unanswered string               `yaml:"unanswered" json:"unanswered" bson:"unanswered" validate:""`
+}
+
+// GetRunInputs read pipeline run input answers from YAML file if fileName is not empty,
+// unanswered overrides the unanswered policy in file, default policy is prompt
+func GetRunInputs(fileName, unanswered string) (RunInputs, error) {
+	var err error
+	var answers pkg.RunInputAnswers
+	runInputs := RunInputs{}
+
+	if fileName != "" {
+		bs, err := os.ReadFile(fileName)
+		if err != nil {
+			err = fmt.Errorf("read file %s error: %s", fileName, err.Error())
+			return runInputs, err
+		}
+		err = yaml.Unmarshal(bs, &answers)
+		if err != nil {
+			err = fmt.Errorf("parse file %s error: %s", fileName, err.Error())
+			return runInputs, err
+		}
+		for i, answer := range answers.Answers {
+			if answer.PhaseID == "" && answer.Title == "" {
+				err = fmt.Errorf("parse file %s error: answers[%d] phaseID or title required", fileName, i)
+				return runInputs, err
+			}
+			if len(answer.Values) == 0 {
+				err = fmt.Errorf("parse file %s error: answers[%d] values required", fileName, i)
+				return runInputs, err
+			}
+		}
+		if answers.Unanswered != "" {
+			err = ValidateInputPolicy(answers.Unanswered)
+			if err != nil {
+				err = fmt.Errorf("parse file %s error: unanswered %s", fileName, err.Error())
+				return runInputs, err
+			}
+		}
+	}
+	if unanswered != "" {
+		err = ValidateInputPolicy(unanswered)
+		if err != nil {
+			err = fmt.Errorf("--inputs-default %s", err.Error())
+			return runInputs, err
+		}
+		answers.Unanswered = unanswered
 	}
+	if answers.Unanswered == "" {
+		answers.Unanswered = pkg.InputPolicyPrompt
+	}
+
+	runInputs.Answers = answers.Answers
+	runInputs.Unanswered = answers.Unanswered
+	return runInputs, err
+}
 
-	headers := []string{}
-	for key, val := range headerMap {
-		header := fmt.Sprintf(`-H "%s: %s"`, key, val)
-		headers = append(headers, header)
+// ValidateInputPolicy check the policy for pipeline run inputs not answered
+func ValidateInputPolicy(policy string) error {
+	var err error
+	policies := []string{pkg.InputPolicyPrompt, pkg.InputPolicyAbort, pkg.InputPolicyFirst}
+	for _, p := range policies {
+		if p == policy {
+			return err
+		}
 	}
-	msgCurlParam := strings.Join(headers, " ")
-	if strReqBody != "" {
-		msgCurlParam = fmt.Sprintf("%s -d '%s'", msgCurlParam, strReqBody)
+	err = fmt.Errorf("%s not correct, options: %s", policy, strings.Join(policies, " / "))
+	return err
+}
+
+// RunInputOptions return the options values of pipeline run input, ABORT is always available
+func RunInputOptions(runInput pkg.RunInput) []string {
+	opts := []string{}
+	for _, opt := range runInput.Options {
+		opts = append(opts, opt.Value)
 	}
-	msgCurl := fmt.Sprintf(`curl -v -X%s %s '%s'`, method, msgCurlParam, url)
-	log.Debug(msgCurl)
+	if len(opts) == 0 {
+		opts = append(opts, pkg.InputValueConfirm, pkg.InputValueAbort)
+	} else {
+		opts = append(opts, pkg.InputValueAbort)
+	}
+	return opts
+}
 
-	resp, err = client.Do(req)
-	if err != nil {
-		return result, xUserToken, err
+// ValidateRunInputValues check the input values are in options of pipeline run input
+func ValidateRunInputValues(runInput pkg.RunInput, values []string) error {
+	var err error
+	if len(values) == 0 {
+		err = fmt.Errorf("input value required")
+		return err
 	}
-	defer resp.Body.Close()
-	statusCode = resp.StatusCode
-	bs, err = ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return result, xUserToken, err
+	if !runInput.IsMultiple && len(values) > 1 {
+		err = fmt.Errorf("input %s not support multiple options", runInput.Title)
+		return err
+	}
+	opts := RunInputOptions(runInput)
+	for _, value := range values {
+		var found bool
+		for _, opt := range opts {
+			if value == opt {
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = fmt.Errorf("input %s value %s not correct, options: %s", runInput.Title, value, strings.Join(opts, ","))
+			return err
+		}
 	}
+	return err
+}
 
-	strJson = string(bs)
-	result = gjson.Parse(strJson)
+// Value return the input value of pipeline run input, return empty string if input value should read from stdin
+func (r *RunInputs) Value(runInput pkg.RunInput) (string, error) {
+	var err error
+	var inputValue string
+	if len(r.Batches) > 0 {
+		inputValue, r.Batches = r.Batches[0], r.Batches[1:]
+		log.Warning(fmt.Sprintf("# input value automatically: %s", inputValue))
+		return inputValue, err
+	}
 
-	strPrettyJson, err := PrettyJson(strJson)
-	if err != nil {
-		return result, xUserToken, err
+	for _, answer := range r.Answers {
+		if (answer.PhaseID != "" && answer.PhaseID == runInput.PhaseID) || (answer.Title != "" && answer.Title == runInput.Title) {
+			err = ValidateRunInputValues(runInput, answer.Values)
+			if err != nil {
+				err = fmt.Errorf("input answers error: %s", err.Error())
+				return inputValue, err
+			}
+			inputValue = strings.Join(answer.Values, ",")
+			log.Warning(fmt.Sprintf("# input value from answers: %s", inputValue))
+			return inputValue, err
+		}
 	}
 
-	log.Debug(fmt.Sprintf("%s %s %s in %s", method, url, resp.Status, result.Get("duration").String()))
-	log.Debug(fmt.Sprintf("Response Header:"))
-	for key, val := range resp.Header {
-		log.Debug(fmt.Sprintf("  %s: %s", key, strings.Join(val, ",")))
+	switch r.Unanswered {
+	case pkg.InputPolicyAbort:
+		inputValue = pkg.InputValueAbort
+		log.Warning(fmt.Sprintf("# input not answered, input value by policy %s: %s", r.Unanswered, inputValue))
+	case pkg.InputPolicyFirst:
+		inputValue = RunInputOptions(runInput)[0]
+		log.Warning(fmt.Sprintf("# input not answered, input value by policy %s: %s", r.Unanswered, inputValue))
 	}
-	log.Debug(fmt.Sprintf("Response Body:\n%s", strPrettyJson))
+	return inputValue, err
+}
 
-	if statusCode < http.StatusOK || statusCode >= http.StatusBadRequest {
-		err = fmt.Errorf("%s %s [%s] %s", method, url, result.Get("status").String(), result.Get("msg").String())
-		return result, xUserToken, err
+// PromptRunInputValue read the input value of pipeline run input from stdin until the value is in options
+func PromptRunInputValue(runInput pkg.RunInput) (string, error) {
+	var err error
+	var inputValue string
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		if runInput.IsMultiple {
+			log.Warning("# please input options (support multiple options, example: opt1,opt2)")
+		} else {
+			log.Warning("# please input option")
+		}
+		inputValue, err = reader.ReadString('\n')
+		inputValue = strings.Trim(inputValue, "\n")
+		inputValue = strings.Trim(inputValue, " ")
+		if err != nil && inputValue == "" {
+			err = fmt.Errorf("read input value error: %s", err.Error())
+			return inputValue, err
+		}
+		err = nil
+		if inputValue == "" {
+			continue
+		}
+		err = ValidateRunInputValues(runInput, strings.Split(inputValue, ","))
+		if err != nil {
+			log.Error(err.Error())
+			continue
+		}
+		return inputValue, err
 	}
-	xUserToken = resp.Header.Get("X-User-Token")
+}
 
-	msg := fmt.Sprintf("%s %s [%s] %s", method, url, result.Get("status").String(), result.Get("msg").String())
-	if showSuccess {
-		log.Success(msg)
-	} else {
-		log.Debug(msg)
+// ConfirmApply prompt to input yes or y to continue, return error if not confirmed
+func ConfirmApply(msg string) error {
+	var err error
+	reader := bufio.NewReader(os.Stdin)
+	log.Warning(fmt.Sprintf("# %s, please input yes to continue", msg))
+	inputValue, err := reader.ReadString('\n')
+	inputValue = strings.TrimSpace(inputValue)
+	if err != nil && inputValue == "" {
+		err = fmt.Errorf("read input value error: %s", err.Error())
+		return err
+	}
+	err = nil
+	if inputValue != "yes" && inputValue != "y" {
+		err = fmt.Errorf("cancelled, input value is %s", inputValue)
+		return err
 	}
+	return err
+}
 
-	return result, xUserToken, err
+// RunInputsRecordFile return the file of pipeline run input answers recorded by doryctl, in the same format of --inputs-file
+func (o *OptionsCommon) RunInputsRecordFile(runName string) string {
+	return filepath.Join(filepath.Dir(o.ConfigFile), pkg.RunInputsRecordDir, fmt.Sprintf("%s.yaml", runName))
 }
 
-func (o *OptionsCommon) QueryWebsocket(url, runName string, batches []string) error {
+// RecordRunInput record the input value of pipeline run input, the input answers can be replayed by run rerun
+func (o *OptionsCommon) RecordRunInput(runName string, runInput pkg.RunInput, inputValue string) error {
 	var err error
-	//http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-
-	var serverURL string
-	if strings.HasPrefix(o.ServerURL, "http://") {
-		serverURL = strings.Replace(o.ServerURL, "http://", "ws://", 1)
-	} else if strings.HasPrefix(o.ServerURL, "https://") {
-		serverURL = strings.Replace(o.ServerURL, "https://", "wss://", 1)
-	}
-	if serverURL == "" {
+	var answers pkg.RunInputAnswers
+	fileName := o.RunInputsRecordFile(runName)
+	bs, err := os.ReadFile(fileName)
+	if err == nil {
+		err = yaml.Unmarshal(bs, &answers)
+		if err != nil {
+			err = fmt.Errorf("parse file %s error: %s", fileName, err.Error())
+			return err
+		}
+	} else if !os.IsNotExist(err) {
 		return err
 	}
 
-	if o.AccessToken == "" || o.ServerURL == "" {
-		err = fmt.Errorf("please login first")
+	answer := pkg.RunInputAnswer{
+		PhaseID: runInput.PhaseID,
+		Title:   runInput.Title,
+		Values:  strings.Split(inputValue, ","),
+	}
+	var found bool
+	for i, a := range answers.Answers {
+		if a.PhaseID == answer.PhaseID {
+			answers.Answers[i] = answer
+			found = true
+			break
+		}
+	}
+	if !found {
+		answers.Answers = append(answers.Answers, answer)
+	}
+	err = WriteYamlFile(fileName, answers)
+	if err != nil {
 		return err
 	}
-	url = fmt.Sprintf("%s/%s", serverURL, url)
 
-	header := http.Header{}
-	header.Add("X-Access-Token", o.AccessToken)
-	dialer := websocket.Dialer{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	err = o.PruneRunInputsRecords(time.Hour * 24 * pkg.RunInputsRecordKeepDays)
+	if err != nil {
+		log.Warning(fmt.Sprintf("prune pipeline run input answers records error: %s", err.Error()))
+		err = nil
 	}
+	return err
+}
 
-	conn, resp, err := dialer.Dial(url, header)
+// PruneRunInputsRecords remove the pipeline run input answers records not modified in keep duration
+func (o *OptionsCommon) PruneRunInputsRecords(keep time.Duration) error {
+	var err error
+	dir := filepath.Join(filepath.Dir(o.ConfigFile), pkg.RunInputsRecordDir)
+	entries, err := os.ReadDir(dir)
 	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
 		return err
 	}
-	defer conn.Close()
-	log.Debug(fmt.Sprintf("WEBSOCKET %s %s", url, resp.Status))
-
-	go func(conn *websocket.Conn) {
-		for {
-			err := conn.WriteMessage(websocket.PingMessage, []byte("ping"))
-			if err != nil {
-				break
-			}
-			time.Sleep(time.Second * 5)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
 		}
-	}(conn)
-
-	for {
-		msgType, msgData, err := conn.ReadMessage()
+		info, err := entry.Info()
 		if err != nil {
-			break
+			continue
 		}
-		switch msgType {
-		case websocket.TextMessage:
-			if runName != "" {
-				var msg pkg.WsRunLog
-				err = json.Unmarshal(msgData, &msg)
-				if err != nil {
-					err = fmt.Errorf("parse msg error: %s", err.Error())
-					return err
-				}
-				log.RunLog(msg)
-				if msg.LogType == pkg.LogStatusInput {
-					param := map[string]interface{}{}
-					var r gjson.Result
-
-					r, _, err = o.QueryAPI(fmt.Sprintf("api/cicd/run/%s", runName), http.MethodGet, "", param, false)
-					if err != nil {
-						return err
-					}
-					run := pkg.Run{}
-					err = json.Unmarshal([]byte(r.Get("data.run").Raw), &run)
-					if err != nil {
-						return err
-					}
-					if run.RunName == "" {
-						err = fmt.Errorf("runName %s not exists", runName)
-						return err
-					}
-					if run.Status.Duration == "" {
-						r, _, err = o.QueryAPI(fmt.Sprintf("api/cicd/run/%s/input", runName), http.MethodGet, "", param, false)
-						if err != nil {
-							return err
-						}
-						var runInput pkg.RunInput
-						err = json.Unmarshal([]byte(r.Get("data").Raw), &runInput)
-						if err != nil {
-							err = fmt.Errorf("parse run input error: %s", err.Error())
-							return err
-						}
-						if runInput.PhaseID == msg.PhaseID {
-							opts := []string{}
-							for _, opt := range runInput.Options {
-								opts = append(opts, opt.Value)
-							}
-							if len(opts) == 0 {
-								opts = append(opts, pkg.InputValueConfirm, pkg.InputValueAbort)
-							} else {
-								opts = append(opts, pkg.InputValueAbort)
-							}
-							strOptions := strings.Join(opts, ",")
-							log.Warning(fmt.Sprintf("# %s, %s", runInput.Title, runInput.Desc))
-							log.Warning(fmt.Sprintf("# options: %s", strOptions))
-
-							var inputValue string
-							if len(batches) > 0 {
-								inputValue, batches = batches[0], batches[1:]
-								log.Warning(fmt.Sprintf("# input value automatically: %s", inputValue))
-							}
-
-							for {
-								if inputValue == "" {
-									if runInput.IsMultiple {
-										log.Warning("# please input options (support multiple options, example: opt1,opt2)")
-									} else {
-										log.Warning("# please input option")
-									}
-									reader := bufio.NewReader(os.Stdin)
-									inputValue, _ = reader.ReadString('\n')
-									inputValue = strings.Trim(inputValue, "\n")
-									inputValue = strings.Trim(inputValue, " ")
-								} else {
-									break
-								}
-							}
-
-							param = map[string]interface{}{
-								"phaseID":    runInput.PhaseID,
-								"inputValue": inputValue,
-							}
-							r, _, err = o.QueryAPI(fmt.Sprintf("api/cicd/run/%s/input", runName), http.MethodPost, "", param, false)
-							if err != nil {
-								return err
-							}
-						}
-					}
-				}
-			} else {
-				var msg pkg.WsAdminLog
-				err = json.Unmarshal(msgData, &msg)
-				if err != nil {
-					err = fmt.Errorf("parse msg error: %s", err.Error())
-					return err
-				}
-				log.AdminLog(msg)
-			}
-		case websocket.CloseMessage:
-			break
-		default:
-			break
+		if time.Since(info.ModTime()) < keep {
+			continue
+		}
+		fileName := filepath.Join(dir, entry.Name())
+		err = os.Remove(fileName)
+		if err != nil {
+			err = fmt.Errorf("remove file %s error: %s", fileName, err.Error())
+			return err
 		}
+		log.Debug(fmt.Sprintf("remove pipeline run input answers record %s", fileName))
 	}
-
 	return err
 }
 
-func (o *OptionsCommon) GetProjectNames() ([]string, error) {
+// inputRun input the pipeline run when received input notice, use runInputs values first, then read from stdin
+func (o *OptionsCommon) inputRun(runName string, msg pkg.WsRunLog, runInputs *RunInputs) error {
 	var err error
-	projectNames := []string{}
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectNames"), http.MethodGet, "", param, false)
+	c := o.Client()
+	run, err := c.GetRun(runName)
 	if err != nil {
-		return projectNames, err
+		return err
 	}
-	err = json.Unmarshal([]byte(result.Get("data.projectNames").Raw), &projectNames)
+	if run.Status.Duration != "" {
+		return err
+	}
+	runInput, err := c.GetRunInput(runName)
 	if err != nil {
-		return projectNames, err
+		return err
 	}
-	return projectNames, err
+	if runInput.PhaseID != msg.PhaseID {
+		return err
+	}
+	err = o.answerRunInput(runName, runInput, runInputs, true)
+	return err
 }
 
-func (o *OptionsCommon) GetProjectDef(projectName string) (pkg.ProjectOutput, error) {
+// answerRunInput answer the pipeline run input by runInputs, if the input not answered,
+// prompt the input value from stdin if prompt is true, otherwise return ExitError with ExitCodeInput
+func (o *OptionsCommon) answerRunInput(runName string, runInput pkg.RunInput, runInputs *RunInputs, prompt bool) error {
 	var err error
-	var project pkg.ProjectOutput
+	strOptions := strings.Join(RunInputOptions(runInput), ",")
+	log.Warning(fmt.Sprintf("# %s, %s", runInput.Title, runInput.Desc))
+	log.Warning(fmt.Sprintf("# options: %s", strOptions))
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s", projectName), http.MethodGet, "", param, false)
+	inputValue, err := runInputs.Value(runInput)
 	if err != nil {
-		return project, err
+		return err
 	}
-	err = json.Unmarshal([]byte(result.Get("data.project").Raw), &project)
+
+	if inputValue == "" {
+		if !prompt {
+			err = &ExitError{Code: pkg.ExitCodeInput, Err: fmt.Errorf("pipeline run %s input %s not answered, answer it by --batch, --inputs-file or --inputs-default", runName, runInput.Title)}
+			return err
+		}
+		inputValue, err = PromptRunInputValue(runInput)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = o.Client().InputRun(runName, runInput.PhaseID, inputValue)
 	if err != nil {
-		return project, err
+		return err
 	}
+	err = o.RecordRunInput(runName, runInput, inputValue)
+	if err != nil {
+		log.Warning(fmt.Sprintf("record input value error: %s", err.Error()))
+		err = nil
+	}
+	return err
+}
+
+func (o *OptionsCommon) GetContextNames() ([]string, error) {
+	var err error
+	contextNames := []string{}
 
-	return project, err
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return contextNames, err
+	}
+	for _, doryContext := range doryConfig.Contexts {
+		contextNames = append(contextNames, doryContext.Name)
+	}
+
+	return contextNames, err
+}
+
+// RedactAccessToken hide access token when show config settings
+func RedactAccessToken(accessToken string) string {
+	if accessToken == "" {
+		return accessToken
+	}
+	return "******"
+}
+
+func (o *OptionsCommon) GetProjectNames() ([]string, error) {
+	return o.Client().GetProjectNames()
+}
+
+func (o *OptionsCommon) GetProjectDef(projectName string) (pkg.ProjectOutput, error) {
+	return o.Client().GetProjectDef(projectName)
 }
 
 func (o *OptionsCommon) GetPipelineNames() ([]string, error) {
 	var err error
 	var pipelineNames []string
 
-	param := map[string]interface{}{
-		"projectNames": []string{},
-		"projectTeam":  "",
-		"page":         1,
-		"perPage":      1000,
-	}
-	result, _, err := o.QueryAPI("api/cicd/projects", http.MethodPost, "", param, false)
+	pipelines, err := o.Client().ListPipelines([]string{})
 	if err != nil {
 		return pipelineNames, err
 	}
-	rs := result.Get("data.projects").Array()
-	projects := []pkg.Project{}
-	for _, r := range rs {
-		project := pkg.Project{}
-		err = json.Unmarshal([]byte(r.Raw), &project)
-		if err != nil {
-			return pipelineNames, err
-		}
-		projects = append(projects, project)
-	}
-	for _, project := range projects {
-		for _, pipeline := range project.Pipelines {
-			pipelineNames = append(pipelineNames, pipeline.PipelineName)
-		}
+	for _, pipeline := range pipelines {
+		pipelineNames = append(pipelineNames, pipeline.PipelineName)
 	}
 
 	return pipelineNames, err
@@ -646,24 +1136,10 @@ func (o *OptionsCommon) GetRunNames() ([]string, error) {
 	var err error
 	var runNames []string
 
-	param := map[string]interface{}{
-		"page":    1,
-		"perPage": 200,
-	}
-	result, _, err := o.QueryAPI("api/cicd/runs", http.MethodPost, "", param, false)
+	runs, err := o.Client().ListRuns(client.RunQuery{Page: 1, PerPage: 200})
 	if err != nil {
 		return runNames, err
 	}
-	rs := result.Get("data.runs").Array()
-	runs := []pkg.Run{}
-	for _, r := range rs {
-		run := pkg.Run{}
-		err = json.Unmarshal([]byte(r.Raw), &run)
-		if err != nil {
-			return runNames, err
-		}
-		runs = append(runs, run)
-	}
 	for _, run := range runs {
 		runNames = append(runNames, run.RunName)
 	}
@@ -672,93 +1148,144 @@ func (o *OptionsCommon) GetRunNames() ([]string, error) {
 }
 
 func (o *OptionsCommon) GetUserNames() ([]string, error) {
-	var err error
-	var userNames []string
+	return o.Client().GetUserNames()
+}
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI("api/admin/userNames", http.MethodGet, "", param, false)
-	if err != nil {
-		return userNames, err
-	}
-	rs := result.Get("data.users").Array()
-	users := []pkg.UserDetail{}
-	for _, r := range rs {
-		user := pkg.UserDetail{}
-		err = json.Unmarshal([]byte(r.Raw), &user)
-		if err != nil {
-			return userNames, err
-		}
-		users = append(users, user)
-	}
-	for _, user := range users {
-		userNames = append(userNames, user.Username)
-	}
+func (o *OptionsCommon) GetStepNames() ([]string, error) {
+	return o.Client().GetCustomStepNames()
+}
 
-	return userNames, err
+func (o *OptionsCommon) GetEnvNames() ([]string, error) {
+	return o.Client().GetEnvNames()
 }
 
-func (o *OptionsCommon) GetStepNames() ([]string, error) {
+func (o *OptionsCommon) GetComponentTemplateNames() ([]string, error) {
 	var err error
-	var stepNames []string
+	var componentTemplateNames []string
 
-	param := map[string]interface{}{
-		"page":    1,
-		"perPage": 1,
-	}
-	result, _, err := o.QueryAPI("api/admin/customStepConfs", http.MethodPost, "", param, false)
+	componentTemplates, err := o.Client().ListAllComponentTemplates()
 	if err != nil {
-		return stepNames, err
+		return componentTemplateNames, err
 	}
-	rs := result.Get("data.customStepNames").Array()
-	for _, r := range rs {
-		stepNames = append(stepNames, r.String())
+	for _, componentTemplate := range componentTemplates {
+		componentTemplateNames = append(componentTemplateNames, componentTemplate.ComponentTemplateName)
 	}
 
-	return stepNames, err
+	return componentTemplateNames, err
 }
 
-func (o *OptionsCommon) GetEnvNames() ([]string, error) {
+// ApplyDefUpdates update project definitions and print the response messages
+func (o *OptionsCommon) ApplyDefUpdates(defUpdates []pkg.DefUpdate) error {
 	var err error
-	var envNames []string
-
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI("api/admin/envNames", http.MethodGet, "", param, false)
-	if err != nil {
-		return envNames, err
-	}
-	rs := result.Get("data.envNames").Array()
-	for _, r := range rs {
-		envNames = append(envNames, r.String())
+	c := o.Client()
+	for _, defUpdate := range defUpdates {
+		logHeader := client.DefUpdateHeader(defUpdate)
+		msg, err := c.ApplyDef(defUpdate)
+		if err != nil {
+			err = fmt.Errorf("%s: %s", logHeader, err.Error())
+			return err
+		}
+		log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
 	}
-
-	return envNames, err
+	return err
 }
 
-func (o *OptionsCommon) GetComponentTemplateNames() ([]string, error) {
+// EditYaml open the yaml content in $EDITOR, return the edited content without the header comments,
+// the edited content is checked by validate, and re-opened with the error as comments until it passes,
+// if the content is not changed after an error, the edit is aborted and the edited file is kept,
+// if the edited file is empty, the edit is cancelled and nil content is returned without error
+func EditYaml(header string, bs []byte, validate func(fileName string, bs []byte) error) ([]byte, error) {
 	var err error
-	var componentTemplateNames []string
-
-	param := map[string]interface{}{
-		"page":    1,
-		"perPage": 1000,
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = pkg.EditorDefault
 	}
-	result, _, err := o.QueryAPI("api/admin/componentTemplates", http.MethodPost, "", param, false)
+
+	f, err := os.CreateTemp("", "doryctl-edit-*.yaml")
 	if err != nil {
-		return componentTemplateNames, err
+		err = fmt.Errorf("create edit file error: %s", err.Error())
+		return nil, err
 	}
-	rs := result.Get("data.componentTemplates").Array()
-	componentTemplates := []pkg.ComponentTemplate{}
-	for _, r := range rs {
-		componentTemplate := pkg.ComponentTemplate{}
-		err = json.Unmarshal([]byte(r.Raw), &componentTemplate)
+	fileName := f.Name()
+	_ = f.Close()
+
+	var bsEdited, lastEdited []byte
+	comments := header
+	content := bs
+	for {
+		err = os.WriteFile(fileName, []byte(fmt.Sprintf("%s%s", comments, string(content))), 0600)
+		if err != nil {
+			err = fmt.Errorf("write edit file %s error: %s", fileName, err.Error())
+			return nil, err
+		}
+
+		execCmd := exec.Command("sh", "-c", fmt.Sprintf("%s %s", editor, fileName))
+		execCmd.Stdin = os.Stdin
+		execCmd.Stdout = os.Stdout
+		execCmd.Stderr = os.Stderr
+		err = execCmd.Run()
 		if err != nil {
-			return componentTemplateNames, err
+			err = fmt.Errorf("run editor %s error: %s, edited file is kept in %s", editor, err.Error(), fileName)
+			return nil, err
+		}
+
+		bsEdited, err = os.ReadFile(fileName)
+		if err != nil {
+			err = fmt.Errorf("read edit file %s error: %s", fileName, err.Error())
+			return nil, err
+		}
+		edited := StripHeaderComments(bsEdited)
+		if len(bytes.TrimSpace(edited)) == 0 {
+			_ = os.Remove(fileName)
+			log.Info("edit cancelled, no changes made")
+			return nil, err
+		}
+
+		errValidate := validate(fileName, edited)
+		if errValidate == nil {
+			_ = os.Remove(fileName)
+			return edited, err
 		}
-		componentTemplates = append(componentTemplates, componentTemplate)
+		if lastEdited != nil && bytes.Equal(edited, lastEdited) {
+			err = fmt.Errorf("edit aborted, error not fixed: %s, edited file is kept in %s", errValidate.Error(), fileName)
+			return nil, err
+		}
+		lastEdited = edited
+
+		lines := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(errValidate.Error()), "\n") {
+			lines = append(lines, fmt.Sprintf("# error: %s\n", line))
+		}
+		comments = fmt.Sprintf("%s#\n%s", strings.Join(lines, ""), header)
+		content = edited
 	}
-	for _, componentTemplate := range componentTemplates {
-		componentTemplateNames = append(componentTemplateNames, componentTemplate.ComponentTemplateName)
+}
+
+// StripHeaderComments remove the comments and empty lines at the top of yaml content,
+// comments in the content are kept, they may be part of multi-line strings
+func StripHeaderComments(bs []byte) []byte {
+	lines := strings.SplitAfter(string(bs), "\n")
+	var i int
+	for i < len(lines) {
+		s := strings.TrimSpace(lines[i])
+		if s != "" && !strings.HasPrefix(s, "#") {
+			break
+		}
+		i++
 	}
+	return []byte(strings.Join(lines[i:], ""))
+}
 
-	return componentTemplateNames, err
+// AddPrinterFlags add the output flags of get commands: --output, --no-headers and --sort-by
+func AddPrinterFlags(cmd *cobra.Command, options *printer.Options) {
+	cmd.Flags().StringVarP(&options.Output, "output", "o", "", fmt.Sprintf("output format, example: -o jsonpath='{.items[*].name}', -o custom-columns=NAME:.name (options: %s)", strings.Join(printer.Outputs, " / ")))
+	cmd.Flags().BoolVar(&options.NoHeaders, "no-headers", false, "not print the table headers in table, wide, csv and custom-columns output")
+	cmd.Flags().StringVar(&options.SortBy, "sort-by", "", "sort items by JSONPath expression evaluated on each item, example: .startTime")
+}
+
+// RegisterPrinterFlagCompletion register the completion of --output flag of get commands
+func RegisterPrinterFlagCompletion(cmd *cobra.Command) error {
+	return cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return printer.Outputs, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	})
 }
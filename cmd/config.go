@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func NewCmdConfig() *cobra.Command {
+	msgUse := fmt.Sprintf("config")
+	msgShort := fmt.Sprintf("manage doryctl config contexts")
+	msgLong := fmt.Sprintf(`manage doryctl config contexts, each context save one dory-core server settings, login will create or update context`)
+	msgExample := fmt.Sprintf(`  # get all contexts
+  doryctl config get-contexts
+
+  # switch to context production
+  doryctl config use-context production
+
+  # show config file settings
+  doryctl config view
+
+  # set current context timeout settings
+  doryctl config set timeout 10`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				cmd.Help()
+				os.Exit(0)
+			}
+		},
+	}
+
+	cmd.AddCommand(NewCmdConfigGetContexts())
+	cmd.AddCommand(NewCmdConfigUseContext())
+	cmd.AddCommand(NewCmdConfigRenameContext())
+	cmd.AddCommand(NewCmdConfigDeleteContext())
+	cmd.AddCommand(NewCmdConfigView())
+	cmd.AddCommand(NewCmdConfigSet())
+	return cmd
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type OptionsConfigDeleteContext struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Param          struct {
+		ContextNames []string `yaml:"contextNames" json:"contextNames" bson:"contextNames" validate:""`
+	}
+}
+
+func NewOptionsConfigDeleteContext() *OptionsConfigDeleteContext {
+	var o OptionsConfigDeleteContext
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdConfigDeleteContext() *cobra.Command {
+	o := NewOptionsConfigDeleteContext()
+
+	msgUse := fmt.Sprintf("delete-context [contextName] ...")
+	msgShort := fmt.Sprintf("delete contexts")
+	msgLong := fmt.Sprintf(`delete contexts from doryctl config file, if current context is deleted, current context will be empty`)
+	msgExample := fmt.Sprintf(`  # delete context staging
+  doryctl config delete-context staging`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsConfigDeleteContext) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		contextNames, err := o.GetContextNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return contextNames, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return err
+}
+
+func (o *OptionsConfigDeleteContext) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		err = fmt.Errorf("contextName error: at least one contextName required")
+		return err
+	}
+	for _, s := range args {
+		s = strings.Trim(s, " ")
+		if s == "" {
+			err = fmt.Errorf("contextName error: can not be empty")
+			return err
+		}
+		o.Param.ContextNames = append(o.Param.ContextNames, s)
+	}
+
+	return err
+}
+
+func (o *OptionsConfigDeleteContext) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return err
+	}
+	for _, contextName := range o.Param.ContextNames {
+		err = doryConfig.DeleteContext(contextName)
+		if err != nil {
+			return err
+		}
+	}
+	err = o.WriteDoryConfig(doryConfig)
+	if err != nil {
+		return err
+	}
+
+	for _, contextName := range o.Param.ContextNames {
+		log.Success(fmt.Sprintf("context %s deleted", contextName))
+	}
+	if doryConfig.CurrentContext == "" {
+		log.Warning("current context is empty, please use-context or login")
+	}
+
+	return err
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+type OptionsConfigGetContexts struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	Param          struct {
+		ContextNames []string `yaml:"contextNames" json:"contextNames" bson:"contextNames" validate:""`
+	}
+}
+
+func NewOptionsConfigGetContexts() *OptionsConfigGetContexts {
+	var o OptionsConfigGetContexts
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdConfigGetContexts() *cobra.Command {
+	o := NewOptionsConfigGetContexts()
+
+	msgUse := fmt.Sprintf("get-contexts [contextName] ...")
+	msgShort := fmt.Sprintf("get contexts in doryctl config file")
+	msgLong := fmt.Sprintf(`get contexts in doryctl config file, current context is marked with *`)
+	msgExample := fmt.Sprintf(`  # get all contexts
+  doryctl config get-contexts
+
+  # get contexts by names
+  doryctl config get-contexts staging production`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsConfigGetContexts) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		contextNames, err := o.GetContextNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return contextNames, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsConfigGetContexts) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range args {
+		s = strings.Trim(s, " ")
+		if s == "" {
+			err = fmt.Errorf("contextName error: can not be empty")
+			return err
+		}
+		o.Param.ContextNames = append(o.Param.ContextNames, s)
+	}
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" {
+			err = fmt.Errorf("--output must be yaml or json")
+			return err
+		}
+	}
+	return err
+}
+
+func (o *OptionsConfigGetContexts) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return err
+	}
+
+	doryContexts := []pkg.DoryContext{}
+	for _, doryContext := range doryConfig.Contexts {
+		var found bool
+		if len(o.Param.ContextNames) == 0 {
+			found = true
+		}
+		for _, name := range o.Param.ContextNames {
+			if name == doryContext.Name {
+				found = true
+				break
+			}
+		}
+		if found {
+			doryContext.AccessToken = RedactAccessToken(doryContext.AccessToken)
+			doryContexts = append(doryContexts, doryContext)
+		}
+	}
+
+	dataOutput := map[string]interface{}{
+		"currentContext": doryConfig.CurrentContext,
+		"contexts":       doryContexts,
+	}
+	switch o.Output {
+	case "json":
+		bs, _ = json.MarshalIndent(dataOutput, "", "  ")
+		fmt.Println(string(bs))
+	case "yaml":
+		bs, _ = pkg.YamlIndent(dataOutput)
+		fmt.Println(string(bs))
+	default:
+		data := [][]string{}
+		for _, doryContext := range doryContexts {
+			var current string
+			if doryContext.Name == doryConfig.CurrentContext {
+				current = "*"
+			}
+			var login string
+			if doryContext.AccessToken != "" {
+				login = "true"
+			} else {
+				login = "false"
+			}
+			timeout := fmt.Sprintf("%d", doryContext.Timeout)
+			insecure := fmt.Sprintf("%v", doryContext.Insecure)
+			data = append(data, []string{current, doryContext.Name, doryContext.ServerURL, login, doryContext.Language, timeout, insecure})
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Current", "Name", "ServerURL", "Login", "Language", "Timeout", "Insecure"})
+		table.SetAutoWrapText(false)
+		table.SetAutoFormatHeaders(true)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetCenterSeparator("")
+		table.SetColumnSeparator("")
+		table.SetRowSeparator("")
+		table.SetHeaderLine(false)
+		table.SetBorder(false)
+		table.SetTablePadding("\t")
+		table.SetNoWhiteSpace(true)
+		table.AppendBulk(data)
+		table.Render()
+	}
+
+	return err
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type OptionsConfigRenameContext struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Param          struct {
+		ContextName    string `yaml:"contextName" json:"contextName" bson:"contextName" validate:""`
+		NewContextName string `yaml:"newContextName" json:"newContextName" bson:"newContextName" validate:""`
+	}
+}
+
+func NewOptionsConfigRenameContext() *OptionsConfigRenameContext {
+	var o OptionsConfigRenameContext
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdConfigRenameContext() *cobra.Command {
+	o := NewOptionsConfigRenameContext()
+
+	msgUse := fmt.Sprintf("rename-context [contextName] [newContextName]")
+	msgShort := fmt.Sprintf("rename context")
+	msgLong := fmt.Sprintf(`rename context in doryctl config file`)
+	msgExample := fmt.Sprintf(`  # rename context dory.example.com to production
+  doryctl config rename-context dory.example.com production`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsConfigRenameContext) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			contextNames, err := o.GetContextNames()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return contextNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return err
+}
+
+func (o *OptionsConfigRenameContext) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 2 {
+		err = fmt.Errorf("contextName error: only accept contextName and newContextName")
+		return err
+	}
+	o.Param.ContextName = strings.Trim(args[0], " ")
+	o.Param.NewContextName = strings.Trim(args[1], " ")
+	if o.Param.ContextName == "" || o.Param.NewContextName == "" {
+		err = fmt.Errorf("contextName error: can not be empty")
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsConfigRenameContext) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return err
+	}
+	err = doryConfig.RenameContext(o.Param.ContextName, o.Param.NewContextName)
+	if err != nil {
+		return err
+	}
+	err = o.WriteDoryConfig(doryConfig)
+	if err != nil {
+		return err
+	}
+
+	log.Success(fmt.Sprintf("context %s renamed to %s", o.Param.ContextName, o.Param.NewContextName))
+
+	return err
+}
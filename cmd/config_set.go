@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type OptionsConfigSet struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Param          struct {
+		Key   string `yaml:"key" json:"key" bson:"key" validate:""`
+		Value string `yaml:"value" json:"value" bson:"value" validate:""`
+	}
+}
+
+func NewOptionsConfigSet() *OptionsConfigSet {
+	var o OptionsConfigSet
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+var configSetKeys = []string{"timeout", "language", "insecure", "certificate-authority", "client-certificate", "client-key", "retries", "retry-max-wait", "retry-methods"}
+
+func NewCmdConfigSet() *cobra.Command {
+	o := NewOptionsConfigSet()
+
+	keys := configSetKeys
+
+	msgUse := fmt.Sprintf("set [key] [value]")
+	msgShort := fmt.Sprintf("set context settings")
+	msgLong := fmt.Sprintf(`set current context settings in doryctl config file, context can be set by --context
+  # keys: %s`, strings.Join(keys, " / "))
+	msgExample := fmt.Sprintf(`  # set current context connection timeout seconds
+  doryctl config set timeout 10
+
+  # set context production language
+  doryctl config set language ZH --context production
+
+  # skip dory-core server certificate verify of current context
+  doryctl config set insecure true
+
+  # verify dory-core server certificate by custom certificate authority
+  doryctl config set certificate-authority /etc/dory/ca.crt
+
+  # remove client certificate of current context
+  doryctl config set client-certificate ""
+
+  # retry GET and idempotent POST requests 5 times when dory-core server is unavailable
+  doryctl config set retries 5
+  doryctl config set retry-methods GET,POST`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsConfigSet) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return configSetKeys, cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) == 1 {
+			switch args[0] {
+			case "language":
+				return []string{"ZH", "EN"}, cobra.ShellCompDirectiveNoFileComp
+			case "insecure":
+				return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+			case "certificate-authority", "client-certificate", "client-key":
+				return nil, cobra.ShellCompDirectiveDefault
+			case "retry-methods":
+				return []string{"GET", "GET,POST", "GET,POST,PUT"}, cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return err
+}
+
+func (o *OptionsConfigSet) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 2 {
+		err = fmt.Errorf("command args error: only accept key and value")
+		return err
+	}
+	o.Param.Key = strings.Trim(args[0], " ")
+	o.Param.Value = strings.Trim(args[1], " ")
+
+	switch o.Param.Key {
+	case "timeout":
+		timeout, err := strconv.Atoi(o.Param.Value)
+		if err != nil || timeout <= 0 {
+			err = fmt.Errorf("timeout error: %s must be a positive integer", o.Param.Value)
+			return err
+		}
+	case "language":
+		o.Param.Value = strings.ToUpper(o.Param.Value)
+		if o.Param.Value != "ZH" && o.Param.Value != "EN" {
+			err = fmt.Errorf("language error: %s must be ZH or EN", o.Param.Value)
+			return err
+		}
+	case "insecure":
+		_, err = strconv.ParseBool(o.Param.Value)
+		if err != nil {
+			err = fmt.Errorf("insecure error: %s must be true or false", o.Param.Value)
+			return err
+		}
+	case "retries":
+		retries, err := strconv.Atoi(o.Param.Value)
+		if err != nil || retries < 0 {
+			err = fmt.Errorf("retries error: %s must be a non-negative integer", o.Param.Value)
+			return err
+		}
+	case "retry-max-wait":
+		retryMaxWait, err := strconv.Atoi(o.Param.Value)
+		if err != nil || retryMaxWait <= 0 {
+			err = fmt.Errorf("retry-max-wait error: %s must be a positive integer", o.Param.Value)
+			return err
+		}
+	case "retry-methods":
+		o.Param.Value = strings.ToUpper(o.Param.Value)
+		for _, method := range strings.Split(o.Param.Value, ",") {
+			err = ValidateRetryMethod(method)
+			if err != nil {
+				err = fmt.Errorf("retry-methods error: %s", err.Error())
+				return err
+			}
+		}
+	case "certificate-authority", "client-certificate", "client-key":
+		if o.Param.Value != "" {
+			fi, err := os.Stat(o.Param.Value)
+			if err != nil {
+				err = fmt.Errorf("%s error: %s", o.Param.Key, err.Error())
+				return err
+			}
+			if fi.IsDir() {
+				err = fmt.Errorf("%s error: %s is a directory", o.Param.Key, o.Param.Value)
+				return err
+			}
+			o.Param.Value = AbsPath(o.Param.Value)
+		}
+	default:
+		err = fmt.Errorf("key error: %s not support, must be %s", o.Param.Key, strings.Join(configSetKeys, " / "))
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsConfigSet) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return err
+	}
+	contextName := o.ContextName(doryConfig)
+	doryContext, idx := doryConfig.GetContext(contextName)
+	if idx < 0 {
+		err = fmt.Errorf("context %s not exists, please login first", contextName)
+		return err
+	}
+
+	switch o.Param.Key {
+	case "timeout":
+		doryContext.Timeout, _ = strconv.Atoi(o.Param.Value)
+	case "language":
+		doryContext.Language = o.Param.Value
+	case "insecure":
+		doryContext.Insecure, _ = strconv.ParseBool(o.Param.Value)
+	case "retries":
+		retries, _ := strconv.Atoi(o.Param.Value)
+		doryContext.Retries = &retries
+	case "retry-max-wait":
+		doryContext.RetryMaxWait, _ = strconv.Atoi(o.Param.Value)
+	case "retry-methods":
+		doryContext.RetryMethods = strings.Split(o.Param.Value, ",")
+	case "certificate-authority":
+		doryContext.CertificateAuthority = o.Param.Value
+	case "client-certificate":
+		doryContext.ClientCertificate = o.Param.Value
+	case "client-key":
+		doryContext.ClientKey = o.Param.Value
+	}
+	doryConfig.SetContext(doryContext)
+	err = o.WriteDoryConfig(doryConfig)
+	if err != nil {
+		return err
+	}
+
+	log.Success(fmt.Sprintf("context %s set %s to %s", contextName, o.Param.Key, o.Param.Value))
+
+	return err
+}
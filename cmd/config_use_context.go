@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type OptionsConfigUseContext struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Param          struct {
+		ContextName string `yaml:"contextName" json:"contextName" bson:"contextName" validate:""`
+	}
+}
+
+func NewOptionsConfigUseContext() *OptionsConfigUseContext {
+	var o OptionsConfigUseContext
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdConfigUseContext() *cobra.Command {
+	o := NewOptionsConfigUseContext()
+
+	msgUse := fmt.Sprintf("use-context [contextName]")
+	msgShort := fmt.Sprintf("switch current context")
+	msgLong := fmt.Sprintf(`switch current context in doryctl config file, doryctl commands will use this context dory-core server settings`)
+	msgExample := fmt.Sprintf(`  # switch to context production
+  doryctl config use-context production`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsConfigUseContext) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			contextNames, err := o.GetContextNames()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return contextNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return err
+}
+
+func (o *OptionsConfigUseContext) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		err = fmt.Errorf("contextName error: only accept one contextName")
+		return err
+	}
+	s := strings.Trim(args[0], " ")
+	if s == "" {
+		err = fmt.Errorf("contextName error: can not be empty")
+		return err
+	}
+	o.Param.ContextName = s
+
+	return err
+}
+
+func (o *OptionsConfigUseContext) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return err
+	}
+	_, idx := doryConfig.GetContext(o.Param.ContextName)
+	if idx < 0 {
+		err = fmt.Errorf("context %s not exists", o.Param.ContextName)
+		return err
+	}
+	doryConfig.CurrentContext = o.Param.ContextName
+	err = o.WriteDoryConfig(doryConfig)
+	if err != nil {
+		return err
+	}
+
+	log.Success(fmt.Sprintf("switched to context %s", o.Param.ContextName))
+
+	return err
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+)
+
+type OptionsConfigView struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Raw            bool   `yaml:"raw" json:"raw" bson:"raw" validate:""`
+	Minify         bool   `yaml:"minify" json:"minify" bson:"minify" validate:""`
+	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+}
+
+func NewOptionsConfigView() *OptionsConfigView {
+	var o OptionsConfigView
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdConfigView() *cobra.Command {
+	o := NewOptionsConfigView()
+
+	msgUse := fmt.Sprintf("view")
+	msgShort := fmt.Sprintf("show doryctl config file settings")
+	msgLong := fmt.Sprintf(`show doryctl config file settings, access tokens are redacted by default`)
+	msgExample := fmt.Sprintf(`  # show doryctl config file settings
+  doryctl config view
+
+  # show current context settings only
+  doryctl config view --minify
+
+  # show doryctl config file settings with access tokens
+  doryctl config view --raw`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().BoolVar(&o.Raw, "raw", false, "show access tokens without redaction")
+	cmd.Flags().BoolVar(&o.Minify, "minify", false, "only show current context settings, it can be set by --context")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsConfigView) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsConfigView) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		err = fmt.Errorf("command args must be empty")
+		return err
+	}
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" {
+			err = fmt.Errorf("--output must be yaml or json")
+			return err
+		}
+	}
+	return err
+}
+
+func (o *OptionsConfigView) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return err
+	}
+
+	if o.Minify {
+		contextName := o.ContextName(doryConfig)
+		doryContext, idx := doryConfig.GetContext(contextName)
+		doryConfig.Contexts = []pkg.DoryContext{}
+		if idx >= 0 {
+			doryConfig.Contexts = append(doryConfig.Contexts, doryContext)
+		}
+		doryConfig.CurrentContext = contextName
+	}
+
+	if !o.Raw {
+		for i, doryContext := range doryConfig.Contexts {
+			doryConfig.Contexts[i].AccessToken = RedactAccessToken(doryContext.AccessToken)
+		}
+	}
+
+	switch o.Output {
+	case "json":
+		bs, _ = json.MarshalIndent(doryConfig, "", "  ")
+		fmt.Println(string(bs))
+	default:
+		bs, _ = pkg.YamlIndent(doryConfig)
+		fmt.Println(string(bs))
+	}
+
+	return err
+}
@@ -16,6 +16,12 @@ func NewCmdDef() *cobra.Command {
   # apply project definitions from file or directory
   doryctl def apply -f def1.yaml -f def2.json
 
+  # diff project definitions files with dory-core server
+  doryctl def diff -f defs/ -r
+
+  # sync project definitions from directory, and delete modules not in files
+  doryctl def sync -f defs/ -r --prune
+
   # clone project definitions deploy modules to another environments
   doryctl def clone test-project1 deploy --from-env=test --modules=tp1-gin-demo,tp1-node-demo --to-envs=uat,prod
 
@@ -23,7 +29,10 @@ func NewCmdDef() *cobra.Command {
   doryctl def delete test-project1 build --modules=tp1-gin-demo,tp1-node-demo
 
   # patch project build modules definitions, update tp1-gin-demo,tp1-go-demo buildChecks commands
-  doryctl def patch test-project1 build --modules=tp1-go-demo,tp1-gin-demo --patch='[{"action": "update", "path": "buildChecks", "value": ["ls -alh"]}]'`)
+  doryctl def patch test-project1 build --modules=tp1-go-demo,tp1-gin-demo --patch='[{"action": "update", "path": "buildChecks", "value": ["ls -alh"]}]'
+
+  # edit project build modules definitions in editor
+  doryctl def edit test-project1 build --modules=tp1-go-demo,tp1-gin-demo`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -41,8 +50,13 @@ func NewCmdDef() *cobra.Command {
 
 	cmd.AddCommand(NewCmdDefGet())
 	cmd.AddCommand(NewCmdDefApply())
+	cmd.AddCommand(NewCmdDefDiff())
+	cmd.AddCommand(NewCmdDefSync())
 	cmd.AddCommand(NewCmdDefDelete())
 	cmd.AddCommand(NewCmdDefClone())
 	cmd.AddCommand(NewCmdDefPatch())
+	cmd.AddCommand(NewCmdDefEdit())
+	cmd.AddCommand(NewCmdDefLint())
+	cmd.AddCommand(NewCmdDefRename())
 	return cmd
 }
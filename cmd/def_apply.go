@@ -9,7 +9,6 @@ import (
 	"gopkg.in/yaml.v3"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -469,45 +468,22 @@ func GetDefKinds(fileName string, bs []byte) ([]pkg.DefKind, error) {
 	return defKinds, err
 }
 
-func (o *OptionsDefApply) Complete(cmd *cobra.Command) error {
-	var err error
-
-	err = o.GetOptionsCommon()
-	if err != nil {
-		return err
-	}
-
-	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
-	})
-	if err != nil {
-		return err
-	}
-
-	err = cmd.MarkFlagRequired("files")
-	if err != nil {
-		return err
-	}
-
-	return err
-}
-
-func (o *OptionsDefApply) Validate(args []string) error {
+// GetDefKindsFromFiles read project definitions from files, directories or stdin (file name is -)
+// return the sorted file names and project definitions
+func GetDefKindsFromFiles(fileNames []string, recursive bool) ([]string, []pkg.DefKind, error) {
 	var err error
+	defFileNames := []string{}
+	defKinds := []pkg.DefKind{}
 
-	err = o.GetOptionsCommon()
-	if err != nil {
-		return err
-	}
-
-	if len(o.FileNames) == 0 {
+	if len(fileNames) == 0 {
 		err = fmt.Errorf("--files required")
-		return err
+		return defFileNames, defKinds, err
 	}
-	var fileNames []string
-	for _, name := range o.FileNames {
-		fileNames = append(fileNames, strings.Trim(name, " "))
+	var names []string
+	for _, name := range fileNames {
+		names = append(names, strings.Trim(name, " "))
 	}
+	fileNames = names
 	var isStdin bool
 	for _, name := range fileNames {
 		if name == "-" {
@@ -517,172 +493,267 @@ func (o *OptionsDefApply) Validate(args []string) error {
 	}
 	if isStdin && len(fileNames) > 1 {
 		err = fmt.Errorf(`"--files -" found, can not use multiple --files options`)
-		return err
+		return defFileNames, defKinds, err
 	}
 
 	if isStdin {
 		bs, err := io.ReadAll(os.Stdin)
 		if err != nil {
-			return err
+			return defFileNames, defKinds, err
 		}
 		if len(bs) == 0 {
 			err = fmt.Errorf("--files - required os.stdin\n example: echo 'xxx' | %s def apply -f -", pkg.BaseCmdName)
-			return err
+			return defFileNames, defKinds, err
 		}
 		defs, err := GetDefKinds("", bs)
 		if err != nil {
-			return err
+			return defFileNames, defKinds, err
 		}
-		o.Param.Defs = append(o.Param.Defs, defs...)
+		defKinds = append(defKinds, defs...)
 	} else {
-		for _, fileName := range fileNames {
-			fi, err := os.Stat(fileName)
-			if err != nil {
-				return err
-			}
-			if fi.IsDir() {
-				if o.Recursive {
-					err = filepath.Walk(fileName, func(path string, info os.FileInfo, err error) error {
-						if err != nil {
-							return err
-						}
-						ext := filepath.Ext(path)
-						if !info.IsDir() && (ext == ".json" || ext == ".yaml" || ext == ".yml") {
-							o.Param.FileNames = append(o.Param.FileNames, path)
-						}
-						return nil
-					})
-				} else {
-					infos, err := ioutil.ReadDir(fileName)
-					if err != nil {
-						return err
-					}
-					for _, info := range infos {
-						ext := filepath.Ext(info.Name())
-						if !info.IsDir() && (ext == ".json" || ext == ".yaml" || ext == ".yml") {
-							if strings.HasSuffix(fileName, "/") {
-								fileName = strings.TrimSuffix(fileName, "/")
-							}
-							o.Param.FileNames = append(o.Param.FileNames, fmt.Sprintf("%s/%s", fileName, info.Name()))
-						}
-					}
-				}
-			} else {
-				ext := filepath.Ext(fileName)
-				if ext != ".json" && ext != ".yaml" && ext != ".yml" {
-					err = fmt.Errorf("file %s error: file extension name not json, yaml or yml", fileName)
-					return err
-				}
-				o.Param.FileNames = append(o.Param.FileNames, fileName)
-			}
-		}
-
-		fileNames = []string{}
-		m := map[string]bool{}
-		for _, fileName := range o.Param.FileNames {
-			m[fileName] = true
-		}
-		for fileName, _ := range m {
-			fileNames = append(fileNames, fileName)
+		defFileNames, err = ListDefFileNames(fileNames, recursive)
+		if err != nil {
+			return defFileNames, defKinds, err
 		}
-		sort.Strings(fileNames)
-		o.Param.FileNames = fileNames
 
-		for _, fileName := range o.Param.FileNames {
+		for _, fileName := range defFileNames {
 			bs, err := os.ReadFile(fileName)
 			if err != nil {
 				err = fmt.Errorf("read file %s error: %s", fileName, err.Error())
-				return err
+				return defFileNames, defKinds, err
 			}
 
 			defs, err := GetDefKinds(fileName, bs)
 			if err != nil {
-				return err
+				return defFileNames, defKinds, err
 			}
-			o.Param.Defs = append(o.Param.Defs, defs...)
+			defKinds = append(defKinds, defs...)
 		}
 	}
 
-	if o.Output != "" {
-		if o.Output != "yaml" && o.Output != "json" {
-			err = fmt.Errorf("--output must be yaml or json")
-			return err
-		}
-	}
-	return err
+	return defFileNames, defKinds, err
 }
 
-func (o *OptionsDefApply) Run(args []string) error {
+// ListDefFileNames return the sorted json, yaml and yml file names in files and directories, directories are processed recursively if recursive is true
+func ListDefFileNames(fileNames []string, recursive bool) ([]string, error) {
 	var err error
-
-	bs, _ := pkg.YamlIndent(o)
-	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
-
-	mapDefProjects := map[string][]pkg.DefKind{}
-	projects := []pkg.ProjectOutput{}
-	for _, def := range o.Param.Defs {
-		mapDefProjects[def.Metadata.ProjectName] = append(mapDefProjects[def.Metadata.ProjectName], def)
-	}
-	for projectName, defs := range mapDefProjects {
-		param := map[string]interface{}{}
-		result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s", projectName), http.MethodGet, "", param, false)
+	defFileNames := []string{}
+	for _, fileName := range fileNames {
+		fi, err := os.Stat(fileName)
 		if err != nil {
-			return err
-		}
-		project := pkg.ProjectOutput{}
-		err = json.Unmarshal([]byte(result.Get("data.project").Raw), &project)
-		if err != nil {
-			return err
+			return defFileNames, err
 		}
-
-		for _, def := range defs {
-			switch def.Kind {
-			case "buildDefs":
-				for _, item := range def.Items {
-					var d pkg.BuildDef
-					bs, _ := pkg.YamlIndent(item)
-					_ = yaml.Unmarshal(bs, &d)
-					idx := -1
-					for i, buildDef := range project.ProjectDef.BuildDefs {
-						if buildDef.BuildName == d.BuildName {
-							idx = i
-							break
-						}
+		if fi.IsDir() {
+			if recursive {
+				err = filepath.Walk(fileName, func(path string, info os.FileInfo, err error) error {
+					if err != nil {
+						return err
 					}
-					if idx >= 0 {
-						project.ProjectDef.BuildDefs[idx] = d
-					} else {
-						project.ProjectDef.BuildDefs = append(project.ProjectDef.BuildDefs, d)
+					ext := filepath.Ext(path)
+					if !info.IsDir() && (ext == ".json" || ext == ".yaml" || ext == ".yml") {
+						defFileNames = append(defFileNames, path)
 					}
-					project.ProjectDef.UpdateBuildDefs = true
+					return nil
+				})
+				if err != nil {
+					return defFileNames, err
 				}
-			case "packageDefs":
-				for _, item := range def.Items {
-					var d pkg.PackageDef
-					bs, _ := pkg.YamlIndent(item)
-					_ = yaml.Unmarshal(bs, &d)
-					idx := -1
-					for i, packageDef := range project.ProjectDef.PackageDefs {
-						if packageDef.PackageName == d.PackageName {
-							idx = i
-							break
+			} else {
+				infos, err := ioutil.ReadDir(fileName)
+				if err != nil {
+					return defFileNames, err
+				}
+				for _, info := range infos {
+					ext := filepath.Ext(info.Name())
+					if !info.IsDir() && (ext == ".json" || ext == ".yaml" || ext == ".yml") {
+						if strings.HasSuffix(fileName, "/") {
+							fileName = strings.TrimSuffix(fileName, "/")
 						}
+						defFileNames = append(defFileNames, fmt.Sprintf("%s/%s", fileName, info.Name()))
 					}
-					if idx >= 0 {
-						project.ProjectDef.PackageDefs[idx] = d
-					} else {
-						project.ProjectDef.PackageDefs = append(project.ProjectDef.PackageDefs, d)
+				}
+			}
+		} else {
+			ext := filepath.Ext(fileName)
+			if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+				err = fmt.Errorf("file %s error: file extension name not json, yaml or yml", fileName)
+				return defFileNames, err
+			}
+			defFileNames = append(defFileNames, fileName)
+		}
+	}
+
+	fileNames = []string{}
+	m := map[string]bool{}
+	for _, fileName := range defFileNames {
+		m[fileName] = true
+	}
+	for fileName, _ := range m {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	defFileNames = fileNames
+
+	return defFileNames, err
+}
+
+// MergeDefKinds update or insert project definitions items into project, mark the updated definitions
+func MergeDefKinds(project pkg.ProjectOutput, defs []pkg.DefKind) (pkg.ProjectOutput, error) {
+	var err error
+	for _, def := range defs {
+		switch def.Kind {
+		case "buildDefs":
+			for _, item := range def.Items {
+				var d pkg.BuildDef
+				bs, _ := pkg.YamlIndent(item)
+				_ = yaml.Unmarshal(bs, &d)
+				idx := -1
+				for i, buildDef := range project.ProjectDef.BuildDefs {
+					if buildDef.BuildName == d.BuildName {
+						idx = i
+						break
+					}
+				}
+				if idx >= 0 {
+					project.ProjectDef.BuildDefs[idx] = d
+				} else {
+					project.ProjectDef.BuildDefs = append(project.ProjectDef.BuildDefs, d)
+				}
+				project.ProjectDef.UpdateBuildDefs = true
+			}
+		case "packageDefs":
+			for _, item := range def.Items {
+				var d pkg.PackageDef
+				bs, _ := pkg.YamlIndent(item)
+				_ = yaml.Unmarshal(bs, &d)
+				idx := -1
+				for i, packageDef := range project.ProjectDef.PackageDefs {
+					if packageDef.PackageName == d.PackageName {
+						idx = i
+						break
+					}
+				}
+				if idx >= 0 {
+					project.ProjectDef.PackageDefs[idx] = d
+				} else {
+					project.ProjectDef.PackageDefs = append(project.ProjectDef.PackageDefs, d)
+				}
+				project.ProjectDef.UpdatePackageDefs = true
+			}
+		case "deployContainerDefs":
+			var envName string
+			for k, v := range def.Metadata.Labels {
+				if k == "envName" {
+					envName = v
+					break
+				}
+			}
+			var projectAvailableEnv pkg.ProjectAvailableEnv
+			index := -1
+			for i, pae := range project.ProjectAvailableEnvs {
+				if pae.EnvName == envName {
+					projectAvailableEnv = pae
+					index = i
+					break
+				}
+			}
+			if projectAvailableEnv.EnvName == "" {
+				err = fmt.Errorf("kind is deployContainerDefs, but projectName %s metadata.Labels.envName %s not exists", def.Metadata.ProjectName, envName)
+				return project, err
+			}
+			for _, item := range def.Items {
+				var d pkg.DeployContainerDef
+				bs, _ := pkg.YamlIndent(item)
+				_ = yaml.Unmarshal(bs, &d)
+				idx := -1
+				for i, deployContainerDef := range projectAvailableEnv.DeployContainerDefs {
+					if deployContainerDef.DeployName == d.DeployName {
+						idx = i
+						break
 					}
-					project.ProjectDef.UpdatePackageDefs = true
 				}
-			case "deployContainerDefs":
-				var envName string
-				for k, v := range def.Metadata.Labels {
-					if k == "envName" {
-						envName = v
+				if idx >= 0 {
+					projectAvailableEnv.DeployContainerDefs[idx] = d
+				} else {
+					projectAvailableEnv.DeployContainerDefs = append(projectAvailableEnv.DeployContainerDefs, d)
+				}
+				projectAvailableEnv.UpdateDeployContainerDefs = true
+			}
+			project.ProjectAvailableEnvs[index] = projectAvailableEnv
+		case "pipelineDef":
+			var branchName string
+			for k, v := range def.Metadata.Labels {
+				if k == "branchName" {
+					branchName = v
+					break
+				}
+			}
+			var projectPipeline pkg.ProjectPipeline
+			index := -1
+			for i, pp := range project.ProjectPipelines {
+				if pp.BranchName == branchName {
+					projectPipeline = pp
+					index = i
+					break
+				}
+			}
+			if projectPipeline.BranchName == "" {
+				err = fmt.Errorf("kind is pipelineDef, but projectName %s metadata.Labels.branchName %s not exists", def.Metadata.ProjectName, branchName)
+				return project, err
+			}
+			for _, item := range def.Items {
+				var d pkg.PipelineDef
+				bs, _ := pkg.YamlIndent(item)
+				_ = yaml.Unmarshal(bs, &d)
+				projectPipeline.PipelineDef = d
+				projectPipeline.UpdatePipelineDef = true
+			}
+			project.ProjectPipelines[index] = projectPipeline
+		case "dockerIgnoreDefs":
+			dockerIgnoreDefs := []string{}
+			for _, item := range def.Items {
+				switch v := item.(type) {
+				case string:
+					dockerIgnoreDefs = append(dockerIgnoreDefs, v)
+				}
+			}
+			project.ProjectDef.DockerIgnoreDefs = dockerIgnoreDefs
+			project.ProjectDef.UpdateDockerIgnoreDefs = true
+		case "customOpsDefs":
+			for _, item := range def.Items {
+				var d pkg.CustomOpsDef
+				bs, _ := pkg.YamlIndent(item)
+				_ = yaml.Unmarshal(bs, &d)
+				idx := -1
+				for i, customOpsDef := range project.ProjectDef.CustomOpsDefs {
+					if customOpsDef.CustomOpsName == d.CustomOpsName {
+						idx = i
 						break
 					}
 				}
+				if idx >= 0 {
+					project.ProjectDef.CustomOpsDefs[idx] = d
+				} else {
+					project.ProjectDef.CustomOpsDefs = append(project.ProjectDef.CustomOpsDefs, d)
+				}
+				project.ProjectDef.UpdateCustomOpsDefs = true
+			}
+		case "customStepDef":
+			var stepName string
+			var envName string
+			var enableMode string
+			for k, v := range def.Metadata.Labels {
+				if k == "stepName" {
+					stepName = v
+				}
+				if k == "envName" {
+					envName = v
+				}
+				if k == "enableMode" {
+					enableMode = v
+				}
+			}
+			if envName != "" {
 				var projectAvailableEnv pkg.ProjectAvailableEnv
 				index := -1
 				for i, pae := range project.ProjectAvailableEnvs {
@@ -693,248 +764,125 @@ func (o *OptionsDefApply) Run(args []string) error {
 					}
 				}
 				if projectAvailableEnv.EnvName == "" {
-					err = fmt.Errorf("kind is deployContainerDefs, but projectName %s metadata.Labels.envName %s not exists", def.Metadata.ProjectName, envName)
-					return err
+					err = fmt.Errorf("kind is customStepDef, but projectName %s metadata.Labels.envName %s not exists", def.Metadata.ProjectName, envName)
+					return project, err
+				}
+				var found bool
+				var customStepDef pkg.CustomStepDef
+				for name, csd := range projectAvailableEnv.CustomStepDefs {
+					if name == stepName {
+						customStepDef = csd
+						found = true
+						break
+					}
+				}
+				if !found {
+					err = fmt.Errorf("kind is customStepDef, but projectName %s metadata.Labels.stepName %s not exists", def.Metadata.ProjectName, stepName)
+					return project, err
 				}
 				for _, item := range def.Items {
-					var d pkg.DeployContainerDef
+					var d pkg.CustomStepModuleDef
 					bs, _ := pkg.YamlIndent(item)
 					_ = yaml.Unmarshal(bs, &d)
 					idx := -1
-					for i, deployContainerDef := range projectAvailableEnv.DeployContainerDefs {
-						if deployContainerDef.DeployName == d.DeployName {
+					for i, moduleDef := range customStepDef.CustomStepModuleDefs {
+						if d.ModuleName == moduleDef.ModuleName {
 							idx = i
 							break
 						}
 					}
 					if idx >= 0 {
-						projectAvailableEnv.DeployContainerDefs[idx] = d
+						customStepDef.CustomStepModuleDefs[idx] = d
 					} else {
-						projectAvailableEnv.DeployContainerDefs = append(projectAvailableEnv.DeployContainerDefs, d)
+						customStepDef.CustomStepModuleDefs = append(customStepDef.CustomStepModuleDefs, d)
 					}
-					projectAvailableEnv.UpdateDeployContainerDefs = true
+					customStepDef.UpdateCustomStepModuleDefs = true
 				}
+				customStepDef.EnableMode = enableMode
+				projectAvailableEnv.CustomStepDefs[stepName] = customStepDef
 				project.ProjectAvailableEnvs[index] = projectAvailableEnv
-			case "pipelineDef":
-				var branchName string
-				for k, v := range def.Metadata.Labels {
-					if k == "branchName" {
-						branchName = v
-						break
-					}
-				}
-				var projectPipeline pkg.ProjectPipeline
-				index := -1
-				for i, pp := range project.ProjectPipelines {
-					if pp.BranchName == branchName {
-						projectPipeline = pp
-						index = i
+			} else {
+				var found bool
+				var customStepDef pkg.CustomStepDef
+				for name, csd := range project.ProjectDef.CustomStepDefs {
+					if name == stepName {
+						customStepDef = csd
+						found = true
 						break
 					}
 				}
-				if projectPipeline.BranchName == "" {
-					err = fmt.Errorf("kind is pipelineDef, but projectName %s metadata.Labels.branchName %s not exists", def.Metadata.ProjectName, branchName)
-					return err
-				}
-				for _, item := range def.Items {
-					var d pkg.PipelineDef
-					bs, _ := pkg.YamlIndent(item)
-					_ = yaml.Unmarshal(bs, &d)
-					projectPipeline.PipelineDef = d
-					projectPipeline.UpdatePipelineDef = true
-				}
-				project.ProjectPipelines[index] = projectPipeline
-			case "dockerIgnoreDefs":
-				dockerIgnoreDefs := []string{}
-				for _, item := range def.Items {
-					switch v := item.(type) {
-					case string:
-						dockerIgnoreDefs = append(dockerIgnoreDefs, v)
-					}
+				if !found {
+					err = fmt.Errorf("kind is customStepDef, but projectName %s metadata.Labels.stepName %s not exists", def.Metadata.ProjectName, stepName)
+					return project, err
 				}
-				project.ProjectDef.DockerIgnoreDefs = dockerIgnoreDefs
-				project.ProjectDef.UpdateDockerIgnoreDefs = true
-			case "customOpsDefs":
 				for _, item := range def.Items {
-					var d pkg.CustomOpsDef
+					var d pkg.CustomStepModuleDef
 					bs, _ := pkg.YamlIndent(item)
 					_ = yaml.Unmarshal(bs, &d)
 					idx := -1
-					for i, customOpsDef := range project.ProjectDef.CustomOpsDefs {
-						if customOpsDef.CustomOpsName == d.CustomOpsName {
+					for i, moduleDef := range customStepDef.CustomStepModuleDefs {
+						if d.ModuleName == moduleDef.ModuleName {
 							idx = i
 							break
 						}
 					}
 					if idx >= 0 {
-						project.ProjectDef.CustomOpsDefs[idx] = d
+						customStepDef.CustomStepModuleDefs[idx] = d
 					} else {
-						project.ProjectDef.CustomOpsDefs = append(project.ProjectDef.CustomOpsDefs, d)
-					}
-					project.ProjectDef.UpdateCustomOpsDefs = true
-				}
-			case "customStepDef":
-				var stepName string
-				var envName string
-				var enableMode string
-				for k, v := range def.Metadata.Labels {
-					if k == "stepName" {
-						stepName = v
-					}
-					if k == "envName" {
-						envName = v
-					}
-					if k == "enableMode" {
-						enableMode = v
-					}
-				}
-				if envName != "" {
-					var projectAvailableEnv pkg.ProjectAvailableEnv
-					index := -1
-					for i, pae := range project.ProjectAvailableEnvs {
-						if pae.EnvName == envName {
-							projectAvailableEnv = pae
-							index = i
-							break
-						}
-					}
-					if projectAvailableEnv.EnvName == "" {
-						err = fmt.Errorf("kind is customStepDef, but projectName %s metadata.Labels.envName %s not exists", def.Metadata.ProjectName, envName)
-						return err
-					}
-					var found bool
-					var customStepDef pkg.CustomStepDef
-					for name, csd := range projectAvailableEnv.CustomStepDefs {
-						if name == stepName {
-							customStepDef = csd
-							found = true
-							break
-						}
-					}
-					if !found {
-						err = fmt.Errorf("kind is customStepDef, but projectName %s metadata.Labels.stepName %s not exists", def.Metadata.ProjectName, stepName)
-						return err
-					}
-					for _, item := range def.Items {
-						var d pkg.CustomStepModuleDef
-						bs, _ := pkg.YamlIndent(item)
-						_ = yaml.Unmarshal(bs, &d)
-						idx := -1
-						for i, moduleDef := range customStepDef.CustomStepModuleDefs {
-							if d.ModuleName == moduleDef.ModuleName {
-								idx = i
-								break
-							}
-						}
-						if idx >= 0 {
-							customStepDef.CustomStepModuleDefs[idx] = d
-						} else {
-							customStepDef.CustomStepModuleDefs = append(customStepDef.CustomStepModuleDefs, d)
-						}
-						customStepDef.UpdateCustomStepModuleDefs = true
-					}
-					customStepDef.EnableMode = enableMode
-					projectAvailableEnv.CustomStepDefs[stepName] = customStepDef
-					project.ProjectAvailableEnvs[index] = projectAvailableEnv
-				} else {
-					var found bool
-					var customStepDef pkg.CustomStepDef
-					for name, csd := range project.ProjectDef.CustomStepDefs {
-						if name == stepName {
-							customStepDef = csd
-							found = true
-							break
-						}
-					}
-					if !found {
-						err = fmt.Errorf("kind is customStepDef, but projectName %s metadata.Labels.stepName %s not exists", def.Metadata.ProjectName, stepName)
-						return err
-					}
-					for _, item := range def.Items {
-						var d pkg.CustomStepModuleDef
-						bs, _ := pkg.YamlIndent(item)
-						_ = yaml.Unmarshal(bs, &d)
-						idx := -1
-						for i, moduleDef := range customStepDef.CustomStepModuleDefs {
-							if d.ModuleName == moduleDef.ModuleName {
-								idx = i
-								break
-							}
-						}
-						if idx >= 0 {
-							customStepDef.CustomStepModuleDefs[idx] = d
-						} else {
-							customStepDef.CustomStepModuleDefs = append(customStepDef.CustomStepModuleDefs, d)
-						}
-						customStepDef.UpdateCustomStepModuleDefs = true
+						customStepDef.CustomStepModuleDefs = append(customStepDef.CustomStepModuleDefs, d)
 					}
-					customStepDef.EnableMode = enableMode
-					project.ProjectDef.CustomStepDefs[stepName] = customStepDef
+					customStepDef.UpdateCustomStepModuleDefs = true
 				}
+				customStepDef.EnableMode = enableMode
+				project.ProjectDef.CustomStepDefs[stepName] = customStepDef
 			}
 		}
-		projects = append(projects, project)
 	}
+	return project, err
+}
 
+// GetDefUpdates return the updated definitions of project, modules are sorted by name
+func GetDefUpdates(project pkg.ProjectOutput) []pkg.DefUpdate {
 	defUpdates := []pkg.DefUpdate{}
+	if project.ProjectDef.UpdateBuildDefs {
+		sort.SliceStable(project.ProjectDef.BuildDefs, func(i, j int) bool {
+			return project.ProjectDef.BuildDefs[i].BuildName < project.ProjectDef.BuildDefs[j].BuildName
+		})
+		defUpdate := pkg.DefUpdate{
+			Kind:        "buildDefs",
+			ProjectName: project.ProjectInfo.ProjectName,
+			Def:         project.ProjectDef.BuildDefs,
+		}
+		defUpdates = append(defUpdates, defUpdate)
+	}
 
-	for _, project := range projects {
-		if project.ProjectDef.UpdateBuildDefs {
-			sort.SliceStable(project.ProjectDef.BuildDefs, func(i, j int) bool {
-				return project.ProjectDef.BuildDefs[i].BuildName < project.ProjectDef.BuildDefs[j].BuildName
-			})
-			defUpdate := pkg.DefUpdate{
-				Kind:        "buildDefs",
-				ProjectName: project.ProjectInfo.ProjectName,
-				Def:         project.ProjectDef.BuildDefs,
-			}
-			defUpdates = append(defUpdates, defUpdate)
+	if project.ProjectDef.UpdatePackageDefs {
+		sort.SliceStable(project.ProjectDef.PackageDefs, func(i, j int) bool {
+			return project.ProjectDef.PackageDefs[i].PackageName < project.ProjectDef.PackageDefs[j].PackageName
+		})
+		defUpdate := pkg.DefUpdate{
+			Kind:        "packageDefs",
+			ProjectName: project.ProjectInfo.ProjectName,
+			Def:         project.ProjectDef.PackageDefs,
 		}
+		defUpdates = append(defUpdates, defUpdate)
+	}
 
-		if project.ProjectDef.UpdatePackageDefs {
-			sort.SliceStable(project.ProjectDef.PackageDefs, func(i, j int) bool {
-				return project.ProjectDef.PackageDefs[i].PackageName < project.ProjectDef.PackageDefs[j].PackageName
+	for _, pae := range project.ProjectAvailableEnvs {
+		if pae.UpdateDeployContainerDefs {
+			sort.SliceStable(pae.DeployContainerDefs, func(i, j int) bool {
+				return pae.DeployContainerDefs[i].DeployName < pae.DeployContainerDefs[j].DeployName
 			})
 			defUpdate := pkg.DefUpdate{
-				Kind:        "packageDefs",
+				Kind:        "deployContainerDefs",
 				ProjectName: project.ProjectInfo.ProjectName,
-				Def:         project.ProjectDef.PackageDefs,
+				Def:         pae.DeployContainerDefs,
+				EnvName:     pae.EnvName,
 			}
 			defUpdates = append(defUpdates, defUpdate)
 		}
 
-		for _, pae := range project.ProjectAvailableEnvs {
-			if pae.UpdateDeployContainerDefs {
-				sort.SliceStable(pae.DeployContainerDefs, func(i, j int) bool {
-					return pae.DeployContainerDefs[i].DeployName < pae.DeployContainerDefs[j].DeployName
-				})
-				defUpdate := pkg.DefUpdate{
-					Kind:        "deployContainerDefs",
-					ProjectName: project.ProjectInfo.ProjectName,
-					Def:         pae.DeployContainerDefs,
-					EnvName:     pae.EnvName,
-				}
-				defUpdates = append(defUpdates, defUpdate)
-			}
-
-			for stepName, csd := range pae.CustomStepDefs {
-				if csd.UpdateCustomStepModuleDefs {
-					sort.SliceStable(csd.CustomStepModuleDefs, func(i, j int) bool {
-						return csd.CustomStepModuleDefs[i].ModuleName < csd.CustomStepModuleDefs[j].ModuleName
-					})
-					defUpdate := pkg.DefUpdate{
-						Kind:           "customStepDef",
-						ProjectName:    project.ProjectInfo.ProjectName,
-						Def:            csd,
-						EnvName:        pae.EnvName,
-						CustomStepName: stepName,
-					}
-					defUpdates = append(defUpdates, defUpdate)
-				}
-			}
-		}
-
-		for stepName, csd := range project.ProjectDef.CustomStepDefs {
+		for stepName, csd := range pae.CustomStepDefs {
 			if csd.UpdateCustomStepModuleDefs {
 				sort.SliceStable(csd.CustomStepModuleDefs, func(i, j int) bool {
 					return csd.CustomStepModuleDefs[i].ModuleName < csd.CustomStepModuleDefs[j].ModuleName
@@ -943,49 +891,142 @@ func (o *OptionsDefApply) Run(args []string) error {
 					Kind:           "customStepDef",
 					ProjectName:    project.ProjectInfo.ProjectName,
 					Def:            csd,
+					EnvName:        pae.EnvName,
 					CustomStepName: stepName,
 				}
 				defUpdates = append(defUpdates, defUpdate)
 			}
 		}
+	}
 
-		for _, pp := range project.ProjectPipelines {
-			if pp.UpdatePipelineDef {
-				defUpdate := pkg.DefUpdate{
-					Kind:        "pipelineDef",
-					ProjectName: project.ProjectInfo.ProjectName,
-					Def:         pp.PipelineDef,
-					BranchName:  pp.BranchName,
-				}
-				defUpdates = append(defUpdates, defUpdate)
-			}
-		}
-
-		if project.ProjectDef.UpdateCustomOpsDefs {
-			sort.SliceStable(project.ProjectDef.CustomOpsDefs, func(i, j int) bool {
-				return project.ProjectDef.CustomOpsDefs[i].CustomOpsName < project.ProjectDef.CustomOpsDefs[j].CustomOpsName
+	for stepName, csd := range project.ProjectDef.CustomStepDefs {
+		if csd.UpdateCustomStepModuleDefs {
+			sort.SliceStable(csd.CustomStepModuleDefs, func(i, j int) bool {
+				return csd.CustomStepModuleDefs[i].ModuleName < csd.CustomStepModuleDefs[j].ModuleName
 			})
 			defUpdate := pkg.DefUpdate{
-				Kind:        "customOpsDefs",
-				ProjectName: project.ProjectInfo.ProjectName,
-				Def:         project.ProjectDef.CustomOpsDefs,
+				Kind:           "customStepDef",
+				ProjectName:    project.ProjectInfo.ProjectName,
+				Def:            csd,
+				CustomStepName: stepName,
 			}
 			defUpdates = append(defUpdates, defUpdate)
 		}
+	}
 
-		if project.ProjectDef.UpdateDockerIgnoreDefs {
-			sort.SliceStable(project.ProjectDef.DockerIgnoreDefs, func(i, j int) bool {
-				return project.ProjectDef.DockerIgnoreDefs[i] < project.ProjectDef.DockerIgnoreDefs[j]
-			})
+	for _, pp := range project.ProjectPipelines {
+		if pp.UpdatePipelineDef {
 			defUpdate := pkg.DefUpdate{
-				Kind:        "dockerIgnoreDefs",
+				Kind:        "pipelineDef",
 				ProjectName: project.ProjectInfo.ProjectName,
-				Def:         project.ProjectDef.DockerIgnoreDefs,
+				Def:         pp.PipelineDef,
+				BranchName:  pp.BranchName,
 			}
 			defUpdates = append(defUpdates, defUpdate)
 		}
 	}
 
+	if project.ProjectDef.UpdateCustomOpsDefs {
+		sort.SliceStable(project.ProjectDef.CustomOpsDefs, func(i, j int) bool {
+			return project.ProjectDef.CustomOpsDefs[i].CustomOpsName < project.ProjectDef.CustomOpsDefs[j].CustomOpsName
+		})
+		defUpdate := pkg.DefUpdate{
+			Kind:        "customOpsDefs",
+			ProjectName: project.ProjectInfo.ProjectName,
+			Def:         project.ProjectDef.CustomOpsDefs,
+		}
+		defUpdates = append(defUpdates, defUpdate)
+	}
+
+	if project.ProjectDef.UpdateDockerIgnoreDefs {
+		sort.SliceStable(project.ProjectDef.DockerIgnoreDefs, func(i, j int) bool {
+			return project.ProjectDef.DockerIgnoreDefs[i] < project.ProjectDef.DockerIgnoreDefs[j]
+		})
+		defUpdate := pkg.DefUpdate{
+			Kind:        "dockerIgnoreDefs",
+			ProjectName: project.ProjectInfo.ProjectName,
+			Def:         project.ProjectDef.DockerIgnoreDefs,
+		}
+		defUpdates = append(defUpdates, defUpdate)
+	}
+
+	return defUpdates
+}
+
+func (o *OptionsDefApply) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.MarkFlagRequired("files")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsDefApply) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	o.Param.FileNames, o.Param.Defs, err = GetDefKindsFromFiles(o.FileNames, o.Recursive)
+	if err != nil {
+		return err
+	}
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" {
+			err = fmt.Errorf("--output must be yaml or json")
+			return err
+		}
+	}
+	return err
+}
+
+func (o *OptionsDefApply) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	mapDefProjects := map[string][]pkg.DefKind{}
+	projects := []pkg.ProjectOutput{}
+	for _, def := range o.Param.Defs {
+		mapDefProjects[def.Metadata.ProjectName] = append(mapDefProjects[def.Metadata.ProjectName], def)
+	}
+	for projectName, defs := range mapDefProjects {
+		project, err := o.GetProjectDef(projectName)
+		if err != nil {
+			return err
+		}
+
+		project, err = MergeDefKinds(project, defs)
+		if err != nil {
+			return err
+		}
+		projects = append(projects, project)
+	}
+
+	defUpdates := []pkg.DefUpdate{}
+	for _, project := range projects {
+		defUpdates = append(defUpdates, GetDefUpdates(project)...)
+	}
+
 	outputs := []map[string]interface{}{}
 	for _, defUpdate := range defUpdates {
 		out := map[string]interface{}{}
@@ -1011,49 +1052,9 @@ func (o *OptionsDefApply) Run(args []string) error {
 	}
 
 	if !o.Try {
-		for _, defUpdate := range defUpdates {
-			bs, _ = pkg.YamlIndent(defUpdate.Def)
-
-			param := map[string]interface{}{
-				"envName":        defUpdate.EnvName,
-				"customStepName": defUpdate.CustomStepName,
-				"branchName":     defUpdate.BranchName,
-			}
-			paramOutput := map[string]interface{}{}
-			for k, v := range param {
-				paramOutput[k] = v
-			}
-
-			urlKind := defUpdate.Kind
-			switch defUpdate.Kind {
-			case "buildDefs":
-				param["buildDefsYaml"] = string(bs)
-			case "packageDefs":
-				param["packageDefsYaml"] = string(bs)
-			case "deployContainerDefs":
-				param["deployContainerDefsYaml"] = string(bs)
-			case "customStepDef":
-				param["customStepDefYaml"] = string(bs)
-				if defUpdate.EnvName != "" {
-					urlKind = fmt.Sprintf("%s/env", urlKind)
-				}
-			case "dockerIgnoreDefs":
-				param["dockerIgnoreDefsYaml"] = string(bs)
-			case "customOpsDefs":
-				param["customOpsDefsYaml"] = string(bs)
-			case "pipelineDef":
-				param["pipelineDefYaml"] = string(bs)
-			}
-			paramOutput = pkg.RemoveMapEmptyItems(paramOutput)
-			bs, _ = json.Marshal(paramOutput)
-			logHeader := fmt.Sprintf("[%s/%s] %s", defUpdate.ProjectName, defUpdate.Kind, string(bs))
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s/%s", defUpdate.ProjectName, urlKind), http.MethodPost, "", param, false)
-			if err != nil {
-				err = fmt.Errorf("%s: %s", logHeader, err.Error())
-				return err
-			}
-			msg := result.Get("msg").String()
-			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
+		err = o.ApplyDefUpdates(defUpdates)
+		if err != nil {
+			return err
 		}
 	}
 
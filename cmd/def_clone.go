@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
-	"net/http"
 	"strings"
 )
 
@@ -14,13 +13,17 @@ type OptionsDefClone struct {
 	FromEnvName    string   `yaml:"fromEnvName" json:"fromEnvName" bson:"fromEnvName" validate:""`
 	StepName       string   `yaml:"stepName" json:"stepName" bson:"stepName" validate:""`
 	ModuleNames    []string `yaml:"moduleNames" json:"moduleNames" bson:"moduleNames" validate:""`
+	ModulePattern  string   `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+	Selector       string   `yaml:"selector" json:"selector" bson:"selector" validate:""`
 	ToEnvNames     []string `yaml:"toEnvNames" json:"toEnvNames" bson:"toEnvNames" validate:""`
 	Try            bool     `yaml:"try" json:"try" bson:"try" validate:""`
 	Full           bool     `yaml:"full" json:"full" bson:"full" validate:""`
 	Output         string   `yaml:"output" json:"output" bson:"output" validate:""`
 	Param          struct {
-		Kind        string `yaml:"kind" json:"kind" bson:"kind" validate:""`
-		ProjectName string `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		Kind          string          `yaml:"kind" json:"kind" bson:"kind" validate:""`
+		ProjectName   string          `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		ModulePattern pkg.NamePattern `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+		Selector      pkg.Selector    `yaml:"selector" json:"selector" bson:"selector" validate:""`
 	}
 }
 
@@ -38,15 +41,21 @@ func NewCmdDefClone() *cobra.Command {
 		"step",
 	}
 
-	msgUse := fmt.Sprintf(`clone [projectName] [kind] [--from-env=envName] [--step=stepName] [--modules=moduleName1,moduleName2] [--to-envs=envName1,envName2] [--output=json|yaml]
+	msgUse := fmt.Sprintf(`clone [projectName] [kind] [--from-env=envName] [--step=stepName] [--modules=moduleName1,moduleName2] [--module-pattern=pattern] [--selector=selector] [--to-envs=envName1,envName2] [--output=json|yaml]
 # kind options: %s`, strings.Join(defCmdKinds, " / "))
 	msgShort := fmt.Sprintf("clone project definitions modules to another environments")
-	msgLong := fmt.Sprintf(`clone project definitions modules to another environments in dory-core server`)
+	msgLong := fmt.Sprintf(`clone project definitions modules to another environments in dory-core server
+# --module-pattern select modules by glob pattern, or by regular expression with %s prefix
+# --selector check the definitions cloned from match the metadata labels envName, stepName and enableMode, selector format:
+#   key=value, key!=value, key in (value1,value2), key notin (value1,value2), key (exists), !key (not exists)`, pkg.NamePatternRegexPrefix)
 	msgExample := fmt.Sprintf(`  # clone project definitions deploy modules to another environments
   doryctl def clone test-project1 deploy --from-env=test --modules=tp1-gin-demo,tp1-node-demo --to-envs=uat,prod
 
   # clone project definitions step modules to another environments
-  doryctl def clone test-project1 deploy --from-env=test --step=customStepName2 --modules=tp1-gin-demo,tp1-node-demo --to-envs=uat,prod`)
+  doryctl def clone test-project1 deploy --from-env=test --step=customStepName2 --modules=tp1-gin-demo,tp1-node-demo --to-envs=uat,prod
+
+  # clone project definitions all *-api deploy modules to another environments
+  doryctl def clone test-project1 deploy --from-env=test --module-pattern='*-api' --to-envs=uat,prod`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -61,7 +70,9 @@ func NewCmdDefClone() *cobra.Command {
 	}
 	cmd.Flags().StringVar(&o.FromEnvName, "from-env", "", "which environment modules clone from")
 	cmd.Flags().StringVar(&o.StepName, "step", "", "which step modules clone from, required if kind is step")
-	cmd.Flags().StringSliceVar(&o.ModuleNames, "modules", []string{}, "which modules to clone")
+	cmd.Flags().StringSliceVar(&o.ModuleNames, "modules", []string{}, "which modules to clone, --modules or --module-pattern required")
+	cmd.Flags().StringVar(&o.ModulePattern, "module-pattern", "", "which modules to clone by glob pattern, or regular expression with regex: prefix, example: *-api")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "metadata labels selector the definitions cloned from must match, example: enableMode!=disable")
 	cmd.Flags().StringSliceVar(&o.ToEnvNames, "to-envs", []string{}, "which environments modules clone to")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
 	cmd.Flags().BoolVar(&o.Full, "full", false, "output project definitions in full version, use with --output option")
@@ -243,11 +254,6 @@ func (o *OptionsDefClone) Complete(cmd *cobra.Command) error {
 		return err
 	}
 
-	err = cmd.MarkFlagRequired("modules")
-	if err != nil {
-		return err
-	}
-
 	return err
 }
 
@@ -297,8 +303,8 @@ func (o *OptionsDefClone) Validate(args []string) error {
 	}
 	o.Param.Kind = kind
 
-	if len(o.ModuleNames) == 0 {
-		err = fmt.Errorf("--modules required")
+	if len(o.ModuleNames) == 0 && o.ModulePattern == "" {
+		err = fmt.Errorf("--modules or --module-pattern required")
 		return err
 	}
 	for _, moduleName := range o.ModuleNames {
@@ -308,6 +314,16 @@ func (o *OptionsDefClone) Validate(args []string) error {
 			return err
 		}
 	}
+	o.Param.ModulePattern, err = pkg.ParseNamePattern(o.ModulePattern)
+	if err != nil {
+		err = fmt.Errorf("--module-pattern %s format error: %s", o.ModulePattern, err.Error())
+		return err
+	}
+	o.Param.Selector, err = pkg.ParseSelector(o.Selector)
+	if err != nil {
+		err = fmt.Errorf("--selector %s format error: %s", o.Selector, err.Error())
+		return err
+	}
 
 	if o.FromEnvName == "" {
 		err = fmt.Errorf("--from-env required")
@@ -336,13 +352,7 @@ func (o *OptionsDefClone) Validate(args []string) error {
 func (o *OptionsDefClone) Run(args []string) error {
 	var err error
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s", o.Param.ProjectName), http.MethodGet, "", param, false)
-	if err != nil {
-		return err
-	}
-	project := pkg.ProjectOutput{}
-	err = json.Unmarshal([]byte(result.Get("data.project").Raw), &project)
+	project, err := o.GetProjectDef(o.Param.ProjectName)
 	if err != nil {
 		return err
 	}
@@ -375,15 +385,14 @@ func (o *OptionsDefClone) Run(args []string) error {
 			err = fmt.Errorf("from envName %s not exists", o.FromEnvName)
 			return err
 		}
+		metadata := pkg.DefMetadata{Labels: map[string]string{"envName": pae.EnvName}}
+		if !o.Param.Selector.Match(metadata) {
+			err = fmt.Errorf("from envName %s not match selector %s", o.FromEnvName, o.Selector)
+			return err
+		}
 		defs := []pkg.DeployContainerDef{}
 		for _, def := range pae.DeployContainerDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.DeployName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.DeployName, o.ModuleNames, o.Param.ModulePattern)
 			if found {
 				defs = append(defs, def)
 			}
@@ -411,15 +420,14 @@ func (o *OptionsDefClone) Run(args []string) error {
 				break
 			}
 		}
+		metadata := pkg.DefMetadata{Labels: map[string]string{"envName": pae.EnvName, "stepName": o.StepName, "enableMode": csd.EnableMode}}
+		if !o.Param.Selector.Match(metadata) {
+			err = fmt.Errorf("from envName %s step %s not match selector %s", o.FromEnvName, o.StepName, o.Selector)
+			return err
+		}
 		defs := []pkg.CustomStepModuleDef{}
 		for _, def := range csd.CustomStepModuleDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.ModuleName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.ModuleName, o.ModuleNames, o.Param.ModulePattern)
 			if found {
 				defs = append(defs, def)
 			}
@@ -450,24 +458,13 @@ func (o *OptionsDefClone) Run(args []string) error {
 	}
 
 	if !o.Try {
-		bs, _ = pkg.YamlIndent(dataOutput["def"])
-		urlKind := defClone.Kind
-		param["envNames"] = o.ToEnvNames
-		switch defClone.Kind {
-		case "deployContainerDefs":
-			param["deployContainerDefsYaml"] = string(bs)
-		case "customStepDef":
-			urlKind = fmt.Sprintf("%s/env", urlKind)
-			param["customStepName"] = o.StepName
-			param["customStepDefYaml"] = string(bs)
-		}
 		logHeader := fmt.Sprintf("[%s/%s]", defClone.ProjectName, defClone.Kind)
-		result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s/%s", defClone.ProjectName, urlKind), http.MethodPut, "", param, false)
+		defClone.Def = dataOutput["def"]
+		msg, err := o.Client().CloneDef(defClone, o.StepName, o.ToEnvNames)
 		if err != nil {
 			err = fmt.Errorf("%s: %s", logHeader, err.Error())
 			return err
 		}
-		msg := result.Get("msg").String()
 		log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
 	}
 
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
-	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -14,12 +14,18 @@ type OptionsDefDelete struct {
 	ModuleNames    []string `yaml:"moduleNames" json:"moduleNames" bson:"moduleNames" validate:""`
 	EnvNames       []string `yaml:"envNames" json:"envNames" bson:"envNames" validate:""`
 	StepNames      []string `yaml:"stepNames" json:"stepNames" bson:"stepNames" validate:""`
+	ModulePattern  string   `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+	Selector       string   `yaml:"selector" json:"selector" bson:"selector" validate:""`
 	Try            bool     `yaml:"try" json:"try" bson:"try" validate:""`
+	Cascade        bool     `yaml:"cascade" json:"cascade" bson:"cascade" validate:""`
+	Yes            bool     `yaml:"yes" json:"yes" bson:"yes" validate:""`
 	Full           bool     `yaml:"full" json:"full" bson:"full" validate:""`
 	Output         string   `yaml:"output" json:"output" bson:"output" validate:""`
 	Param          struct {
-		Kind        string `yaml:"kind" json:"kind" bson:"kind" validate:""`
-		ProjectName string `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		Kind          string          `yaml:"kind" json:"kind" bson:"kind" validate:""`
+		ProjectName   string          `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		ModulePattern pkg.NamePattern `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+		Selector      pkg.Selector    `yaml:"selector" json:"selector" bson:"selector" validate:""`
 	}
 }
 
@@ -40,21 +46,42 @@ func NewCmdDefDelete() *cobra.Command {
 		"step",
 	}
 
-	msgUse := fmt.Sprintf(`delete [projectName] [kind] [--modules=moduleName1,moduleName2] [--envs=envName1,envName2] [--steps=stepName1,stepName2] [--output=json|yaml]
+	msgUse := fmt.Sprintf(`delete [projectName] [kind] [--modules=moduleName1,moduleName2] [--module-pattern=pattern] [--selector=selector] [--envs=envName1,envName2] [--steps=stepName1,stepName2] [--output=json|yaml]
 # kind options: %s`, strings.Join(defCmdKinds, " / "))
 	msgShort := fmt.Sprintf("delete modules from project definitions")
-	msgLong := fmt.Sprintf(`delete modules from project definitions in dory-core server`)
+	msgLong := fmt.Sprintf(`delete modules from project definitions in dory-core server
+# --module-pattern select modules by glob pattern, or by regular expression with %s prefix
+# --selector select deploy and step definitions by metadata labels envName, stepName and enableMode, selector format:
+#   key=value, key!=value, key in (value1,value2), key notin (value1,value2), key (exists), !key (not exists)
+# if kind is step and without --envs, --selector selects both project and environments step definitions
+# with --cascade, definitions depend on the deleted modules are deleted or updated, dependencies:
+#   build -> packageDefs relatedBuilds -> deployContainerDefs relatedPackage in all environments
+#   -> customStepDef modules with the same moduleName or relatedStepModules -> pipelineDef builds
+#   packages are deleted if all relatedBuilds are deleted, otherwise relatedBuilds are updated,
+#   the delete plan is shown and confirmation is required before apply, use --yes to skip confirmation`, pkg.NamePatternRegexPrefix)
 	msgExample := fmt.Sprintf(`  # delete modules from project build definitions
   doryctl def delete test-project1 build --modules=tp1-gin-demo,tp1-node-demo
 
   # delete modules from project deploy definitions in envNames
   doryctl def delete test-project1 deploy --modules=tp1-gin-demo,tp1-node-demo --envs=test
 
+  # delete all *-api modules from project deploy definitions in test and uat environments
+  doryctl def delete test-project1 deploy --module-pattern='*-api' -l 'envName in (test,uat)'
+
   # delete modules from project step definitions in stepNames
   doryctl def delete test-project1 step --modules=tp1-gin-demo,tp1-node-demo --steps=customStepName1
 
   # delete modules from project step definitions in envNames and stepNames
-  doryctl def delete test-project1 step --modules=tp1-gin-demo,tp1-node-demo --envs=test --steps=customStepName1`)
+  doryctl def delete test-project1 step --modules=tp1-gin-demo,tp1-node-demo --envs=test --steps=customStepName1
+
+  # delete build module and all packages, deploys, custom step modules and pipeline builds depend on it
+  doryctl def delete test-project1 build --modules=tp1-gin-demo --cascade
+
+  # show the cascading delete plan only, not apply to dory-core server
+  doryctl def delete test-project1 build --modules=tp1-gin-demo --cascade --try
+
+  # delete package module and the deploys depend on it without confirmation
+  doryctl def delete test-project1 package --modules=tp1-gin-demo --cascade --yes`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -67,12 +94,16 @@ func NewCmdDefDelete() *cobra.Command {
 			CheckError(o.Run(args))
 		},
 	}
-	cmd.Flags().StringSliceVar(&o.ModuleNames, "modules", []string{}, "moduleNames to delete")
-	cmd.Flags().StringSliceVar(&o.EnvNames, "envs", []string{}, "filter project definitions in envNames, required if kind is deploy")
-	cmd.Flags().StringSliceVar(&o.StepNames, "steps", []string{}, "filter project definitions in stepNames, required if kind is step")
+	cmd.Flags().StringSliceVar(&o.ModuleNames, "modules", []string{}, "moduleNames to delete, --modules or --module-pattern required")
+	cmd.Flags().StringVar(&o.ModulePattern, "module-pattern", "", "moduleName glob pattern to delete, or regular expression with regex: prefix, example: *-api")
+	cmd.Flags().StringSliceVar(&o.EnvNames, "envs", []string{}, "filter project definitions in envNames, --envs or --selector required if kind is deploy")
+	cmd.Flags().StringSliceVar(&o.StepNames, "steps", []string{}, "filter project definitions in stepNames, --steps or --selector required if kind is step")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "filter deploy and step definitions by metadata labels selector, example: envName in (test,uat)")
 	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
 	cmd.Flags().BoolVar(&o.Full, "full", false, "output project definitions in full version, use with --output option")
 	cmd.Flags().BoolVar(&o.Try, "try", false, "try to check input project definitions only, not apply to dory-core server, use with --output option")
+	cmd.Flags().BoolVar(&o.Cascade, "cascade", false, "delete or update the definitions depend on the deleted modules, show the delete plan and confirm before apply")
+	cmd.Flags().BoolVarP(&o.Yes, "yes", "y", false, "apply the cascading delete plan without confirmation, use with --cascade option")
 
 	CheckError(o.Complete(cmd))
 	return cmd
@@ -245,11 +276,6 @@ func (o *OptionsDefDelete) Complete(cmd *cobra.Command) error {
 		return err
 	}
 
-	err = cmd.MarkFlagRequired("modules")
-	if err != nil {
-		return err
-	}
-
 	return err
 }
 
@@ -302,8 +328,8 @@ func (o *OptionsDefDelete) Validate(args []string) error {
 	}
 	o.Param.Kind = kind
 
-	if len(o.ModuleNames) == 0 {
-		err = fmt.Errorf("--modules required")
+	if len(o.ModuleNames) == 0 && o.ModulePattern == "" {
+		err = fmt.Errorf("--modules or --module-pattern required")
 		return err
 	}
 	for _, moduleName := range o.ModuleNames {
@@ -313,13 +339,28 @@ func (o *OptionsDefDelete) Validate(args []string) error {
 			return err
 		}
 	}
+	o.Param.ModulePattern, err = pkg.ParseNamePattern(o.ModulePattern)
+	if err != nil {
+		err = fmt.Errorf("--module-pattern %s format error: %s", o.ModulePattern, err.Error())
+		return err
+	}
 
-	if o.Param.Kind == "deploy" && len(o.EnvNames) == 0 {
-		err = fmt.Errorf("kind is deploy, --envs required")
+	o.Param.Selector, err = pkg.ParseSelector(o.Selector)
+	if err != nil {
+		err = fmt.Errorf("--selector %s format error: %s", o.Selector, err.Error())
 		return err
 	}
-	if o.Param.Kind == "step" && len(o.StepNames) == 0 {
-		err = fmt.Errorf("kind is step, --steps required")
+	if len(o.Param.Selector) > 0 && o.Param.Kind != "deploy" && o.Param.Kind != "step" {
+		err = fmt.Errorf("--selector only uses with kind deploy / step")
+		return err
+	}
+
+	if o.Param.Kind == "deploy" && len(o.EnvNames) == 0 && len(o.Param.Selector) == 0 {
+		err = fmt.Errorf("kind is deploy, --envs or --selector required")
+		return err
+	}
+	if o.Param.Kind == "step" && len(o.StepNames) == 0 && len(o.Param.Selector) == 0 {
+		err = fmt.Errorf("kind is step, --steps or --selector required")
 		return err
 	}
 
@@ -329,19 +370,18 @@ func (o *OptionsDefDelete) Validate(args []string) error {
 			return err
 		}
 	}
+
+	if o.Yes && !o.Cascade {
+		err = fmt.Errorf("--yes only uses with --cascade")
+		return err
+	}
 	return err
 }
 
 func (o *OptionsDefDelete) Run(args []string) error {
 	var err error
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s", o.Param.ProjectName), http.MethodGet, "", param, false)
-	if err != nil {
-		return err
-	}
-	project := pkg.ProjectOutput{}
-	err = json.Unmarshal([]byte(result.Get("data.project").Raw), &project)
+	project, err := o.GetProjectDef(o.Param.ProjectName)
 	if err != nil {
 		return err
 	}
@@ -363,13 +403,7 @@ func (o *OptionsDefDelete) Run(args []string) error {
 		defKind.Kind = pkg.DefCmdKinds[o.Param.Kind]
 		ids := []int{}
 		for i, def := range project.ProjectDef.BuildDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.BuildName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.BuildName, o.ModuleNames, o.Param.ModulePattern)
 			if found {
 				ids = append(ids, i)
 			}
@@ -400,13 +434,7 @@ func (o *OptionsDefDelete) Run(args []string) error {
 		defKind.Status.ErrMsg = project.ProjectDef.ErrMsgPackageDefs
 		ids := []int{}
 		for i, def := range project.ProjectDef.PackageDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.PackageName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.PackageName, o.ModuleNames, o.Param.ModulePattern)
 			if found {
 				ids = append(ids, i)
 			}
@@ -434,10 +462,14 @@ func (o *OptionsDefDelete) Run(args []string) error {
 	case "deploy":
 		paes := []pkg.ProjectAvailableEnv{}
 		for _, pae := range project.ProjectAvailableEnvs {
-			for _, envName := range o.EnvNames {
-				if envName == pae.EnvName {
-					paes = append(paes, pae)
-					break
+			if len(o.EnvNames) == 0 {
+				paes = append(paes, pae)
+			} else {
+				for _, envName := range o.EnvNames {
+					if envName == pae.EnvName {
+						paes = append(paes, pae)
+						break
+					}
 				}
 			}
 		}
@@ -449,15 +481,12 @@ func (o *OptionsDefDelete) Run(args []string) error {
 				defKind.Metadata.Labels = map[string]string{
 					"envName": pae.EnvName,
 				}
+				if !o.Param.Selector.Match(defKind.Metadata) {
+					continue
+				}
 				ids := []int{}
 				for i, def := range pae.DeployContainerDefs {
-					var found bool
-					for _, moduleName := range o.ModuleNames {
-						if def.DeployName == moduleName {
-							found = true
-							break
-						}
-					}
+					found := MatchModuleName(def.DeployName, o.ModuleNames, o.Param.ModulePattern)
 					if found {
 						ids = append(ids, i)
 					}
@@ -491,13 +520,7 @@ func (o *OptionsDefDelete) Run(args []string) error {
 		defKind.Kind = pkg.DefCmdKinds[o.Param.Kind]
 		ids := []int{}
 		for i, def := range project.ProjectDef.CustomOpsDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.CustomOpsName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.CustomOpsName, o.ModuleNames, o.Param.ModulePattern)
 			if found {
 				ids = append(ids, i)
 			}
@@ -523,13 +546,18 @@ func (o *OptionsDefDelete) Run(args []string) error {
 		}
 		defUpdates = append(defUpdates, defUpdate)
 	case "step":
-		if len(o.EnvNames) > 0 {
+		// without --envs, the selector selects both project and environments step definitions
+		if len(o.EnvNames) > 0 || len(o.Param.Selector) > 0 {
 			paes := []pkg.ProjectAvailableEnv{}
 			for _, pae := range project.ProjectAvailableEnvs {
-				for _, envName := range o.EnvNames {
-					if envName == pae.EnvName {
-						paes = append(paes, pae)
-						break
+				if len(o.EnvNames) == 0 {
+					paes = append(paes, pae)
+				} else {
+					for _, envName := range o.EnvNames {
+						if envName == pae.EnvName {
+							paes = append(paes, pae)
+							break
+						}
 					}
 				}
 			}
@@ -563,16 +591,13 @@ func (o *OptionsDefDelete) Run(args []string) error {
 							"stepName":   stepName,
 							"enableMode": csd.EnableMode,
 						}
+						if !o.Param.Selector.Match(defKind.Metadata) {
+							continue
+						}
 
 						ids := []int{}
 						for i, csmd := range csd.CustomStepModuleDefs {
-							var found bool
-							for _, moduleName := range o.ModuleNames {
-								if csmd.ModuleName == moduleName {
-									found = true
-									break
-								}
-							}
+							found := MatchModuleName(csmd.ModuleName, o.ModuleNames, o.Param.ModulePattern)
 							if found {
 								ids = append(ids, i)
 							}
@@ -610,7 +635,8 @@ func (o *OptionsDefDelete) Run(args []string) error {
 					}
 				}
 			}
-		} else {
+		}
+		if len(o.EnvNames) == 0 {
 			csds := pkg.CustomStepDefs{}
 			for stepName, csd := range project.ProjectDef.CustomStepDefs {
 				if len(o.StepNames) == 0 {
@@ -638,16 +664,13 @@ func (o *OptionsDefDelete) Run(args []string) error {
 					"stepName":   stepName,
 					"enableMode": csd.EnableMode,
 				}
+				if !o.Param.Selector.Match(defKind.Metadata) {
+					continue
+				}
 
 				ids := []int{}
 				for i, csmd := range csd.CustomStepModuleDefs {
-					var found bool
-					for _, moduleName := range o.ModuleNames {
-						if csmd.ModuleName == moduleName {
-							found = true
-							break
-						}
-					}
+					found := MatchModuleName(csmd.ModuleName, o.ModuleNames, o.Param.ModulePattern)
 					if found {
 						ids = append(ids, i)
 					}
@@ -685,6 +708,13 @@ func (o *OptionsDefDelete) Run(args []string) error {
 		}
 	}
 
+	if o.Cascade {
+		defKinds, defUpdates, err = o.CascadeDefUpdates(project, defUpdates)
+		if err != nil {
+			return err
+		}
+	}
+
 	defKindList := pkg.DefKindList{
 		Kind: "list",
 		Defs: defKinds,
@@ -709,48 +739,412 @@ func (o *OptionsDefDelete) Run(args []string) error {
 		fmt.Println(string(bs))
 	}
 
+	if o.Cascade && len(defUpdates) == 0 {
+		log.Success("no modules matched, nothing to delete")
+		return err
+	}
+
 	if !o.Try {
-		for _, defUpdate := range defUpdates {
-			bs, _ = pkg.YamlIndent(defUpdate.Def)
-
-			param := map[string]interface{}{
-				"envName":        defUpdate.EnvName,
-				"customStepName": defUpdate.CustomStepName,
-				"branchName":     defUpdate.BranchName,
-			}
-			paramOutput := map[string]interface{}{}
-			for k, v := range param {
-				paramOutput[k] = v
-			}
-
-			urlKind := defUpdate.Kind
-			switch defUpdate.Kind {
-			case "buildDefs":
-				param["buildDefsYaml"] = string(bs)
-			case "packageDefs":
-				param["packageDefsYaml"] = string(bs)
-			case "deployContainerDefs":
-				param["deployContainerDefsYaml"] = string(bs)
-			case "customStepDef":
-				param["customStepDefYaml"] = string(bs)
-				if defUpdate.EnvName != "" {
-					urlKind = fmt.Sprintf("%s/env", urlKind)
-				}
-			case "customOpsDefs":
-				param["customOpsDefsYaml"] = string(bs)
-			}
-			paramOutput = pkg.RemoveMapEmptyItems(paramOutput)
-			bs, _ = json.Marshal(paramOutput)
-			logHeader := fmt.Sprintf("[%s/%s] %s", defUpdate.ProjectName, defUpdate.Kind, string(bs))
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s/%s", defUpdate.ProjectName, urlKind), http.MethodPost, "", param, false)
+		if o.Cascade && !o.Yes {
+			err = ConfirmApply(fmt.Sprintf("apply the delete plan, %d definitions will be changed", len(defUpdates)))
 			if err != nil {
-				err = fmt.Errorf("%s: %s", logHeader, err.Error())
 				return err
 			}
-			msg := result.Get("msg").String()
-			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
+		}
+		err = o.ApplyDefUpdates(defUpdates)
+		if err != nil {
+			return err
 		}
 	}
 
 	return err
 }
+
+// DefDeleteTarget is a module to delete in the cascading delete, EnvName and StepName are empty for project definitions
+type DefDeleteTarget struct {
+	Kind       string `yaml:"kind" json:"kind" bson:"kind" validate:""`
+	EnvName    string `yaml:"envName" json:"envName" bson:"envName" validate:""`
+	StepName   string `yaml:"stepName" json:"stepName" bson:"stepName" validate:""`
+	ModuleName string `yaml:"moduleName" json:"moduleName" bson:"moduleName" validate:""`
+}
+
+// CascadeDeleteModules delete the target modules from project, and delete or update the definitions depend on them,
+// return the changed project with update flags and the delete plan, packages are deleted if all relatedBuilds are deleted,
+// custom step modules are deleted if the module with the same name is deleted in the same scope
+func CascadeDeleteModules(project pkg.ProjectOutput, targets []DefDeleteTarget) (pkg.ProjectOutput, []string) {
+	plan := []string{}
+	projectName := project.ProjectInfo.ProjectName
+	modulePath := func(kind, envName, stepName, branchName, moduleName string) string {
+		defUpdate := pkg.DefUpdate{
+			Kind:           kind,
+			ProjectName:    projectName,
+			EnvName:        envName,
+			CustomStepName: stepName,
+			BranchName:     branchName,
+		}
+		return DefModulePath(defUpdate, moduleName)
+	}
+
+	deletedNames := map[string]bool{}
+	deletedDeploys := map[string]map[string]bool{}
+	reasons := map[DefDeleteTarget]string{}
+	queue := append([]DefDeleteTarget{}, targets...)
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		reason := reasons[t]
+		if reason == "" {
+			reason = "selected"
+		}
+		var found bool
+		switch t.Kind {
+		case "buildDefs":
+			defs := []pkg.BuildDef{}
+			for _, d := range project.ProjectDef.BuildDefs {
+				if d.BuildName == t.ModuleName {
+					found = true
+					continue
+				}
+				defs = append(defs, d)
+			}
+			if !found {
+				continue
+			}
+			project.ProjectDef.BuildDefs = defs
+			project.ProjectDef.UpdateBuildDefs = true
+			deletedNames[t.ModuleName] = true
+			plan = append(plan, fmt.Sprintf("delete %s: %s", modulePath(t.Kind, "", "", "", t.ModuleName), reason))
+
+			for i, d := range project.ProjectDef.PackageDefs {
+				relatedBuilds := []string{}
+				for _, name := range d.RelatedBuilds {
+					if name != t.ModuleName {
+						relatedBuilds = append(relatedBuilds, name)
+					}
+				}
+				if len(relatedBuilds) == len(d.RelatedBuilds) {
+					continue
+				}
+				if len(relatedBuilds) == 0 {
+					target := DefDeleteTarget{Kind: "packageDefs", ModuleName: d.PackageName}
+					reasons[target] = fmt.Sprintf("all relatedBuilds deleted")
+					queue = append(queue, target)
+				} else {
+					project.ProjectDef.PackageDefs[i].RelatedBuilds = relatedBuilds
+					project.ProjectDef.UpdatePackageDefs = true
+					plan = append(plan, fmt.Sprintf("update %s: relatedBuilds %s removed", modulePath("packageDefs", "", "", "", d.PackageName), t.ModuleName))
+				}
+			}
+
+			for i, pp := range project.ProjectPipelines {
+				builds := []pkg.PipelineBuildDef{}
+				for _, build := range pp.PipelineDef.Builds {
+					if build.Name != t.ModuleName {
+						builds = append(builds, build)
+					}
+				}
+				if len(builds) == len(pp.PipelineDef.Builds) {
+					continue
+				}
+				project.ProjectPipelines[i].PipelineDef.Builds = builds
+				project.ProjectPipelines[i].UpdatePipelineDef = true
+				plan = append(plan, fmt.Sprintf("update %s: builds %s removed", modulePath("pipelineDef", "", "", pp.BranchName, ""), t.ModuleName))
+			}
+		case "packageDefs":
+			defs := []pkg.PackageDef{}
+			for _, d := range project.ProjectDef.PackageDefs {
+				if d.PackageName == t.ModuleName {
+					found = true
+					continue
+				}
+				defs = append(defs, d)
+			}
+			if !found {
+				continue
+			}
+			project.ProjectDef.PackageDefs = defs
+			project.ProjectDef.UpdatePackageDefs = true
+			deletedNames[t.ModuleName] = true
+			plan = append(plan, fmt.Sprintf("delete %s: %s", modulePath(t.Kind, "", "", "", t.ModuleName), reason))
+
+			for _, pae := range project.ProjectAvailableEnvs {
+				for _, d := range pae.DeployContainerDefs {
+					if d.RelatedPackage == t.ModuleName {
+						target := DefDeleteTarget{Kind: "deployContainerDefs", EnvName: pae.EnvName, ModuleName: d.DeployName}
+						reasons[target] = fmt.Sprintf("relatedPackage %s deleted", t.ModuleName)
+						queue = append(queue, target)
+					}
+				}
+			}
+		case "deployContainerDefs":
+			for i, pae := range project.ProjectAvailableEnvs {
+				if pae.EnvName != t.EnvName {
+					continue
+				}
+				defs := []pkg.DeployContainerDef{}
+				for _, d := range pae.DeployContainerDefs {
+					if d.DeployName == t.ModuleName {
+						found = true
+						continue
+					}
+					defs = append(defs, d)
+				}
+				if found {
+					project.ProjectAvailableEnvs[i].DeployContainerDefs = defs
+					project.ProjectAvailableEnvs[i].UpdateDeployContainerDefs = true
+				}
+			}
+			if !found {
+				continue
+			}
+			if deletedDeploys[t.EnvName] == nil {
+				deletedDeploys[t.EnvName] = map[string]bool{}
+			}
+			deletedDeploys[t.EnvName][t.ModuleName] = true
+			plan = append(plan, fmt.Sprintf("delete %s: %s", modulePath(t.Kind, t.EnvName, "", "", t.ModuleName), reason))
+		case "customOpsDefs":
+			defs := []pkg.CustomOpsDef{}
+			for _, d := range project.ProjectDef.CustomOpsDefs {
+				if d.CustomOpsName == t.ModuleName {
+					found = true
+					continue
+				}
+				defs = append(defs, d)
+			}
+			if !found {
+				continue
+			}
+			project.ProjectDef.CustomOpsDefs = defs
+			project.ProjectDef.UpdateCustomOpsDefs = true
+			plan = append(plan, fmt.Sprintf("delete %s: %s", modulePath(t.Kind, "", "", "", t.ModuleName), reason))
+		case "customStepDef":
+			csds := project.ProjectDef.CustomStepDefs
+			if t.EnvName != "" {
+				csds = nil
+				for _, pae := range project.ProjectAvailableEnvs {
+					if pae.EnvName == t.EnvName {
+						csds = pae.CustomStepDefs
+						break
+					}
+				}
+			}
+			csd, ok := csds[t.StepName]
+			if !ok {
+				continue
+			}
+			csmds := []pkg.CustomStepModuleDef{}
+			for _, csmd := range csd.CustomStepModuleDefs {
+				if csmd.ModuleName == t.ModuleName {
+					found = true
+					continue
+				}
+				csmds = append(csmds, csmd)
+			}
+			if !found {
+				continue
+			}
+			csd.CustomStepModuleDefs = csmds
+			csd.UpdateCustomStepModuleDefs = true
+			csds[t.StepName] = csd
+			plan = append(plan, fmt.Sprintf("delete %s: %s", modulePath(t.Kind, t.EnvName, t.StepName, "", t.ModuleName), reason))
+		}
+	}
+
+	// custom step modules depend on the modules deleted in the same scope, and not exist in other kinds
+	cascadeCustomStepDefs := func(envName string, csds pkg.CustomStepDefs, deletedDeployNames map[string]bool, deployNames map[string]bool) {
+		names := map[string]bool{}
+		for _, d := range project.ProjectDef.BuildDefs {
+			names[d.BuildName] = true
+		}
+		for _, d := range project.ProjectDef.PackageDefs {
+			names[d.PackageName] = true
+		}
+		for name := range deployNames {
+			names[name] = true
+		}
+		isDeleted := func(name string) bool {
+			return (deletedNames[name] || deletedDeployNames[name]) && !names[name]
+		}
+		stepNames := []string{}
+		for stepName := range csds {
+			stepNames = append(stepNames, stepName)
+		}
+		sort.Strings(stepNames)
+		for _, stepName := range stepNames {
+			csd := csds[stepName]
+			csmds := []pkg.CustomStepModuleDef{}
+			var changed bool
+			for _, csmd := range csd.CustomStepModuleDefs {
+				path := modulePath("customStepDef", envName, stepName, "", csmd.ModuleName)
+				if isDeleted(csmd.ModuleName) {
+					changed = true
+					plan = append(plan, fmt.Sprintf("delete %s: module %s deleted", path, csmd.ModuleName))
+					continue
+				}
+				relatedStepModules := []string{}
+				removed := []string{}
+				for _, name := range csmd.RelatedStepModules {
+					if isDeleted(name) {
+						removed = append(removed, name)
+					} else {
+						relatedStepModules = append(relatedStepModules, name)
+					}
+				}
+				if len(removed) > 0 {
+					changed = true
+					csmd.RelatedStepModules = relatedStepModules
+					plan = append(plan, fmt.Sprintf("update %s: relatedStepModules %s removed", path, strings.Join(removed, ",")))
+				}
+				csmds = append(csmds, csmd)
+			}
+			if changed {
+				csd.CustomStepModuleDefs = csmds
+				csd.UpdateCustomStepModuleDefs = true
+				csds[stepName] = csd
+			}
+		}
+	}
+	allDeletedDeploys := map[string]bool{}
+	allDeploys := map[string]bool{}
+	for _, pae := range project.ProjectAvailableEnvs {
+		for name := range deletedDeploys[pae.EnvName] {
+			allDeletedDeploys[name] = true
+		}
+		for _, d := range pae.DeployContainerDefs {
+			allDeploys[d.DeployName] = true
+		}
+	}
+	cascadeCustomStepDefs("", project.ProjectDef.CustomStepDefs, allDeletedDeploys, allDeploys)
+	for _, pae := range project.ProjectAvailableEnvs {
+		deployNames := map[string]bool{}
+		for _, d := range pae.DeployContainerDefs {
+			deployNames[d.DeployName] = true
+		}
+		cascadeCustomStepDefs(pae.EnvName, pae.CustomStepDefs, deletedDeploys[pae.EnvName], deployNames)
+	}
+
+	return project, plan
+}
+
+// DefUpdateDefKind convert the DefUpdate to DefKind, the metadata labels are envName, stepName, enableMode and branchName
+func DefUpdateDefKind(defUpdate pkg.DefUpdate) pkg.DefKind {
+	defKind := pkg.DefKind{
+		Kind: defUpdate.Kind,
+		Metadata: pkg.DefMetadata{
+			ProjectName: defUpdate.ProjectName,
+			Labels:      map[string]string{},
+		},
+		Items: []interface{}{},
+	}
+	if defUpdate.EnvName != "" {
+		defKind.Metadata.Labels["envName"] = defUpdate.EnvName
+	}
+	if defUpdate.CustomStepName != "" {
+		defKind.Metadata.Labels["stepName"] = defUpdate.CustomStepName
+	}
+	if defUpdate.BranchName != "" {
+		defKind.Metadata.Labels["branchName"] = defUpdate.BranchName
+	}
+	switch def := defUpdate.Def.(type) {
+	case []pkg.BuildDef:
+		for _, d := range def {
+			defKind.Items = append(defKind.Items, d)
+		}
+	case []pkg.PackageDef:
+		for _, d := range def {
+			defKind.Items = append(defKind.Items, d)
+		}
+	case []pkg.DeployContainerDef:
+		for _, d := range def {
+			defKind.Items = append(defKind.Items, d)
+		}
+	case []pkg.CustomOpsDef:
+		for _, d := range def {
+			defKind.Items = append(defKind.Items, d)
+		}
+	case []string:
+		for _, d := range def {
+			defKind.Items = append(defKind.Items, d)
+		}
+	case pkg.CustomStepDef:
+		defKind.Metadata.Labels["enableMode"] = def.EnableMode
+		for _, d := range def.CustomStepModuleDefs {
+			defKind.Items = append(defKind.Items, d)
+		}
+	case pkg.PipelineDef:
+		defKind.Items = append(defKind.Items, def)
+	}
+	return defKind
+}
+
+// CascadeDefUpdates return the definitions and updates of the cascading delete, the deleted modules are the modules in project but not in defUpdates,
+// the delete plan is printed, updates are sorted in reverse apply order to delete the dependents first
+func (o *OptionsDefDelete) CascadeDefUpdates(project pkg.ProjectOutput, defUpdates []pkg.DefUpdate) ([]pkg.DefKind, []pkg.DefUpdate, error) {
+	var err error
+	defKinds := []pkg.DefKind{}
+	cascadeUpdates := []pkg.DefUpdate{}
+
+	targets := []DefDeleteTarget{}
+	for _, defUpdate := range defUpdates {
+		current := GetProjectDefUpdate(project, defUpdate)
+		currentModules, _, err := DefUpdateModules(current)
+		if err != nil {
+			return defKinds, cascadeUpdates, err
+		}
+		desiredModules, _, err := DefUpdateModules(defUpdate)
+		if err != nil {
+			return defKinds, cascadeUpdates, err
+		}
+		moduleNames := []string{}
+		for moduleName := range currentModules {
+			if _, ok := desiredModules[moduleName]; !ok {
+				moduleNames = append(moduleNames, moduleName)
+			}
+		}
+		sort.Strings(moduleNames)
+		for _, moduleName := range moduleNames {
+			targets = append(targets, DefDeleteTarget{
+				Kind:       defUpdate.Kind,
+				EnvName:    defUpdate.EnvName,
+				StepName:   defUpdate.CustomStepName,
+				ModuleName: moduleName,
+			})
+		}
+	}
+	if len(targets) == 0 {
+		return defKinds, cascadeUpdates, err
+	}
+
+	desiredProject, err := CopyProjectOutput(project)
+	if err != nil {
+		return defKinds, cascadeUpdates, err
+	}
+	desiredProject, plan := CascadeDeleteModules(desiredProject, targets)
+	// only print the definitions with --output, the output can be applied by def apply
+	if o.Output == "" {
+		for _, s := range plan {
+			log.Info(s)
+		}
+	}
+
+	updates := GetDefUpdates(desiredProject)
+	SortDefUpdates(updates)
+	for i := len(updates) - 1; i >= 0; i-- {
+		defUpdate := updates[i]
+		current := GetProjectDefUpdate(project, defUpdate)
+		diffs, err := DiffDefUpdate(current, defUpdate, 0)
+		if err != nil {
+			return defKinds, cascadeUpdates, err
+		}
+		if len(diffs) == 0 {
+			continue
+		}
+		defKinds = append(defKinds, DefUpdateDefKind(defUpdate))
+		cascadeUpdates = append(cascadeUpdates, defUpdate)
+	}
+	if o.Output == "" {
+		log.Info(fmt.Sprintf("delete plan: %d modules deleted or updated, %d definitions will be changed", len(plan), len(cascadeUpdates)))
+	}
+
+	return defKinds, cascadeUpdates, err
+}
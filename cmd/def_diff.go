@@ -0,0 +1,420 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"sort"
+	"strings"
+)
+
+type OptionsDefDiff struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	FileNames      []string `yaml:"fileNames" json:"fileNames" bson:"fileNames" validate:""`
+	Recursive      bool     `yaml:"recursive" json:"recursive" bson:"recursive" validate:""`
+	Unified        int      `yaml:"unified" json:"unified" bson:"unified" validate:""`
+	Param          struct {
+		FileNames []string      `yaml:"fileNames" json:"fileNames" bson:"fileNames" validate:""`
+		Defs      []pkg.DefKind `yaml:"defs" json:"defs" bson:"defs" validate:""`
+	}
+}
+
+func NewOptionsDefDiff() *OptionsDefDiff {
+	var o OptionsDefDiff
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdDefDiff() *cobra.Command {
+	o := NewOptionsDefDiff()
+
+	msgUse := fmt.Sprintf(`diff -f [filename]`)
+	msgShort := fmt.Sprintf("diff project definitions with dory-core server")
+	msgLong := fmt.Sprintf(`compare project definitions files with the project definitions in dory-core server.
+# it shows what will be changed by def apply, in unified diff format for each kind / env / module.
+# JSON and YAML formats are accepted, the same as def apply.
+# exit status is 0 if no differences, 1 if differences found, 2 if error occurred, the same as diff(1).`)
+	msgExample := fmt.Sprintf(`  # diff project definitions from file or directory
+  doryctl def diff -f def1.yaml -f def2.json
+
+  # diff project definitions from directory recursively
+  doryctl def diff -f defs/ -r
+
+  # diff project definitions from stdin
+  cat def1.yaml | doryctl def diff -f -`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(DiffExitError(o.Validate(args)))
+			CheckError(DiffExitError(o.Run(args)))
+		},
+	}
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false, "process the directory used in -f, --files recursively")
+	cmd.Flags().StringSliceVarP(&o.FileNames, "files", "f", []string{}, "project definitions file name or directory, support *.json and *.yaml and *.yml files")
+	cmd.Flags().IntVarP(&o.Unified, "unified", "U", 3, "number of context lines around the differences")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsDefDiff) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.MarkFlagRequired("files")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsDefDiff) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if o.Unified < 0 {
+		err = fmt.Errorf("--unified must be greater than or equal to 0")
+		return err
+	}
+
+	o.Param.FileNames, o.Param.Defs, err = GetDefKindsFromFiles(o.FileNames, o.Recursive)
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+// DefUpdatePath return the path of DefUpdate, example: test-project1/deployContainerDefs/test
+func DefUpdatePath(defUpdate pkg.DefUpdate) string {
+	items := []string{defUpdate.ProjectName, defUpdate.Kind}
+	for _, s := range []string{defUpdate.EnvName, defUpdate.CustomStepName, defUpdate.BranchName} {
+		if s != "" {
+			items = append(items, s)
+		}
+	}
+	return strings.Join(items, "/")
+}
+
+// DefModulePath return the path of module in the definition, example: project/kind/env/step/module
+func DefModulePath(defUpdate pkg.DefUpdate, moduleName string) string {
+	if moduleName == "" {
+		return DefUpdatePath(defUpdate)
+	}
+	return fmt.Sprintf("%s/%s", DefUpdatePath(defUpdate), moduleName)
+}
+
+// GetProjectDefUpdate return the current definition in project with the same kind / env / step / branch of defUpdate,
+// Def is nil if the definition not exists
+func GetProjectDefUpdate(project pkg.ProjectOutput, defUpdate pkg.DefUpdate) pkg.DefUpdate {
+	current := defUpdate
+	current.Def = nil
+	switch defUpdate.Kind {
+	case "buildDefs":
+		if len(project.ProjectDef.BuildDefs) > 0 {
+			current.Def = project.ProjectDef.BuildDefs
+		}
+	case "packageDefs":
+		if len(project.ProjectDef.PackageDefs) > 0 {
+			current.Def = project.ProjectDef.PackageDefs
+		}
+	case "customOpsDefs":
+		if len(project.ProjectDef.CustomOpsDefs) > 0 {
+			current.Def = project.ProjectDef.CustomOpsDefs
+		}
+	case "dockerIgnoreDefs":
+		if len(project.ProjectDef.DockerIgnoreDefs) > 0 {
+			current.Def = project.ProjectDef.DockerIgnoreDefs
+		}
+	case "deployContainerDefs":
+		for _, pae := range project.ProjectAvailableEnvs {
+			if pae.EnvName == defUpdate.EnvName && len(pae.DeployContainerDefs) > 0 {
+				current.Def = pae.DeployContainerDefs
+				break
+			}
+		}
+	case "customStepDef":
+		csds := project.ProjectDef.CustomStepDefs
+		if defUpdate.EnvName != "" {
+			csds = pkg.CustomStepDefs{}
+			for _, pae := range project.ProjectAvailableEnvs {
+				if pae.EnvName == defUpdate.EnvName {
+					csds = pae.CustomStepDefs
+					break
+				}
+			}
+		}
+		for stepName, csd := range csds {
+			if stepName == defUpdate.CustomStepName {
+				current.Def = csd
+				break
+			}
+		}
+	case "pipelineDef":
+		for _, pp := range project.ProjectPipelines {
+			if pp.BranchName == defUpdate.BranchName {
+				current.Def = pp.PipelineDef
+				break
+			}
+		}
+	}
+	return current
+}
+
+// DefUpdateModules return the yaml of each module in DefUpdate, the key is module name,
+// and the yaml of the definition fields not belong to any module, example: enableMode of customStepDef, empty if no such fields
+func DefUpdateModules(defUpdate pkg.DefUpdate) (map[string]string, string, error) {
+	var err error
+	modules := map[string]string{}
+	var fields string
+	if defUpdate.Def == nil {
+		return modules, fields, err
+	}
+
+	bs, err := json.Marshal(defUpdate.Def)
+	if err != nil {
+		return modules, fields, err
+	}
+	items := map[string]interface{}{}
+	var fieldItem interface{}
+	switch defUpdate.Kind {
+	case "buildDefs":
+		defs := []pkg.BuildDef{}
+		err = json.Unmarshal(bs, &defs)
+		for _, def := range defs {
+			items[def.BuildName] = def
+		}
+	case "packageDefs":
+		defs := []pkg.PackageDef{}
+		err = json.Unmarshal(bs, &defs)
+		for _, def := range defs {
+			items[def.PackageName] = def
+		}
+	case "deployContainerDefs":
+		defs := []pkg.DeployContainerDef{}
+		err = json.Unmarshal(bs, &defs)
+		for _, def := range defs {
+			items[def.DeployName] = def
+		}
+	case "customOpsDefs":
+		defs := []pkg.CustomOpsDef{}
+		err = json.Unmarshal(bs, &defs)
+		for _, def := range defs {
+			items[def.CustomOpsName] = def
+		}
+	case "customStepDef":
+		var csd pkg.CustomStepDef
+		err = json.Unmarshal(bs, &csd)
+		for _, def := range csd.CustomStepModuleDefs {
+			items[def.ModuleName] = def
+		}
+		fieldItem = map[string]string{"enableMode": csd.EnableMode}
+	case "pipelineDef":
+		var def pkg.PipelineDef
+		err = json.Unmarshal(bs, &def)
+		items[defUpdate.BranchName] = def
+	case "dockerIgnoreDefs":
+		defs := []string{}
+		err = json.Unmarshal(bs, &defs)
+		items[defUpdate.Kind] = defs
+	default:
+		err = fmt.Errorf("kind %s not support", defUpdate.Kind)
+	}
+	if err != nil {
+		err = fmt.Errorf("parse %s error: %s", DefUpdatePath(defUpdate), err.Error())
+		return modules, fields, err
+	}
+
+	for name, item := range items {
+		bs, err := pkg.YamlIndent(item)
+		if err != nil {
+			return modules, fields, err
+		}
+		modules[name] = string(bs)
+	}
+	if fieldItem != nil {
+		bs, err := pkg.YamlIndent(fieldItem)
+		if err != nil {
+			return modules, fields, err
+		}
+		fields = string(bs)
+	}
+	return modules, fields, err
+}
+
+// DiffDefUpdate return the unified diff of each module between current and desired definitions, sorted by module name
+func DiffDefUpdate(current, desired pkg.DefUpdate, contextLines int) ([]string, error) {
+	var err error
+	diffs := []string{}
+
+	currentModules, currentFields, err := DefUpdateModules(current)
+	if err != nil {
+		return diffs, err
+	}
+	desiredModules, desiredFields, err := DefUpdateModules(desired)
+	if err != nil {
+		return diffs, err
+	}
+
+	names := []string{}
+	for name := range desiredModules {
+		names = append(names, name)
+	}
+	for name := range currentModules {
+		if _, ok := desiredModules[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	defPath := DefUpdatePath(desired)
+	// the definition fields not belong to any module are diffed before the modules
+	if currentFields != "" || desiredFields != "" {
+		fromName := fmt.Sprintf("server/%s", defPath)
+		toName := fmt.Sprintf("local/%s", defPath)
+		if currentFields == "" {
+			fromName = "/dev/null"
+		}
+		if desiredFields == "" {
+			toName = "/dev/null"
+		}
+		diff := pkg.UnifiedDiff(fromName, toName, currentFields, desiredFields, contextLines)
+		if diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+	for _, name := range names {
+		fromName := fmt.Sprintf("server/%s/%s", defPath, name)
+		toName := fmt.Sprintf("local/%s/%s", defPath, name)
+		a, ok := currentModules[name]
+		if !ok {
+			fromName = "/dev/null"
+		}
+		b, ok := desiredModules[name]
+		if !ok {
+			toName = "/dev/null"
+		}
+		diff := pkg.UnifiedDiff(fromName, toName, a, b, contextLines)
+		if diff != "" {
+			diffs = append(diffs, diff)
+		}
+	}
+	return diffs, err
+}
+
+// ColorDiff colorize the unified diff output
+func ColorDiff(diff string) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+			lines[i] = color.New(color.Bold).Sprint(line)
+		} else if strings.HasPrefix(line, "@@") {
+			lines[i] = color.CyanString(line)
+		} else if strings.HasPrefix(line, "-") {
+			lines[i] = color.RedString(line)
+		} else if strings.HasPrefix(line, "+") {
+			lines[i] = color.GreenString(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CopyProjectOutput return a deep copy of project, MergeDefKinds changes the project in place
+func CopyProjectOutput(project pkg.ProjectOutput) (pkg.ProjectOutput, error) {
+	var err error
+	var p pkg.ProjectOutput
+	bs, err := json.Marshal(project)
+	if err != nil {
+		return p, err
+	}
+	err = json.Unmarshal(bs, &p)
+	if err != nil {
+		return p, err
+	}
+	return p, err
+}
+
+// DiffExitError set the exit code of errors to 2, the same as diff(1), exit code 1 means differences found
+func DiffExitError(err error) error {
+	var errExit *ExitError
+	if err != nil && !errors.As(err, &errExit) {
+		err = &ExitError{Code: pkg.ExitCodeDiffTrouble, Err: err}
+	}
+	return err
+}
+
+func (o *OptionsDefDiff) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	mapDefProjects := map[string][]pkg.DefKind{}
+	projectNames := []string{}
+	for _, def := range o.Param.Defs {
+		if _, ok := mapDefProjects[def.Metadata.ProjectName]; !ok {
+			projectNames = append(projectNames, def.Metadata.ProjectName)
+		}
+		mapDefProjects[def.Metadata.ProjectName] = append(mapDefProjects[def.Metadata.ProjectName], def)
+	}
+	sort.Strings(projectNames)
+
+	var diffCount int
+	for _, projectName := range projectNames {
+		defs := mapDefProjects[projectName]
+		project, err := o.GetProjectDef(projectName)
+		if err != nil {
+			return err
+		}
+		desiredProject, err := CopyProjectOutput(project)
+		if err != nil {
+			return err
+		}
+		desiredProject, err = MergeDefKinds(desiredProject, defs)
+		if err != nil {
+			return err
+		}
+
+		defUpdates := GetDefUpdates(desiredProject)
+		sort.SliceStable(defUpdates, func(i, j int) bool {
+			return DefUpdatePath(defUpdates[i]) < DefUpdatePath(defUpdates[j])
+		})
+		for _, defUpdate := range defUpdates {
+			current := GetProjectDefUpdate(project, defUpdate)
+			diffs, err := DiffDefUpdate(current, defUpdate, o.Unified)
+			if err != nil {
+				return err
+			}
+			for _, diff := range diffs {
+				fmt.Println(ColorDiff(diff))
+				diffCount++
+			}
+		}
+	}
+
+	if diffCount > 0 {
+		log.Warning(fmt.Sprintf("%d project definitions modules differ from dory-core server", diffCount))
+		err = &ExitError{Code: pkg.ExitCodeDiffFound}
+		return err
+	}
+	log.Success("project definitions are the same as dory-core server")
+
+	return err
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"github.com/dory-engine/dory-ctl/pkg"
+	"strings"
+	"testing"
+)
+
+func TestDiffDefUpdateCustomStepDef(t *testing.T) {
+	// customStepDef returns the scanCode definition of project test-project1
+	customStepDef := func(enableMode string, moduleNames ...string) pkg.DefUpdate {
+		csd := pkg.CustomStepDef{EnableMode: enableMode}
+		for _, moduleName := range moduleNames {
+			csd.CustomStepModuleDefs = append(csd.CustomStepModuleDefs, pkg.CustomStepModuleDef{ModuleName: moduleName})
+		}
+		return pkg.DefUpdate{Kind: "customStepDef", ProjectName: "test-project1", CustomStepName: "scanCode", Def: csd}
+	}
+	tests := []struct {
+		name    string
+		current pkg.DefUpdate
+		desired pkg.DefUpdate
+		want    []string
+	}{
+		{
+			name:    "not changed",
+			current: customStepDef("enable", "enableMode", "tp1-go-demo"),
+			desired: customStepDef("enable", "enableMode", "tp1-go-demo"),
+			want:    []string{},
+		},
+		{
+			name:    "enableMode changed",
+			current: customStepDef("enable", "tp1-go-demo"),
+			desired: customStepDef("disable", "tp1-go-demo"),
+			want:    []string{"local/test-project1/customStepDef/scanCode"},
+		},
+		{
+			name:    "module named enableMode added",
+			current: customStepDef("enable", "tp1-go-demo"),
+			desired: customStepDef("enable", "enableMode", "tp1-go-demo"),
+			want:    []string{"local/test-project1/customStepDef/scanCode/enableMode"},
+		},
+		{
+			name:    "module named enableMode deleted and enableMode changed",
+			current: customStepDef("enable", "enableMode", "tp1-go-demo"),
+			desired: customStepDef("disable", "tp1-go-demo"),
+			want: []string{
+				"local/test-project1/customStepDef/scanCode",
+				"/dev/null",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs, err := DiffDefUpdate(tt.current, tt.desired, 0)
+			if err != nil {
+				t.Fatalf("DiffDefUpdate error: %s", err.Error())
+			}
+			got := []string{}
+			for _, diff := range diffs {
+				// compare the to file name of each diff
+				lines := strings.SplitN(diff, "\n", 3)
+				got = append(got, strings.TrimPrefix(lines[1], "+++ "))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("DiffDefUpdate = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("DiffDefUpdate = %q, want %q", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
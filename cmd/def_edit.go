@@ -0,0 +1,561 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"sort"
+	"strings"
+)
+
+type OptionsDefEdit struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	ModuleNames    []string `yaml:"moduleNames" json:"moduleNames" bson:"moduleNames" validate:""`
+	EnvNames       []string `yaml:"envNames" json:"envNames" bson:"envNames" validate:""`
+	BranchNames    []string `yaml:"branchNames" json:"branchNames" bson:"branchNames" validate:""`
+	StepName       string   `yaml:"stepName" json:"stepName" bson:"stepName" validate:""`
+	ModulePattern  string   `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+	Selector       string   `yaml:"selector" json:"selector" bson:"selector" validate:""`
+	Param          struct {
+		Kind          string          `yaml:"kind" json:"kind" bson:"kind" validate:""`
+		ProjectName   string          `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		ModulePattern pkg.NamePattern `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+		Selector      pkg.Selector    `yaml:"selector" json:"selector" bson:"selector" validate:""`
+	}
+}
+
+func NewOptionsDefEdit() *OptionsDefEdit {
+	var o OptionsDefEdit
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdDefEdit() *cobra.Command {
+	o := NewOptionsDefEdit()
+
+	defCmdKinds := []string{
+		"build",
+		"package",
+		"deploy",
+		"ops",
+		"step",
+		"pipeline",
+	}
+
+	msgUse := fmt.Sprintf(`edit [projectName] [kind] [--modules=moduleName1,moduleName2] [--module-pattern=pattern] [--selector=selector] [--envs=envName1,envName2] [--branches=branchName1,branchName2] [--step=stepName]
+  # kind options: %s`, strings.Join(defCmdKinds, " / "))
+	msgShort := fmt.Sprintf("edit project definitions in editor")
+	msgLong := fmt.Sprintf(`edit project definitions in dory-core server with the editor defined by $EDITOR environment variable, default editor is %s
+# the selected definitions are opened in YAML format, after the editor exits, the definitions are checked the same as def apply,
+# if check failed, the definitions are re-opened with the error as comments at the top, save without changes to abort the edit.
+# only the changed modules are applied to dory-core server, save an empty file to cancel the edit.
+# modules removed in the editor are not deleted from dory-core server, use def delete to delete modules.`, pkg.EditorDefault)
+	msgExample := fmt.Sprintf(`  # edit project build modules definitions
+  doryctl def edit test-project1 build --modules=tp1-go-demo,tp1-gin-demo
+
+  # edit project deploy modules definitions in test environment with vim
+  EDITOR=vim doryctl def edit test-project1 deploy --modules=tp1-gin-demo --envs=test
+
+  # edit project deploy modules definitions match the pattern in test and uat environments
+  doryctl def edit test-project1 deploy --module-pattern='*-api' -l 'envName in (test,uat)'
+
+  # edit project custom step modules definitions in test environment
+  doryctl def edit test-project1 step --envs=test --step=customStepName2 --modules=tp1-gin-demo
+
+  # edit project pipeline definitions
+  doryctl def edit test-project1 pipeline --branches=develop,release`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringSliceVar(&o.ModuleNames, "modules", []string{}, "filter moduleNames to edit, --modules or --module-pattern required if kind is not pipeline")
+	cmd.Flags().StringVar(&o.ModulePattern, "module-pattern", "", "filter moduleNames to edit by glob pattern, or regular expression with regex: prefix, example: *-api")
+	cmd.Flags().StringSliceVar(&o.EnvNames, "envs", []string{}, "filter envNames to edit, --envs or --selector required if kind is deploy")
+	cmd.Flags().StringSliceVar(&o.BranchNames, "branches", []string{}, "filter branchNames to edit, --branches or --selector required if kind is pipeline")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "filter deploy, step and pipeline definitions to edit by metadata labels or annotations selector, example: envName in (test,uat)")
+	cmd.Flags().StringVar(&o.StepName, "step", "", "filter stepName to edit, required if kind is step")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsDefEdit) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	defCmdKinds := []string{
+		"build",
+		"package",
+		"deploy",
+		"ops",
+		"step",
+		"pipeline",
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		projectNames, err := o.GetProjectNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		if len(args) == 0 {
+			return projectNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) == 1 {
+			return defCmdKinds, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("envs", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		projectName := args[0]
+		project, err := o.GetProjectDef(projectName)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		envNames := []string{}
+		for _, pae := range project.ProjectAvailableEnvs {
+			envNames = append(envNames, pae.EnvName)
+		}
+		return envNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("branches", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		projectName := args[0]
+		project, err := o.GetProjectDef(projectName)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		branchNames := []string{}
+		for _, pp := range project.ProjectPipelines {
+			branchNames = append(branchNames, pp.BranchName)
+		}
+		return branchNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("step", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		projectName := args[0]
+		project, err := o.GetProjectDef(projectName)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		stepNames := []string{}
+		for _, conf := range project.CustomStepConfs {
+			stepNames = append(stepNames, conf.CustomStepName)
+		}
+		return stepNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("modules", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		moduleNames := []string{}
+		projectName := args[0]
+		kind := args[1]
+		step, _ := cmd.Flags().GetString("step")
+		envs, _ := cmd.Flags().GetStringSlice("envs")
+		project, err := o.GetProjectDef(projectName)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		switch kind {
+		case "build":
+			for _, def := range project.ProjectDef.BuildDefs {
+				moduleNames = append(moduleNames, def.BuildName)
+			}
+		case "package":
+			for _, def := range project.ProjectDef.PackageDefs {
+				moduleNames = append(moduleNames, def.PackageName)
+			}
+		case "deploy":
+			m := map[string]string{}
+			if len(envs) == 0 {
+				for _, pae := range project.ProjectAvailableEnvs {
+					for _, def := range pae.DeployContainerDefs {
+						m[def.DeployName] = def.DeployName
+					}
+				}
+				for k, _ := range m {
+					moduleNames = append(moduleNames, k)
+				}
+			} else {
+				paes := []pkg.ProjectAvailableEnv{}
+				for _, pae := range project.ProjectAvailableEnvs {
+					for _, env := range envs {
+						if env == pae.EnvName {
+							paes = append(paes, pae)
+							break
+						}
+					}
+				}
+				for _, pae := range paes {
+					for _, def := range pae.DeployContainerDefs {
+						m[def.DeployName] = def.DeployName
+					}
+				}
+				for k, _ := range m {
+					moduleNames = append(moduleNames, k)
+				}
+			}
+		case "ops":
+			for _, def := range project.ProjectDef.CustomOpsDefs {
+				moduleNames = append(moduleNames, def.CustomOpsName)
+			}
+		case "step":
+			if step != "" {
+				if len(envs) == 0 {
+					for stepName, csd := range project.ProjectDef.CustomStepDefs {
+						if stepName == step {
+							for _, def := range csd.CustomStepModuleDefs {
+								moduleNames = append(moduleNames, def.ModuleName)
+							}
+							break
+						}
+					}
+				} else {
+					m := map[string]string{}
+					paes := []pkg.ProjectAvailableEnv{}
+					for _, pae := range project.ProjectAvailableEnvs {
+						for _, env := range envs {
+							if env == pae.EnvName {
+								paes = append(paes, pae)
+								break
+							}
+						}
+					}
+					for _, pae := range paes {
+						for stepName, csd := range pae.CustomStepDefs {
+							if stepName == step {
+								for _, def := range csd.CustomStepModuleDefs {
+									m[def.ModuleName] = def.ModuleName
+								}
+								break
+							}
+						}
+					}
+					for k, _ := range m {
+						moduleNames = append(moduleNames, k)
+					}
+				}
+			}
+		}
+		return moduleNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsDefEdit) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		err = fmt.Errorf("projectName required")
+		return err
+	}
+	if len(args) == 1 {
+		err = fmt.Errorf("kind required")
+		return err
+	}
+	var projectName string
+	var kind string
+	projectName = args[0]
+	kind = args[1]
+
+	defCmdKinds := []string{
+		"build",
+		"package",
+		"deploy",
+		"ops",
+		"step",
+		"pipeline",
+	}
+
+	var found bool
+	for _, cmdKind := range defCmdKinds {
+		if cmdKind == kind {
+			found = true
+			break
+		}
+	}
+	if !found {
+		err = fmt.Errorf("kind %s not correct, options: %s", kind, strings.Join(defCmdKinds, " / "))
+		return err
+	}
+	o.Param.Kind = kind
+
+	err = pkg.ValidateMinusNameID(projectName)
+	if err != nil {
+		err = fmt.Errorf("projectName %s format error: %s", projectName, err.Error())
+		return err
+	}
+	o.Param.ProjectName = projectName
+
+	o.Param.ModulePattern, err = pkg.ParseNamePattern(o.ModulePattern)
+	if err != nil {
+		err = fmt.Errorf("--module-pattern %s format error: %s", o.ModulePattern, err.Error())
+		return err
+	}
+	o.Param.Selector, err = pkg.ParseSelector(o.Selector)
+	if err != nil {
+		err = fmt.Errorf("--selector %s format error: %s", o.Selector, err.Error())
+		return err
+	}
+	if len(o.Param.Selector) > 0 && kind != "deploy" && kind != "step" && kind != "pipeline" {
+		err = fmt.Errorf("--selector only uses with kind deploy / step / pipeline")
+		return err
+	}
+
+	if kind != "pipeline" && len(o.ModuleNames) == 0 && o.ModulePattern == "" {
+		err = fmt.Errorf("--modules or --module-pattern required")
+		return err
+	}
+	if kind == "pipeline" && len(o.BranchNames) == 0 && len(o.Param.Selector) == 0 {
+		err = fmt.Errorf("kind is pipeline, --branches or --selector required")
+		return err
+	}
+	if kind == "deploy" && len(o.EnvNames) == 0 && len(o.Param.Selector) == 0 {
+		err = fmt.Errorf("kind is deploy, --envs or --selector required")
+		return err
+	}
+	if kind == "step" && o.StepName == "" {
+		err = fmt.Errorf("kind is step, --step required")
+		return err
+	}
+
+	for _, moduleName := range o.ModuleNames {
+		err = pkg.ValidateMinusNameID(moduleName)
+		if err != nil {
+			err = fmt.Errorf("moduleName %s format error: %s", moduleName, err.Error())
+			return err
+		}
+	}
+
+	return err
+}
+
+// SelectDefKinds return the project definitions to edit, items are filtered by module names and pattern
+func (o *OptionsDefEdit) SelectDefKinds(project pkg.ProjectOutput) []pkg.DefKind {
+	defs := []pkg.DefKind{}
+	defKindFiles := GetProjectDefKindFiles(project)
+	fileNames := []string{}
+	for fileName := range defKindFiles {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	inNames := func(name string, names []string) bool {
+		for _, s := range names {
+			if s == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, fileName := range fileNames {
+		def := defKindFiles[fileName]
+		if def.Kind != pkg.DefCmdKinds[o.Param.Kind] {
+			continue
+		}
+		envName := def.Metadata.Labels["envName"]
+		switch o.Param.Kind {
+		case "deploy":
+			if len(o.EnvNames) > 0 && !inNames(envName, o.EnvNames) {
+				continue
+			}
+		case "step":
+			if def.Metadata.Labels["stepName"] != o.StepName {
+				continue
+			}
+			if len(o.EnvNames) > 0 && !inNames(envName, o.EnvNames) {
+				continue
+			}
+			if len(o.EnvNames) == 0 && len(o.Param.Selector) == 0 && envName != "" {
+				continue
+			}
+		case "pipeline":
+			if len(o.BranchNames) > 0 && !inNames(def.Metadata.Labels["branchName"], o.BranchNames) {
+				continue
+			}
+		}
+
+		metadata := def.Metadata
+		if o.Param.Kind == "pipeline" {
+			for _, pp := range project.ProjectPipelines {
+				if pp.BranchName == def.Metadata.Labels["branchName"] {
+					metadata = PipelineDefMetadata(pp)
+					break
+				}
+			}
+		}
+		if !o.Param.Selector.Match(metadata) {
+			continue
+		}
+
+		if o.Param.Kind != "pipeline" {
+			items := []interface{}{}
+			for _, item := range def.Items {
+				moduleNames := DefKindModuleNames(pkg.DefKind{Kind: def.Kind, Items: []interface{}{item}})
+				if len(moduleNames) > 0 && MatchModuleName(moduleNames[0], o.ModuleNames, o.Param.ModulePattern) {
+					items = append(items, item)
+				}
+			}
+			if len(items) == 0 {
+				continue
+			}
+			def.Items = items
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+func (o *OptionsDefEdit) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	project, err := o.GetProjectDef(o.Param.ProjectName)
+	if err != nil {
+		return err
+	}
+
+	defs := o.SelectDefKinds(project)
+	if len(defs) == 0 {
+		err = fmt.Errorf("project %s no %s definitions selected to edit", o.Param.ProjectName, o.Param.Kind)
+		return err
+	}
+
+	docs := []string{}
+	for _, def := range defs {
+		m := map[string]interface{}{}
+		bs, _ = json.Marshal(def)
+		_ = json.Unmarshal(bs, &m)
+		bs, err = pkg.YamlIndent(pkg.RemoveMapEmptyItems(m))
+		if err != nil {
+			return err
+		}
+		docs = append(docs, string(bs))
+	}
+	bs = []byte(strings.Join(docs, "---\n"))
+
+	header := fmt.Sprintf(`# edit project %s %s definitions, comments at the top are ignored, an empty file cancels the edit.
+# only the changed modules are applied, modules removed here are not deleted.
+#
+`, o.Param.ProjectName, pkg.DefCmdKinds[o.Param.Kind])
+
+	var editedDefs []pkg.DefKind
+	edited, err := EditYaml(header, bs, func(fileName string, bs []byte) error {
+		var err error
+		editedDefs, err = GetDefKinds(fileName, bs)
+		if err != nil {
+			return err
+		}
+		for _, def := range editedDefs {
+			if def.Metadata.ProjectName != o.Param.ProjectName {
+				err = fmt.Errorf("metadata.projectName %s not correct, must be %s", def.Metadata.ProjectName, o.Param.ProjectName)
+				return err
+			}
+			if def.Kind != pkg.DefCmdKinds[o.Param.Kind] {
+				err = fmt.Errorf("kind %s not correct, must be %s", def.Kind, pkg.DefCmdKinds[o.Param.Kind])
+				return err
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if edited == nil {
+		return err
+	}
+
+	desiredProject, err := CopyProjectOutput(project)
+	if err != nil {
+		return err
+	}
+	desiredProject, err = MergeDefKinds(desiredProject, editedDefs)
+	if err != nil {
+		return err
+	}
+
+	defUpdates := []pkg.DefUpdate{}
+	updates := GetDefUpdates(desiredProject)
+	SortDefUpdates(updates)
+	for _, defUpdate := range updates {
+		current := GetProjectDefUpdate(project, defUpdate)
+		currentModules, currentFields, err := DefUpdateModules(current)
+		if err != nil {
+			return err
+		}
+		desiredModules, desiredFields, err := DefUpdateModules(defUpdate)
+		if err != nil {
+			return err
+		}
+		moduleNames := []string{}
+		for moduleName, s := range desiredModules {
+			if currentModules[moduleName] != s {
+				moduleNames = append(moduleNames, moduleName)
+			}
+		}
+		fieldsChanged := currentFields != desiredFields
+		if len(moduleNames) == 0 && !fieldsChanged {
+			log.Debug(fmt.Sprintf("%s not changed, skip", DefUpdatePath(defUpdate)))
+			continue
+		}
+		sort.Strings(moduleNames)
+		if defUpdate.Kind == "pipelineDef" {
+			log.Info(fmt.Sprintf("%s changed", DefUpdatePath(defUpdate)))
+		} else {
+			if fieldsChanged {
+				log.Info(fmt.Sprintf("%s changed", DefUpdatePath(defUpdate)))
+			}
+			for _, moduleName := range moduleNames {
+				log.Info(fmt.Sprintf("%s/%s changed", DefUpdatePath(defUpdate), moduleName))
+			}
+		}
+		defUpdates = append(defUpdates, defUpdate)
+	}
+
+	if len(defUpdates) == 0 {
+		log.Success("project definitions not changed, nothing to apply")
+		return err
+	}
+
+	err = o.ApplyDefUpdates(defUpdates)
+	if err != nil {
+		return err
+	}
+	log.Success(fmt.Sprintf("edit project definitions success, %d definitions applied", len(defUpdates)))
+
+	return err
+}
@@ -4,25 +4,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
-	"github.com/olekukonko/tablewriter"
+	"github.com/dory-engine/dory-ctl/pkg/printer"
 	"github.com/spf13/cobra"
-	"net/http"
 	"os"
 	"strings"
 )
 
 type OptionsDefGet struct {
 	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
-	ModuleNames    []string `yaml:"moduleNames" json:"moduleNames" bson:"moduleNames" validate:""`
-	EnvNames       []string `yaml:"envNames" json:"envNames" bson:"envNames" validate:""`
-	BranchNames    []string `yaml:"branchNames" json:"branchNames" bson:"branchNames" validate:""`
-	StepNames      []string `yaml:"stepNames" json:"stepNames" bson:"stepNames" validate:""`
-	Full           bool     `yaml:"full" json:"full" bson:"full" validate:""`
-	Output         string   `yaml:"output" json:"output" bson:"output" validate:""`
+	ModuleNames    []string        `yaml:"moduleNames" json:"moduleNames" bson:"moduleNames" validate:""`
+	EnvNames       []string        `yaml:"envNames" json:"envNames" bson:"envNames" validate:""`
+	BranchNames    []string        `yaml:"branchNames" json:"branchNames" bson:"branchNames" validate:""`
+	StepNames      []string        `yaml:"stepNames" json:"stepNames" bson:"stepNames" validate:""`
+	ModulePattern  string          `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+	Selector       string          `yaml:"selector" json:"selector" bson:"selector" validate:""`
+	Full           bool            `yaml:"full" json:"full" bson:"full" validate:""`
+	PrinterOptions printer.Options `yaml:"printerOptions" json:"printerOptions" bson:"printerOptions" validate:""`
 	Param          struct {
-		Kinds       []string `yaml:"kinds" json:"kinds" bson:"kinds" validate:""`
-		ProjectName string   `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
-		IsAllKind   bool     `yaml:"isAllKind" json:"isAllKind" bson:"isAllKind" validate:""`
+		Kinds         []string         `yaml:"kinds" json:"kinds" bson:"kinds" validate:""`
+		ProjectName   string           `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		IsAllKind     bool             `yaml:"isAllKind" json:"isAllKind" bson:"isAllKind" validate:""`
+		ModulePattern pkg.NamePattern  `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+		Selector      pkg.Selector     `yaml:"selector" json:"selector" bson:"selector" validate:""`
+		Printer       *printer.Printer `yaml:"-" json:"-" bson:"-" validate:""`
 	}
 }
 
@@ -40,10 +44,14 @@ func NewCmdDefGet() *cobra.Command {
 		defCmdKinds = append(defCmdKinds, k)
 	}
 
-	msgUse := fmt.Sprintf(`get [projectName] [kind],[kind]... [--output=json|yaml] [--modules=moduleName1,moduleName2] [--envs=envName1,envName2] [--branches=branchName1,branchName2] [--steps=stepName1,stepName2]
+	msgUse := fmt.Sprintf(`get [projectName] [kind],[kind]... [--output=json|yaml|wide|name|csv|jsonpath=...|go-template=...|custom-columns=...] [--modules=moduleName1,moduleName2] [--module-pattern=pattern] [--selector=selector] [--envs=envName1,envName2] [--branches=branchName1,branchName2] [--steps=stepName1,stepName2]
   # kind options: %s`, strings.Join(defCmdKinds, " / "))
 	msgShort := fmt.Sprintf("get project definitions")
-	msgLong := fmt.Sprintf(`get project definitions in dory-core server`)
+	msgLong := fmt.Sprintf(`get project definitions in dory-core server
+# --module-pattern filter modules by glob pattern, or by regular expression with %s prefix
+# --selector filter definitions by metadata labels or annotations, labels are envName, stepName, enableMode and branchName,
+# pipeline definitions annotations are envs, envProductions, isDefault, webhookPushEvent and tagSuffix, selector format:
+#   key=value, key!=value, key in (value1,value2), key notin (value1,value2), key (exists), !key (not exists)`, pkg.NamePatternRegexPrefix)
 	msgExample := fmt.Sprintf(`  # get project definitions summary
   doryctl def get test-project1
 
@@ -63,7 +71,22 @@ func NewCmdDefGet() *cobra.Command {
   doryctl def get test-project1 pipeline --branches=develop,release
 
   # get project custom step modules definitions, and filter by envNames and stepNames
-  doryctl def get test-project1 step --envs=test --steps=customStepName2`)
+  doryctl def get test-project1 step --envs=test --steps=customStepName2
+
+  # get project build and deploy definitions of all *-api modules
+  doryctl def get test-project1 build,deploy --module-pattern='*-api'
+
+  # get project deploy definitions in test and uat environments, filter modules by regular expression
+  doryctl def get test-project1 deploy -l 'envName in (test,uat)' --module-pattern='regex:tp1-(go|gin)-demo'
+
+  # get project pipeline definitions of the default branch
+  doryctl def get test-project1 pipeline -l isDefault=true
+
+  # get project deploy modules names, sorted by deployName
+  doryctl def get test-project1 deploy -o name --sort-by=.deployName
+
+  # get project build modules names and build environments
+  doryctl def get test-project1 build -o custom-columns=NAME:.buildName,ENV:.buildEnv`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -80,7 +103,9 @@ func NewCmdDefGet() *cobra.Command {
 	cmd.Flags().StringSliceVar(&o.EnvNames, "envs", []string{}, "filter project definitions by envNames")
 	cmd.Flags().StringSliceVar(&o.BranchNames, "branches", []string{}, "filter project pipeline definitions by branchNames")
 	cmd.Flags().StringSliceVar(&o.StepNames, "steps", []string{}, "filter project definitions by stepNames")
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+	cmd.Flags().StringVar(&o.ModulePattern, "module-pattern", "", "filter project definitions items by moduleName glob pattern, or regular expression with regex: prefix, example: *-api")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "filter project definitions by metadata labels or annotations selector, example: envName in (test,uat),stepName!=scanCode")
+	AddPrinterFlags(cmd, &o.PrinterOptions)
 	cmd.Flags().BoolVar(&o.Full, "full", false, "output project definitions in full version, use with --output option")
 
 	CheckError(o.Complete(cmd))
@@ -274,9 +299,7 @@ func (o *OptionsDefGet) Complete(cmd *cobra.Command) error {
 		return err
 	}
 
-	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
-	})
+	err = RegisterPrinterFlagCompletion(cmd)
 	if err != nil {
 		return err
 	}
@@ -351,11 +374,20 @@ func (o *OptionsDefGet) Validate(args []string) error {
 	}
 	o.Param.ProjectName = projectName
 
-	if o.Output != "" {
-		if o.Output != "yaml" && o.Output != "json" {
-			err = fmt.Errorf("--output must be yaml or json")
-			return err
-		}
+	o.Param.ModulePattern, err = pkg.ParseNamePattern(o.ModulePattern)
+	if err != nil {
+		err = fmt.Errorf("--module-pattern %s format error: %s", o.ModulePattern, err.Error())
+		return err
+	}
+	o.Param.Selector, err = pkg.ParseSelector(o.Selector)
+	if err != nil {
+		err = fmt.Errorf("--selector %s format error: %s", o.Selector, err.Error())
+		return err
+	}
+
+	o.Param.Printer, err = printer.NewPrinter(o.PrinterOptions)
+	if err != nil {
+		return err
 	}
 	return err
 }
@@ -366,13 +398,7 @@ func (o *OptionsDefGet) Run(args []string) error {
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s", o.Param.ProjectName), http.MethodGet, "", param, false)
-	if err != nil {
-		return err
-	}
-	project := pkg.ProjectOutput{}
-	err = json.Unmarshal([]byte(result.Get("data.project").Raw), &project)
+	project, err := o.GetProjectDef(o.Param.ProjectName)
 	if err != nil {
 		return err
 	}
@@ -419,17 +445,7 @@ func (o *OptionsDefGet) Run(args []string) error {
 			defKind := defKindProject
 			defKind.Kind = "buildDefs"
 			for _, def := range project.ProjectDef.BuildDefs {
-				var isShow bool
-				if len(o.ModuleNames) == 0 {
-					isShow = true
-				} else {
-					for _, moduleName := range o.ModuleNames {
-						if moduleName == def.BuildName {
-							isShow = true
-							break
-						}
-					}
-				}
+				isShow := MatchModuleName(def.BuildName, o.ModuleNames, o.Param.ModulePattern)
 				if isShow {
 					defKind.Items = append(defKind.Items, def)
 				}
@@ -442,17 +458,7 @@ func (o *OptionsDefGet) Run(args []string) error {
 			defKind.Kind = "packageDefs"
 			defKind.Status.ErrMsg = project.ProjectDef.ErrMsgPackageDefs
 			for _, def := range project.ProjectDef.PackageDefs {
-				var isShow bool
-				if len(o.ModuleNames) == 0 {
-					isShow = true
-				} else {
-					for _, moduleName := range o.ModuleNames {
-						if moduleName == def.PackageName {
-							isShow = true
-							break
-						}
-					}
-				}
+				isShow := MatchModuleName(def.PackageName, o.ModuleNames, o.Param.ModulePattern)
 				if isShow {
 					defKind.Items = append(defKind.Items, def)
 				}
@@ -483,17 +489,7 @@ func (o *OptionsDefGet) Run(args []string) error {
 						"envName": pae.EnvName,
 					}
 					for _, def := range pae.DeployContainerDefs {
-						var isShow bool
-						if len(o.ModuleNames) == 0 {
-							isShow = true
-						} else {
-							for _, moduleName := range o.ModuleNames {
-								if moduleName == def.DeployName {
-									isShow = true
-									break
-								}
-							}
-						}
+						isShow := MatchModuleName(def.DeployName, o.ModuleNames, o.Param.ModulePattern)
 						if isShow {
 							defKind.Items = append(defKind.Items, def)
 						}
@@ -533,17 +529,7 @@ func (o *OptionsDefGet) Run(args []string) error {
 							"enableMode": csd.EnableMode,
 						}
 						for _, csmd := range csd.CustomStepModuleDefs {
-							var isShow bool
-							if len(o.ModuleNames) == 0 {
-								isShow = true
-							} else {
-								for _, moduleName := range o.ModuleNames {
-									if moduleName == csmd.ModuleName {
-										isShow = true
-										break
-									}
-								}
-							}
+							isShow := MatchModuleName(csmd.ModuleName, o.ModuleNames, o.Param.ModulePattern)
 							if isShow {
 								defKind.Items = append(defKind.Items, csmd)
 							}
@@ -583,17 +569,7 @@ func (o *OptionsDefGet) Run(args []string) error {
 					"enableMode": csd.EnableMode,
 				}
 				for _, csmd := range csd.CustomStepModuleDefs {
-					var isShow bool
-					if len(o.ModuleNames) == 0 {
-						isShow = true
-					} else {
-						for _, moduleName := range o.ModuleNames {
-							if moduleName == csmd.ModuleName {
-								isShow = true
-								break
-							}
-						}
-					}
+					isShow := MatchModuleName(csmd.ModuleName, o.ModuleNames, o.Param.ModulePattern)
 					if isShow {
 						defKind.Items = append(defKind.Items, csmd)
 					}
@@ -620,16 +596,9 @@ func (o *OptionsDefGet) Run(args []string) error {
 				defKind := defKindProject
 				defKind.Kind = "pipelineDef"
 				defKind.Status.ErrMsg = pp.ErrMsgPipelineDef
-				defKind.Metadata.Labels = map[string]string{
-					"branchName": pp.BranchName,
-				}
-				defKind.Metadata.Annotations = map[string]string{
-					"envs":             strings.Join(pp.Envs, ","),
-					"envProductions":   strings.Join(pp.EnvProductions, ","),
-					"isDefault":        fmt.Sprintf("%v", pp.IsDefault),
-					"webhookPushEvent": fmt.Sprintf("%v", pp.WebhookPushEvent),
-					"tagSuffix":        pp.TagSuffix,
-				}
+				metadata := PipelineDefMetadata(pp)
+				defKind.Metadata.Labels = metadata.Labels
+				defKind.Metadata.Annotations = metadata.Annotations
 				defKind.Items = append(defKind.Items, pp.PipelineDef)
 				defKinds = append(defKinds, defKind)
 			}
@@ -649,17 +618,7 @@ func (o *OptionsDefGet) Run(args []string) error {
 			defKind.Kind = "customOpsDefs"
 			defKind.Status.ErrMsg = project.ProjectDef.ErrMsgCustomOpsDefs
 			for _, def := range project.ProjectDef.CustomOpsDefs {
-				var isShow bool
-				if len(o.ModuleNames) == 0 {
-					isShow = true
-				} else {
-					for _, moduleName := range o.ModuleNames {
-						if moduleName == def.CustomOpsName {
-							isShow = true
-							break
-						}
-					}
-				}
+				isShow := MatchModuleName(def.CustomOpsName, o.ModuleNames, o.Param.ModulePattern)
 				if isShow {
 					defKind.Items = append(defKind.Items, def)
 				}
@@ -669,10 +628,17 @@ func (o *OptionsDefGet) Run(args []string) error {
 	}
 
 	defKindFilters := []pkg.DefKind{}
-	if len(o.Param.Kinds) == 0 || o.Param.IsAllKind {
+	if len(o.Param.Kinds) == 0 {
 		defKindFilters = defKinds
 	} else {
 		for _, defKind := range defKinds {
+			if !o.Param.Selector.Match(defKind.Metadata) {
+				continue
+			}
+			if o.Param.IsAllKind {
+				defKindFilters = append(defKindFilters, defKind)
+				continue
+			}
 			for _, kind := range o.Param.Kinds {
 				if kind == defKind.Kind {
 					defKindFilters = append(defKindFilters, defKind)
@@ -717,6 +683,14 @@ func (o *OptionsDefGet) Run(args []string) error {
 		errMsgs = append(errMsgs, errMsg)
 	}
 
+	p := o.Param.Printer
+	for _, defKind := range defKindFilters {
+		err = p.Sort(defKind.Items)
+		if err != nil {
+			return err
+		}
+	}
+
 	defKindList := pkg.DefKindList{
 		Kind: "list",
 		Defs: defKindFilters,
@@ -733,149 +707,135 @@ func (o *OptionsDefGet) Run(args []string) error {
 		dataOutput = pkg.RemoveMapEmptyItems(m)
 	}
 
-	switch o.Output {
-	case "json":
-		bs, _ = json.MarshalIndent(dataOutput, "", "  ")
-		fmt.Println(string(bs))
-	case "yaml":
-		bs, _ = pkg.YamlIndent(dataOutput)
-		fmt.Println(string(bs))
-	default:
-		for _, defKind := range defKindList.Defs {
-			if defKind.Status.ErrMsg != "" {
-				log.Error(defKind.Status.ErrMsg)
-			}
-
-			dataHeader := []string{}
-			dataRows := [][]string{}
-			bs, _ := json.Marshal(defKind.Items)
-			switch defKind.Kind {
-			case "projectSummary":
-				items := []pkg.ProjectSummary{}
-				_ = json.Unmarshal(bs, &items)
-				for _, item := range items {
-					var customSteps []string
-					for _, conf := range item.CustomStepConfs {
-						var isEnvDiff string
-						if conf.IsEnvDiff {
-							isEnvDiff = "[env]"
-						}
-						s := fmt.Sprintf("%s%s", conf.CustomStepName, isEnvDiff)
-						customSteps = append(customSteps, s)
+	tables := []printer.Table{}
+	for _, defKind := range defKindList.Defs {
+		dataHeader := []string{}
+		rows := []printer.Row{}
+		bs, _ := json.Marshal(defKind.Items)
+		switch defKind.Kind {
+		case "projectSummary":
+			items := []pkg.ProjectSummary{}
+			_ = json.Unmarshal(bs, &items)
+			for _, item := range items {
+				var customSteps []string
+				for _, conf := range item.CustomStepConfs {
+					var isEnvDiff string
+					if conf.IsEnvDiff {
+						isEnvDiff = "[env]"
 					}
-					var nodePorts []string
-					for _, port := range item.NodePorts {
-						s := fmt.Sprintf("%d", port)
-						nodePorts = append(nodePorts, s)
-					}
-					dataRow := []string{defKind.Kind, strings.Join(item.BuildNames, "\n"), strings.Join(item.PackageNames, "\n"), strings.Join(customSteps, "\n"), strings.Join(item.BranchNames, "\n"), strings.Join(item.EnvNames, "\n"), strings.Join(nodePorts, "\n")}
-					dataRows = append(dataRows, dataRow)
-				}
-				dataHeader = []string{"kind", "Builds", "Packages", "CustomSteps", "Branches", "Envs", "NodePorts"}
-			case "buildDefs":
-				items := []pkg.BuildDef{}
-				_ = json.Unmarshal(bs, &items)
-				for _, item := range items {
-					dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.BuildName), item.BuildEnv, item.BuildPath, fmt.Sprintf("%d", item.BuildPhaseID), strings.Join(item.BuildCmds, "\n")}
-					dataRows = append(dataRows, dataRow)
+					s := fmt.Sprintf("%s%s", conf.CustomStepName, isEnvDiff)
+					customSteps = append(customSteps, s)
 				}
-				dataHeader = []string{"Name", "Env", "Path", "PhaseID", "Cmds"}
-			case "packageDefs":
-				items := []pkg.PackageDef{}
-				_ = json.Unmarshal(bs, &items)
-				for _, item := range items {
-					dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.PackageName), strings.Join(item.RelatedBuilds, "\n"), item.PackageFrom, strings.Join(item.Packages, "\n")}
-					dataRows = append(dataRows, dataRow)
+				var nodePorts []string
+				for _, port := range item.NodePorts {
+					s := fmt.Sprintf("%d", port)
+					nodePorts = append(nodePorts, s)
 				}
-				dataHeader = []string{"Name", "Builds", "From", "Dockerfile"}
-			case "deployContainerDefs":
-				items := []pkg.DeployContainerDef{}
-				_ = json.Unmarshal(bs, &items)
-				for _, item := range items {
-					var ports []string
-					for _, p := range item.DeployLocalPorts {
-						if p.Protocol == "" {
-							p.Protocol = "TCP"
-						}
-						ports = append(ports, fmt.Sprintf("%d/%s", p.Port, p.Protocol))
-					}
-					for _, p := range item.DeployNodePorts {
-						if p.Protocol == "" {
-							p.Protocol = "TCP"
-						}
-						ports = append(ports, fmt.Sprintf("%d:%d/%s", p.Port, p.NodePort, p.Protocol))
-					}
-
-					dependServices := []string{}
-					for _, ds := range item.DependServices {
-						dependServices = append(dependServices, fmt.Sprintf("%s:%d", ds.DependName, ds.DependPort))
-					}
-					dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.DeployName), defKind.Metadata.Labels["envName"], item.RelatedPackage, fmt.Sprintf("%d", item.DeployReplicas), strings.Join(ports, ","), strings.Join(dependServices, "\n")}
-					dataRows = append(dataRows, dataRow)
-				}
-				dataHeader = []string{"Name", "Env", "Package", "Replicas", "Ports", "Depends"}
-			case "customStepDef":
-				items := []pkg.CustomStepModuleDef{}
-				_ = json.Unmarshal(bs, &items)
-				var envName string
-				for k, v := range defKind.Metadata.Labels {
-					if k == "envName" {
-						envName = v
+				dataRow := []string{defKind.Kind, strings.Join(item.BuildNames, "\n"), strings.Join(item.PackageNames, "\n"), strings.Join(customSteps, "\n"), strings.Join(item.BranchNames, "\n"), strings.Join(item.EnvNames, "\n"), strings.Join(nodePorts, "\n")}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"kind", "Builds", "Packages", "CustomSteps", "Branches", "Envs", "NodePorts"}
+		case "buildDefs":
+			items := []pkg.BuildDef{}
+			_ = json.Unmarshal(bs, &items)
+			for _, item := range items {
+				dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.BuildName), item.BuildEnv, item.BuildPath, fmt.Sprintf("%d", item.BuildPhaseID), strings.Join(item.BuildCmds, "\n")}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"Name", "Env", "Path", "PhaseID", "Cmds"}
+		case "packageDefs":
+			items := []pkg.PackageDef{}
+			_ = json.Unmarshal(bs, &items)
+			for _, item := range items {
+				dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.PackageName), strings.Join(item.RelatedBuilds, "\n"), item.PackageFrom, strings.Join(item.Packages, "\n")}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"Name", "Builds", "From", "Dockerfile"}
+		case "deployContainerDefs":
+			items := []pkg.DeployContainerDef{}
+			_ = json.Unmarshal(bs, &items)
+			for _, item := range items {
+				var ports []string
+				for _, p := range item.DeployLocalPorts {
+					if p.Protocol == "" {
+						p.Protocol = "TCP"
 					}
+					ports = append(ports, fmt.Sprintf("%d/%s", p.Port, p.Protocol))
 				}
-				for _, item := range items {
-					dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.ModuleName), defKind.Metadata.Labels["stepName"], envName, defKind.Metadata.Labels["enableMode"], strings.Join(item.RelatedStepModules, "\n"), fmt.Sprintf("%v", item.ManualEnable), item.ParamInputYaml}
-					dataRows = append(dataRows, dataRow)
-				}
-				dataHeader = []string{"Name", "StepName", "Env", "EnableMode", "RelateModules", "ManualEnable", "Params"}
-			case "pipelineDef":
-				items := []pkg.PipelineDef{}
-				_ = json.Unmarshal(bs, &items)
-				for _, item := range items {
-					var builds []string
-					for _, build := range item.Builds {
-						buildStr := fmt.Sprintf("%s: %v", build.Name, build.Run)
-						builds = append(builds, buildStr)
+				for _, p := range item.DeployNodePorts {
+					if p.Protocol == "" {
+						p.Protocol = "TCP"
 					}
-					envs := strings.Split(defKind.Metadata.Annotations["envs"], ",")
-					envProductions := strings.Split(defKind.Metadata.Annotations["envProductions"], ",")
-					dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, defKind.Metadata.Labels["branchName"]), strings.Join(envs, "\n"), strings.Join(envProductions, "\n"), fmt.Sprintf("%v", item.IsAutoDetectBuild), fmt.Sprintf("%v", item.IsQueue), strings.Join(builds, "\n")}
-					dataRows = append(dataRows, dataRow)
+					ports = append(ports, fmt.Sprintf("%d:%d/%s", p.Port, p.NodePort, p.Protocol))
 				}
-				dataHeader = []string{"Name", "Envs", "EnvProds", "AutoDetect", "Queue", "Builds"}
-			case "dockerIgnoreDefs":
-				items := []string{}
-				_ = json.Unmarshal(bs, &items)
-				for _, item := range items {
-					dataRow := []string{defKind.Kind, item}
-					dataRows = append(dataRows, dataRow)
+
+				dependServices := []string{}
+				for _, ds := range item.DependServices {
+					dependServices = append(dependServices, fmt.Sprintf("%s:%d", ds.DependName, ds.DependPort))
 				}
-				dataHeader = []string{"Name", "Value"}
-			case "customOpsDefs":
-				items := []pkg.CustomOpsDef{}
-				_ = json.Unmarshal(bs, &items)
-				for _, item := range items {
-					dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.CustomOpsName), item.CustomOpsDesc, strings.Join(item.CustomOpsSteps, "\n")}
-					dataRows = append(dataRows, dataRow)
+				dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.DeployName), defKind.Metadata.Labels["envName"], item.RelatedPackage, fmt.Sprintf("%d", item.DeployReplicas), strings.Join(ports, ","), strings.Join(dependServices, "\n")}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"Name", "Env", "Package", "Replicas", "Ports", "Depends"}
+		case "customStepDef":
+			items := []pkg.CustomStepModuleDef{}
+			_ = json.Unmarshal(bs, &items)
+			var envName string
+			for k, v := range defKind.Metadata.Labels {
+				if k == "envName" {
+					envName = v
 				}
-				dataHeader = []string{"Name", "Desc", "Steps"}
-			}
-
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader(dataHeader)
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(dataRows)
-			table.Render()
+			}
+			for _, item := range items {
+				dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.ModuleName), defKind.Metadata.Labels["stepName"], envName, defKind.Metadata.Labels["enableMode"], strings.Join(item.RelatedStepModules, "\n"), fmt.Sprintf("%v", item.ManualEnable), item.ParamInputYaml}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"Name", "StepName", "Env", "EnableMode", "RelateModules", "ManualEnable", "Params"}
+		case "pipelineDef":
+			items := []pkg.PipelineDef{}
+			_ = json.Unmarshal(bs, &items)
+			for _, item := range items {
+				var builds []string
+				for _, build := range item.Builds {
+					buildStr := fmt.Sprintf("%s: %v", build.Name, build.Run)
+					builds = append(builds, buildStr)
+				}
+				envs := strings.Split(defKind.Metadata.Annotations["envs"], ",")
+				envProductions := strings.Split(defKind.Metadata.Annotations["envProductions"], ",")
+				dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, defKind.Metadata.Labels["branchName"]), strings.Join(envs, "\n"), strings.Join(envProductions, "\n"), fmt.Sprintf("%v", item.IsAutoDetectBuild), fmt.Sprintf("%v", item.IsQueue), strings.Join(builds, "\n")}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"Name", "Envs", "EnvProds", "AutoDetect", "Queue", "Builds"}
+		case "dockerIgnoreDefs":
+			items := []string{}
+			_ = json.Unmarshal(bs, &items)
+			for _, item := range items {
+				dataRow := []string{defKind.Kind, item}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"Name", "Value"}
+		case "customOpsDefs":
+			items := []pkg.CustomOpsDef{}
+			_ = json.Unmarshal(bs, &items)
+			for _, item := range items {
+				dataRow := []string{fmt.Sprintf("%s/%s", defKind.Kind, item.CustomOpsName), item.CustomOpsDesc, strings.Join(item.CustomOpsSteps, "\n")}
+				rows = append(rows, printer.Row{Name: dataRow[0], Cells: dataRow, Object: item})
+			}
+			dataHeader = []string{"Name", "Desc", "Steps"}
+		}
+
+		tables = append(tables, printer.Table{Headers: dataHeader, Rows: rows})
+	}
+
+	if p.IsTable() {
+		for i, defKind := range defKindList.Defs {
+			if defKind.Status.ErrMsg != "" {
+				log.Error(defKind.Status.ErrMsg)
+			}
+			err = p.PrintTable(os.Stdout, tables[i], true)
+			if err != nil {
+				return err
+			}
 			fmt.Println("------------")
 			fmt.Println()
 		}
@@ -887,7 +847,28 @@ func (o *OptionsDefGet) Run(args []string) error {
 			}
 			fmt.Println()
 		}
+	} else {
+		err = p.Print(os.Stdout, dataOutput, tables)
+		if err != nil {
+			return err
+		}
 	}
 
 	return err
 }
+
+// PipelineDefMetadata return the metadata labels and annotations of the project pipeline definition
+func PipelineDefMetadata(pp pkg.ProjectPipeline) pkg.DefMetadata {
+	return pkg.DefMetadata{
+		Labels: map[string]string{
+			"branchName": pp.BranchName,
+		},
+		Annotations: map[string]string{
+			"envs":             strings.Join(pp.Envs, ","),
+			"envProductions":   strings.Join(pp.EnvProductions, ","),
+			"isDefault":        fmt.Sprintf("%v", pp.IsDefault),
+			"webhookPushEvent": fmt.Sprintf("%v", pp.WebhookPushEvent),
+			"tagSuffix":        pp.TagSuffix,
+		},
+	}
+}
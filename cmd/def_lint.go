@@ -0,0 +1,642 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type OptionsDefLint struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	FileNames      []string `yaml:"fileNames" json:"fileNames" bson:"fileNames" validate:""`
+	Recursive      bool     `yaml:"recursive" json:"recursive" bson:"recursive" validate:""`
+	Output         string   `yaml:"output" json:"output" bson:"output" validate:""`
+	Param          struct {
+		FileNames []string `yaml:"fileNames" json:"fileNames" bson:"fileNames" validate:""`
+		IsStdin   bool     `yaml:"isStdin" json:"isStdin" bson:"isStdin" validate:""`
+	}
+}
+
+func NewOptionsDefLint() *OptionsDefLint {
+	var o OptionsDefLint
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdDefLint() *cobra.Command {
+	o := NewOptionsDefLint()
+
+	msgUse := fmt.Sprintf(`lint -f [filename] [-r] [--output=json|yaml|github]`)
+	msgShort := fmt.Sprintf("check project definitions files offline")
+	msgLong := fmt.Sprintf(`check project definitions files without dory-core server, report all problems with file name, line and column.
+# checks: yaml / json syntax, kind and metadata, validate rules of definitions fields, modules names format, duplicate modules,
+# ports must be between 1 and 65535, duplicate ports and nodePorts, kubernetes resource quantities format of deployResources and hpaConfig,
+# packageDefs relatedBuilds, deployContainerDefs relatedPackage and pipelineDef builds must refer to modules defined in the files,
+# references are checked only when the project buildDefs or packageDefs are in the files.
+# exit code is %d if problems found, --output=github prints GitHub Actions annotations.`, pkg.ExitCodeFail)
+	msgExample := fmt.Sprintf(`  # check project definitions files in directory recursively
+  doryctl def lint -f defs/ -r
+
+  # check project definitions files, output problems in json format
+  doryctl def lint -f defs/ -r -o json
+
+  # check project definitions files in GitHub Actions workflow, problems are shown as annotations
+  doryctl def lint -f defs/ -r -o github
+
+  # check project definitions from stdin
+  cat defs.yaml | doryctl def lint -f -`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false, "process the directory used in -f, --files recursively")
+	cmd.Flags().StringSliceVarP(&o.FileNames, "files", "f", []string{}, "project definitions file name or directory, support *.json and *.yaml and *.yml files")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: json / yaml / github)")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsDefLint) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.MarkFlagRequired("files")
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml", "github"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsDefLint) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" && o.Output != "github" {
+			err = fmt.Errorf("--output must be yaml, json or github")
+			return err
+		}
+	}
+
+	for _, fileName := range o.FileNames {
+		if strings.TrimSpace(fileName) == "-" {
+			o.Param.IsStdin = true
+		}
+	}
+	if o.Param.IsStdin {
+		if len(o.FileNames) > 1 {
+			err = fmt.Errorf(`"--files -" found, can not use multiple --files options`)
+			return err
+		}
+		return err
+	}
+
+	o.Param.FileNames, err = ListDefFileNames(o.FileNames, o.Recursive)
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+// defLintModule is a module definition checked by DefLinter, pipelineDef name is the branchName
+type defLintModule struct {
+	fileName    string
+	node        *yaml.Node
+	prefix      string
+	kind        string
+	projectName string
+	envName     string
+	stepName    string
+	name        string
+	item        interface{}
+}
+
+// DefLinter check project definitions files and collect all the problems
+type DefLinter struct {
+	Issues  []pkg.LintIssue
+	modules []defLintModule
+}
+
+func NewDefLinter() *DefLinter {
+	return &DefLinter{Issues: []pkg.LintIssue{}}
+}
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+): (.*)`)
+
+func (l *DefLinter) addIssue(fileName string, node *yaml.Node, prefix, path, rule, msg string) {
+	line, column := pkg.YamlNodePosition(node, path)
+	fullPath := path
+	if prefix != "" && path != "" {
+		fullPath = fmt.Sprintf("%s.%s", prefix, path)
+	} else if prefix != "" {
+		fullPath = prefix
+	}
+	l.Issues = append(l.Issues, pkg.LintIssue{
+		FileName: fileName,
+		Line:     line,
+		Column:   column,
+		Rule:     rule,
+		Path:     fullPath,
+		Message:  msg,
+	})
+}
+
+// addDecodeIssues add the yaml decode errors, the line is parsed from the error message
+func (l *DefLinter) addDecodeIssues(fileName string, node *yaml.Node, prefix, header string, err error) {
+	msgs := []string{err.Error()}
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		msgs = typeErr.Errors
+	}
+	for _, msg := range msgs {
+		issue := pkg.LintIssue{
+			FileName: fileName,
+			Rule:     pkg.LintRuleParse,
+			Path:     prefix,
+		}
+		if node != nil {
+			issue.Line, issue.Column = node.Line, node.Column
+		}
+		msg = strings.TrimPrefix(msg, "yaml: ")
+		arr := yamlErrorLineRegexp.FindStringSubmatch(msg)
+		if len(arr) == 3 {
+			issue.Line, _ = strconv.Atoi(arr[1])
+			issue.Column = 0
+			msg = arr[2]
+		}
+		issue.Message = fmt.Sprintf("%s%s", header, msg)
+		l.Issues = append(l.Issues, issue)
+	}
+}
+
+// LintFile check all definitions in the yaml or json file, a file can include multiple yaml documents or a list kind
+func (l *DefLinter) LintFile(fileName string, bs []byte) {
+	dec := yaml.NewDecoder(bytes.NewReader(bs))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			l.addDecodeIssues(fileName, nil, "", "", err)
+			break
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		kindNode := pkg.YamlMappingValue(root, "kind")
+		if kindNode != nil && kindNode.Value == "list" {
+			defsNode := pkg.YamlMappingValue(root, "defs")
+			if defsNode == nil || defsNode.Kind != yaml.SequenceNode {
+				l.addIssue(fileName, root, "", "defs", pkg.LintRuleMetadata, "kind is list, but defs is not an array")
+				continue
+			}
+			for i, node := range defsNode.Content {
+				l.lintDef(fileName, node, fmt.Sprintf("defs[%d]", i))
+			}
+		} else {
+			l.lintDef(fileName, root, "")
+		}
+	}
+}
+
+func (l *DefLinter) lintDef(fileName string, node *yaml.Node, prefix string) {
+	if node.Kind != yaml.MappingNode {
+		l.addIssue(fileName, node, prefix, "", pkg.LintRuleParse, "definition must be an object")
+		return
+	}
+	var def pkg.DefKind
+	err := node.Decode(&def)
+	if err != nil {
+		l.addDecodeIssues(fileName, node, prefix, "", err)
+		return
+	}
+
+	if def.Kind == "" {
+		l.addIssue(fileName, node, prefix, "kind", pkg.LintRuleMetadata, "kind is empty")
+		return
+	}
+	kinds := []string{}
+	for _, v := range pkg.DefCmdKinds {
+		if v != "" {
+			kinds = append(kinds, v)
+		}
+	}
+	sort.Strings(kinds)
+	var found bool
+	for _, kind := range kinds {
+		if kind == def.Kind {
+			found = true
+			break
+		}
+	}
+	if !found {
+		l.addIssue(fileName, node, prefix, "kind", pkg.LintRuleMetadata, fmt.Sprintf("kind %s not correct, options: %s", def.Kind, strings.Join(kinds, " / ")))
+		return
+	}
+
+	if def.Metadata.ProjectName == "" {
+		l.addIssue(fileName, node, prefix, "metadata.projectName", pkg.LintRuleMetadata, fmt.Sprintf("kind is %s, but metadata.projectName is empty", def.Kind))
+	} else {
+		err = pkg.ValidateMinusNameID(def.Metadata.ProjectName)
+		if err != nil {
+			l.addIssue(fileName, node, prefix, "metadata.projectName", pkg.LintRuleName, fmt.Sprintf("metadata.projectName %s format error: %s", def.Metadata.ProjectName, err.Error()))
+		}
+	}
+	labels := map[string]string{
+		"deployContainerDefs": "envName",
+		"customStepDef":       "stepName",
+		"pipelineDef":         "branchName",
+	}
+	if label, ok := labels[def.Kind]; ok && def.Metadata.Labels[label] == "" {
+		l.addIssue(fileName, node, prefix, fmt.Sprintf("metadata.labels.%s", label), pkg.LintRuleMetadata, fmt.Sprintf("kind is %s, but metadata.labels.%s is empty", def.Kind, label))
+	}
+
+	itemsNode := pkg.YamlMappingValue(node, "items")
+	if itemsNode != nil && itemsNode.Kind != yaml.SequenceNode && itemsNode.Tag != "!!null" {
+		l.addIssue(fileName, node, prefix, "items", pkg.LintRuleMetadata, fmt.Sprintf("kind is %s, but items is not an array", def.Kind))
+		return
+	}
+	if def.Kind == "pipelineDef" && len(def.Items) != 1 {
+		l.addIssue(fileName, node, prefix, "items", pkg.LintRuleMetadata, "kind is pipelineDef, but items size is not 1")
+	}
+	if itemsNode == nil || itemsNode.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, itemNode := range itemsNode.Content {
+		itemPrefix := fmt.Sprintf("items[%d]", i)
+		if prefix != "" {
+			itemPrefix = fmt.Sprintf("%s.%s", prefix, itemPrefix)
+		}
+		l.lintItem(fileName, def, itemNode, itemPrefix)
+	}
+}
+
+func (l *DefLinter) lintItem(fileName string, def pkg.DefKind, node *yaml.Node, prefix string) {
+	var err error
+	module := defLintModule{
+		fileName:    fileName,
+		node:        node,
+		prefix:      prefix,
+		kind:        def.Kind,
+		projectName: def.Metadata.ProjectName,
+		envName:     def.Metadata.Labels["envName"],
+		stepName:    def.Metadata.Labels["stepName"],
+	}
+	header := fmt.Sprintf("%s %s: ", def.Kind, prefix)
+
+	if def.Kind == "dockerIgnoreDefs" {
+		if node.Kind != yaml.ScalarNode {
+			l.addIssue(fileName, node, prefix, "", pkg.LintRuleParse, fmt.Sprintf("%sitems must be string array", header))
+		}
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		l.addIssue(fileName, node, prefix, "", pkg.LintRuleParse, fmt.Sprintf("%sitem must be an object", header))
+		return
+	}
+
+	var nameField string
+	switch def.Kind {
+	case "buildDefs":
+		var d pkg.BuildDef
+		err = node.Decode(&d)
+		module.name, module.item, nameField = d.BuildName, d, "buildName"
+	case "packageDefs":
+		var d pkg.PackageDef
+		err = node.Decode(&d)
+		module.name, module.item, nameField = d.PackageName, d, "packageName"
+	case "deployContainerDefs":
+		var d pkg.DeployContainerDef
+		err = node.Decode(&d)
+		module.name, module.item, nameField = d.DeployName, d, "deployName"
+	case "customOpsDefs":
+		var d pkg.CustomOpsDef
+		err = node.Decode(&d)
+		module.name, module.item, nameField = d.CustomOpsName, d, "customOpsName"
+	case "customStepDef":
+		var d pkg.CustomStepModuleDef
+		err = node.Decode(&d)
+		module.name, module.item, nameField = d.ModuleName, d, "moduleName"
+	case "pipelineDef":
+		var d pkg.PipelineDef
+		err = node.Decode(&d)
+		module.name, module.item = def.Metadata.Labels["branchName"], d
+	}
+	if module.name != "" {
+		header = fmt.Sprintf("%s/%s: ", def.Kind, module.name)
+	}
+	if err != nil {
+		l.addDecodeIssues(fileName, node, prefix, header, err)
+	}
+
+	for _, issue := range pkg.ValidateStruct(module.item) {
+		l.addIssue(fileName, node, prefix, issue.Path, issue.Rule, fmt.Sprintf("%s%s", header, issue.Message))
+	}
+
+	checkName := func(path, name string) {
+		if name == "" {
+			return
+		}
+		err := pkg.ValidateMinusNameID(name)
+		if err != nil {
+			l.addIssue(fileName, node, prefix, path, pkg.LintRuleName, fmt.Sprintf("%s%s %s format error: %s", header, path, name, err.Error()))
+		}
+	}
+	if nameField != "" {
+		checkName(nameField, module.name)
+	}
+
+	switch d := module.item.(type) {
+	case pkg.PackageDef:
+		for i, s := range d.RelatedBuilds {
+			checkName(fmt.Sprintf("relatedBuilds[%d]", i), s)
+		}
+	case pkg.DeployContainerDef:
+		checkName("relatedPackage", d.RelatedPackage)
+		l.lintDeployContainerDef(fileName, node, prefix, header, d)
+	case pkg.CustomStepModuleDef:
+		if d.ParamInputYaml != "" {
+			var m map[string]interface{}
+			err = yaml.Unmarshal([]byte(d.ParamInputYaml), &m)
+			if err != nil {
+				l.addIssue(fileName, node, prefix, "paramInputYaml", pkg.LintRuleParse, fmt.Sprintf("%sparamInputYaml parse error: %s", header, strings.TrimPrefix(err.Error(), "yaml: ")))
+			}
+		}
+	case pkg.PipelineDef:
+		for i, build := range d.Builds {
+			checkName(fmt.Sprintf("builds[%d].name", i), build.Name)
+		}
+	}
+
+	if module.name != "" {
+		l.modules = append(l.modules, module)
+	}
+}
+
+func (l *DefLinter) lintDeployContainerDef(fileName string, node *yaml.Node, prefix, header string, d pkg.DeployContainerDef) {
+	type portField struct {
+		path string
+		port int
+	}
+	ports := []portField{}
+	containerPorts := []portField{}
+	for i, np := range d.DeployNodePorts {
+		containerPorts = append(containerPorts, portField{path: fmt.Sprintf("deployNodePorts[%d].port", i), port: np.Port})
+		ports = append(ports, portField{path: fmt.Sprintf("deployNodePorts[%d].nodePort", i), port: np.NodePort})
+	}
+	for i, lp := range d.DeployLocalPorts {
+		containerPorts = append(containerPorts, portField{path: fmt.Sprintf("deployLocalPorts[%d].port", i), port: lp.Port})
+	}
+	for i, ds := range d.DependServices {
+		ports = append(ports, portField{path: fmt.Sprintf("dependServices[%d].dependPort", i), port: ds.DependPort})
+	}
+	ports = append(ports, portField{path: "deployHealthCheck.checkPort", port: d.DeployHealthCheck.CheckPort})
+	ports = append(ports, portField{path: "deployHealthCheck.httpGet.port", port: d.DeployHealthCheck.HttpGet.Port})
+
+	// port 0 is empty, required ports are checked by validate rules
+	definedPorts := map[int]string{}
+	for _, pf := range containerPorts {
+		if pf.port == 0 {
+			continue
+		}
+		err := pkg.ValidatePort(pf.port)
+		if err != nil {
+			l.addIssue(fileName, node, prefix, pf.path, pkg.LintRulePort, fmt.Sprintf("%s%s: %s", header, pf.path, err.Error()))
+			continue
+		}
+		if path, ok := definedPorts[pf.port]; ok {
+			l.addIssue(fileName, node, prefix, pf.path, pkg.LintRulePort, fmt.Sprintf("%s%s: port %d duplicated with %s", header, pf.path, pf.port, path))
+			continue
+		}
+		definedPorts[pf.port] = pf.path
+	}
+	for _, pf := range ports {
+		if pf.port == 0 {
+			continue
+		}
+		err := pkg.ValidatePort(pf.port)
+		if err != nil {
+			l.addIssue(fileName, node, prefix, pf.path, pkg.LintRulePort, fmt.Sprintf("%s%s: %s", header, pf.path, err.Error()))
+		}
+	}
+
+	quantities := []struct {
+		path  string
+		value string
+	}{
+		{path: "deployResources.memoryRequest", value: d.DeployResources.MemoryRequest},
+		{path: "deployResources.memoryLimit", value: d.DeployResources.MemoryLimit},
+		{path: "deployResources.cpuRequest", value: d.DeployResources.CpuRequest},
+		{path: "deployResources.cpuLimit", value: d.DeployResources.CpuLimit},
+		{path: "hpaConfig.memoryAverageValue", value: d.HpaConfig.MemoryAverageValue},
+		{path: "hpaConfig.cpuAverageValue", value: d.HpaConfig.CpuAverageValue},
+	}
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+		err := pkg.ValidateQuantity(q.value)
+		if err != nil {
+			l.addIssue(fileName, node, prefix, q.path, pkg.LintRuleQuantity, fmt.Sprintf("%s%s: %s", header, q.path, err.Error()))
+		}
+	}
+}
+
+// LintReferences check the problems between modules: duplicate modules, duplicate nodePorts in the same environment,
+// and the references to builds and packages, references are checked only when the referenced kind of the project is linted
+func (l *DefLinter) LintReferences() {
+	modulesDefined := map[string]defLintModule{}
+	builds := map[string]map[string]bool{}
+	packages := map[string]map[string]bool{}
+	for _, module := range l.modules {
+		key := strings.Join([]string{module.projectName, module.kind, module.envName, module.stepName, module.name}, "/")
+		if first, ok := modulesDefined[key]; ok {
+			line, column := pkg.YamlNodePosition(first.node, "")
+			l.addIssue(module.fileName, module.node, module.prefix, "", pkg.LintRuleDuplicate, fmt.Sprintf("%s/%s: duplicate module, already defined at %s", module.kind, module.name, pkg.LintIssue{FileName: first.fileName, Line: line, Column: column}.Position()))
+			continue
+		}
+		modulesDefined[key] = module
+		switch module.kind {
+		case "buildDefs":
+			if builds[module.projectName] == nil {
+				builds[module.projectName] = map[string]bool{}
+			}
+			builds[module.projectName][module.name] = true
+		case "packageDefs":
+			if packages[module.projectName] == nil {
+				packages[module.projectName] = map[string]bool{}
+			}
+			packages[module.projectName][module.name] = true
+		}
+	}
+
+	nodePorts := map[string]string{}
+	for _, module := range l.modules {
+		header := fmt.Sprintf("%s/%s: ", module.kind, module.name)
+		switch d := module.item.(type) {
+		case pkg.PackageDef:
+			if builds[module.projectName] == nil {
+				continue
+			}
+			for i, s := range d.RelatedBuilds {
+				if !builds[module.projectName][s] {
+					l.addIssue(module.fileName, module.node, module.prefix, fmt.Sprintf("relatedBuilds[%d]", i), pkg.LintRuleReference, fmt.Sprintf("%srelatedBuilds %s not found in project %s buildDefs", header, s, module.projectName))
+				}
+			}
+		case pkg.DeployContainerDef:
+			if packages[module.projectName] != nil && d.RelatedPackage != "" && !packages[module.projectName][d.RelatedPackage] {
+				l.addIssue(module.fileName, module.node, module.prefix, "relatedPackage", pkg.LintRuleReference, fmt.Sprintf("%srelatedPackage %s not found in project %s packageDefs", header, d.RelatedPackage, module.projectName))
+			}
+			for i, np := range d.DeployNodePorts {
+				if np.NodePort == 0 {
+					continue
+				}
+				key := fmt.Sprintf("%s/%s/%d", module.projectName, module.envName, np.NodePort)
+				path := fmt.Sprintf("deployNodePorts[%d].nodePort", i)
+				if name, ok := nodePorts[key]; ok {
+					l.addIssue(module.fileName, module.node, module.prefix, path, pkg.LintRulePort, fmt.Sprintf("%s%s: nodePort %d already used by %s in environment %s", header, path, np.NodePort, name, module.envName))
+					continue
+				}
+				nodePorts[key] = module.name
+			}
+		case pkg.PipelineDef:
+			if builds[module.projectName] == nil {
+				continue
+			}
+			for i, build := range d.Builds {
+				if build.Name != "" && !builds[module.projectName][build.Name] {
+					l.addIssue(module.fileName, module.node, module.prefix, fmt.Sprintf("builds[%d].name", i), pkg.LintRuleReference, fmt.Sprintf("%sbuilds %s not found in project %s buildDefs", header, build.Name, module.projectName))
+				}
+			}
+		}
+	}
+}
+
+// SortIssues sort the issues by file name, line and column
+func (l *DefLinter) SortIssues() {
+	sort.SliceStable(l.Issues, func(i, j int) bool {
+		a, b := l.Issues[i], l.Issues[j]
+		if a.FileName != b.FileName {
+			return a.FileName < b.FileName
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// GithubAnnotation format the issue as GitHub Actions error annotation
+func GithubAnnotation(issue pkg.LintIssue) string {
+	escapeData := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	escapeProperty := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	properties := []string{fmt.Sprintf("file=%s", escapeProperty.Replace(issue.FileName))}
+	if issue.Line > 0 {
+		properties = append(properties, fmt.Sprintf("line=%d", issue.Line))
+	}
+	if issue.Column > 0 {
+		properties = append(properties, fmt.Sprintf("col=%d", issue.Column))
+	}
+	properties = append(properties, fmt.Sprintf("title=%s", escapeProperty.Replace(fmt.Sprintf("doryctl def lint %s", issue.Rule))))
+	return fmt.Sprintf("::error %s::%s", strings.Join(properties, ","), escapeData.Replace(issue.Message))
+}
+
+func (o *OptionsDefLint) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	linter := NewDefLinter()
+	fileCount := len(o.Param.FileNames)
+	if o.Param.IsStdin {
+		bs, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		if len(bs) == 0 {
+			err = fmt.Errorf("--files - required os.stdin\n example: echo 'xxx' | %s def lint -f -", pkg.BaseCmdName)
+			return err
+		}
+		linter.LintFile("-", bs)
+		fileCount = 1
+	}
+	for _, fileName := range o.Param.FileNames {
+		bs, err = os.ReadFile(fileName)
+		if err != nil {
+			err = fmt.Errorf("read file %s error: %s", fileName, err.Error())
+			return err
+		}
+		linter.LintFile(fileName, bs)
+	}
+	linter.LintReferences()
+	linter.SortIssues()
+
+	switch o.Output {
+	case "json":
+		bs, _ = json.MarshalIndent(map[string]interface{}{"issues": linter.Issues}, "", "  ")
+		fmt.Println(string(bs))
+	case "yaml":
+		bs, _ = pkg.YamlIndent(map[string]interface{}{"issues": linter.Issues})
+		fmt.Println(string(bs))
+	case "github":
+		for _, issue := range linter.Issues {
+			fmt.Println(GithubAnnotation(issue))
+		}
+	default:
+		for _, issue := range linter.Issues {
+			fmt.Printf("%s: %s [%s]\n", issue.Position(), issue.Message, issue.Rule)
+		}
+	}
+
+	// only the issues are printed with --output, the output can be parsed
+	if o.Output != "" {
+		if len(linter.Issues) > 0 {
+			err = &ExitError{Code: pkg.ExitCodeFail}
+		}
+		return err
+	}
+	if len(linter.Issues) > 0 {
+		err = &ExitError{Code: pkg.ExitCodeFail, Err: fmt.Errorf("%d problems found in %d files", len(linter.Issues), fileCount)}
+		return err
+	}
+	log.Success(fmt.Sprintf("no problems found in %d files", fileCount))
+
+	return err
+}
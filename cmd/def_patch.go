@@ -8,7 +8,6 @@ import (
 	"github.com/tidwall/sjson"
 	"gopkg.in/yaml.v3"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"sort"
@@ -21,7 +20,10 @@ type OptionsDefPatch struct {
 	EnvNames       []string `yaml:"envNames" json:"envNames" bson:"envNames" validate:""`
 	BranchNames    []string `yaml:"branchNames" json:"branchNames" bson:"branchNames" validate:""`
 	StepName       string   `yaml:"stepName" json:"stepName" bson:"stepName" validate:""`
+	ModulePattern  string   `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+	Selector       string   `yaml:"selector" json:"selector" bson:"selector" validate:""`
 	Patch          string   `yaml:"patch" json:"patch" bson:"patch" validate:""`
+	PatchType      string   `yaml:"patchType" json:"patchType" bson:"patchType" validate:""`
 	FileName       string   `yaml:"fileName" json:"fileName" bson:"fileName" validate:""`
 	Runs           []string `yaml:"runs" json:"runs" bson:"runs" validate:""`
 	NoRuns         []string `yaml:"noRuns" json:"noRuns" bson:"noRuns" validate:""`
@@ -29,12 +31,22 @@ type OptionsDefPatch struct {
 	Full           bool     `yaml:"full" json:"full" bson:"full" validate:""`
 	Output         string   `yaml:"output" json:"output" bson:"output" validate:""`
 	Param          struct {
-		Kind         string            `yaml:"kind" json:"kind" bson:"kind" validate:""`
-		ProjectName  string            `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
-		PatchActions []pkg.PatchAction `yaml:"patchActions" json:"patchActions" bson:"patchActions" validate:""`
+		Kind          string                   `yaml:"kind" json:"kind" bson:"kind" validate:""`
+		ProjectName   string                   `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		PatchActions  []pkg.PatchAction        `yaml:"patchActions" json:"patchActions" bson:"patchActions" validate:""`
+		JSONPatches   []pkg.JSONPatchOperation `yaml:"jsonPatches" json:"jsonPatches" bson:"jsonPatches" validate:""`
+		MergePatches  []map[string]interface{} `yaml:"mergePatches" json:"mergePatches" bson:"mergePatches" validate:""`
+		ModulePattern pkg.NamePattern          `yaml:"modulePattern" json:"modulePattern" bson:"modulePattern" validate:""`
+		Selector      pkg.Selector             `yaml:"selector" json:"selector" bson:"selector" validate:""`
 	}
 }
 
+type patchContent struct {
+	name   string
+	format string
+	bs     []byte
+}
+
 func NewOptionsDefPatch() *OptionsDefPatch {
 	var o OptionsDefPatch
 	o.OptionsCommon = OptCommon
@@ -53,10 +65,19 @@ func NewCmdDefPatch() *cobra.Command {
 		"pipeline",
 	}
 
-	msgUse := fmt.Sprintf(`patch [projectName] [kind] [--output=json|yaml] [--patch=patchAction] [--file=patchFile]... [--modules=moduleName1,moduleName2] [--envs=envName1,envName2] [--branches=branchName1,branchName2] [--step=stepName1,stepName2]
+	msgUse := fmt.Sprintf(`patch [projectName] [kind] [--output=json|yaml] [--type=sjson|json|merge] [--patch=patch] [--file=patchFile]... [--modules=moduleName1,moduleName2] [--module-pattern=pattern] [--selector=selector] [--envs=envName1,envName2] [--branches=branchName1,branchName2] [--step=stepName1,stepName2]
   # kind options: %s`, strings.Join(defCmdKinds, " / "))
 	msgShort := fmt.Sprintf("patch project definitions")
-	msgLong := fmt.Sprintf(`patch project definitions in dory-core server`)
+	msgLong := fmt.Sprintf(`patch project definitions in dory-core server
+# patches apply to each module definition selected, or to each pipeline definition selected, --type options:
+#   sjson (default): patch actions, example: [{"action": "update", "path": "sjson.path", "value": "xxx"}], action options: update / delete
+#   json: JSON Patch (RFC 6902) operations, example: [{"op": "replace", "path": "/json/pointer", "value": "xxx"}], op options: add / remove / replace / move / copy / test,
+#         the whole patch is aborted if the value of test operation is not equal
+#   merge: JSON Merge Patch (RFC 7386) object, example: {"field": "xxx", "removeField": null}
+# --module-pattern select modules by glob pattern, or by regular expression with %s prefix
+# --selector select deploy, step and pipeline definitions by metadata labels or annotations, labels are envName, stepName, enableMode and branchName,
+# pipeline definitions annotations are envs, envProductions, isDefault, webhookPushEvent and tagSuffix, selector format:
+#   key=value, key!=value, key in (value1,value2), key notin (value1,value2), key (exists), !key (not exists)`, pkg.NamePatternRegexPrefix)
 	msgExample := fmt.Sprintf(`  # print current project build modules definitions for patched
   doryctl def patch test-project1 build --modules=tp1-go-demo,tp1-gin-demo -o yaml
 
@@ -69,6 +90,9 @@ func NewCmdDefPatch() *cobra.Command {
   # patch project deploy modules definitions, delete test environment tp1-gin-demo deployNodePorts.0.nodePort to 30109
   doryctl def patch test-project1 deploy --modules=tp1-gin-demo --envs=test --patch='[{"action": "update", "path": "deployNodePorts.0.nodePort", "value": 30109}]'
 
+  # patch project deploy modules definitions, update all *-api modules replicas in test and uat environments
+  doryctl def patch test-project1 deploy --module-pattern='*-api' -l 'envName in (test,uat)' --patch='[{"action": "update", "path": "deployReplicas", "value": 2}]'
+
   # patch project pipeline definitions, update builds dp1-gin-demo run setting to true 
   doryctl def patch test-project1 pipeline --branches=develop,release --patch='[{"action": "update", "path": "builds.#(name==\"dp1-gin-demo\").run", "value": true}]'
 
@@ -81,6 +105,12 @@ func NewCmdDefPatch() *cobra.Command {
   # patch project custom step modules definitions, update customStepName2 step in test environment tp1-gin-demo paramInputYaml
   doryctl def patch test-project1 step --envs=test --step=customStepName2 --modules=tp1-gin-demo --patch='[{"action": "update", "path": "paramInputYaml", "value": "path: Tests"}]'
 
+  # patch project deploy modules definitions with JSON Patch, update tp1-gin-demo replicas only if current replicas is 1
+  doryctl def patch test-project1 deploy --modules=tp1-gin-demo --envs=test --type=json --patch='[{"op": "test", "path": "/deployReplicas", "value": 1}, {"op": "replace", "path": "/deployReplicas", "value": 2}]'
+
+  # patch project build modules definitions with JSON Merge Patch, update buildEnv and remove buildChecks
+  doryctl def patch test-project1 build --modules=tp1-go-demo --type=merge --patch='{"buildEnv": "go-1.21", "buildChecks": null}'
+
   # patch project pipeline definitions from stdin, support JSON and YAML
   cat << EOF | doryctl def patch test-project1 pipeline --branches=develop,release -f -
   - action: update
@@ -111,11 +141,14 @@ func NewCmdDefPatch() *cobra.Command {
 			CheckError(o.Run(args))
 		},
 	}
-	cmd.Flags().StringSliceVar(&o.ModuleNames, "modules", []string{}, "filter moduleNames to patch")
-	cmd.Flags().StringSliceVar(&o.EnvNames, "envs", []string{}, "filter envNames to patch, required if kind is deploy")
-	cmd.Flags().StringSliceVar(&o.BranchNames, "branches", []string{}, "filter branchNames to patch, required if kind is pipeline")
+	cmd.Flags().StringSliceVar(&o.ModuleNames, "modules", []string{}, "filter moduleNames to patch, --modules or --module-pattern required if kind is not pipeline")
+	cmd.Flags().StringVar(&o.ModulePattern, "module-pattern", "", "filter moduleNames to patch by glob pattern, or regular expression with regex: prefix, example: *-api")
+	cmd.Flags().StringSliceVar(&o.EnvNames, "envs", []string{}, "filter envNames to patch, --envs or --selector required if kind is deploy")
+	cmd.Flags().StringSliceVar(&o.BranchNames, "branches", []string{}, "filter branchNames to patch, --branches or --selector required if kind is pipeline")
+	cmd.Flags().StringVarP(&o.Selector, "selector", "l", "", "filter deploy, step and pipeline definitions to patch by metadata labels or annotations selector, example: envName in (test,uat)")
 	cmd.Flags().StringVar(&o.StepName, "step", "", "filter stepName to patch, required if kind is step")
-	cmd.Flags().StringVarP(&o.Patch, "patch", "p", "", "patch actions in JSON format")
+	cmd.Flags().StringVarP(&o.Patch, "patch", "p", "", "patch in JSON format, the format depends on --type")
+	cmd.Flags().StringVar(&o.PatchType, "type", pkg.PatchTypeSjson, "patch type of --patch and --file, options: sjson / json / merge")
 	cmd.Flags().StringVarP(&o.FileName, "file", "f", "", "project definitions file name or directory, support *.json and *.yaml and *.yml file")
 	cmd.Flags().StringSliceVar(&o.Runs, "runs", []string{}, "set pipeline which build modules enable run, only uses with kind is pipeline")
 	cmd.Flags().StringSliceVar(&o.NoRuns, "no-runs", []string{}, "set pipeline which build modules disable run, only uses with kind is pipeline")
@@ -406,16 +439,31 @@ func (o *OptionsDefPatch) Validate(args []string) error {
 	}
 	o.Param.ProjectName = projectName
 
-	if kind != "pipeline" && len(o.ModuleNames) == 0 {
-		err = fmt.Errorf("--modules required")
+	o.Param.ModulePattern, err = pkg.ParseNamePattern(o.ModulePattern)
+	if err != nil {
+		err = fmt.Errorf("--module-pattern %s format error: %s", o.ModulePattern, err.Error())
+		return err
+	}
+	o.Param.Selector, err = pkg.ParseSelector(o.Selector)
+	if err != nil {
+		err = fmt.Errorf("--selector %s format error: %s", o.Selector, err.Error())
+		return err
+	}
+	if len(o.Param.Selector) > 0 && kind != "deploy" && kind != "step" && kind != "pipeline" {
+		err = fmt.Errorf("--selector only uses with kind deploy / step / pipeline")
+		return err
+	}
+
+	if kind != "pipeline" && len(o.ModuleNames) == 0 && o.ModulePattern == "" {
+		err = fmt.Errorf("--modules or --module-pattern required")
 		return err
 	}
-	if kind == "pipeline" && len(o.BranchNames) == 0 {
-		err = fmt.Errorf("kind is pipeline, --branches required")
+	if kind == "pipeline" && len(o.BranchNames) == 0 && len(o.Param.Selector) == 0 {
+		err = fmt.Errorf("kind is pipeline, --branches or --selector required")
 		return err
 	}
-	if kind == "deploy" && len(o.EnvNames) == 0 {
-		err = fmt.Errorf("kind is deploy, --envs required")
+	if kind == "deploy" && len(o.EnvNames) == 0 && len(o.Param.Selector) == 0 {
+		err = fmt.Errorf("kind is deploy, --envs or --selector required")
 		return err
 	}
 	if kind == "step" && o.StepName == "" {
@@ -454,8 +502,12 @@ func (o *OptionsDefPatch) Validate(args []string) error {
 		}
 	}
 
-	patchActions := []pkg.PatchAction{}
-	pas := []pkg.PatchAction{}
+	if o.PatchType != pkg.PatchTypeSjson && o.PatchType != pkg.PatchTypeJson && o.PatchType != pkg.PatchTypeMerge {
+		err = fmt.Errorf("--type must be %s / %s / %s", pkg.PatchTypeSjson, pkg.PatchTypeJson, pkg.PatchTypeMerge)
+		return err
+	}
+
+	patchContents := []patchContent{}
 	if o.FileName == "-" {
 		bs, err := io.ReadAll(os.Stdin)
 		if err != nil {
@@ -466,71 +518,63 @@ func (o *OptionsDefPatch) Validate(args []string) error {
 			err = fmt.Errorf("--file - required os.stdin\n example: echo 'xxx' | %s def patch test-project1 build --modules=tp1-gin-demo -f -", pkg.BaseCmdName)
 			return err
 		}
-		err = json.Unmarshal(bs, &pas)
+		patchContents = append(patchContents, patchContent{name: "--file", bs: bs})
+	} else if o.FileName != "" {
+		ext := filepath.Ext(o.FileName)
+		if ext != ".json" && ext != ".yaml" && ext != ".yml" {
+			err = fmt.Errorf("--file %s read error: file extension must be json or yaml or yml", o.FileName)
+			return err
+		}
+		bs, err := os.ReadFile(o.FileName)
 		if err != nil {
-			err = yaml.Unmarshal(bs, &pas)
-			if err != nil {
-				err = fmt.Errorf("--file parse error: %s", err.Error())
-				return err
-			}
+			err = fmt.Errorf("--file %s read error: %s", o.FileName, err.Error())
+			return err
 		}
-	} else if o.FileName != "" {
-		if o.FileName != "-" {
-			ext := filepath.Ext(o.FileName)
-			if ext != ".json" && ext != ".yaml" && ext != ".yml" {
-				err = fmt.Errorf("--file %s read error: file extension must be json or yaml or yml", o.FileName)
+		format := "yaml"
+		if ext == ".json" {
+			format = "json"
+		}
+		patchContents = append(patchContents, patchContent{name: fmt.Sprintf("--file %s", o.FileName), format: format, bs: bs})
+	}
+	if o.Patch != "" {
+		patchContents = append(patchContents, patchContent{name: fmt.Sprintf("--patch %s", o.Patch), format: "json", bs: []byte(o.Patch)})
+	}
+
+	patchActions := []pkg.PatchAction{}
+	for _, pc := range patchContents {
+		switch o.PatchType {
+		case pkg.PatchTypeSjson:
+			pas := []pkg.PatchAction{}
+			err = UnmarshalPatch(pc.bs, pc.format, &pas)
+			if err != nil {
+				err = fmt.Errorf("%s parse error: %s", pc.name, err.Error())
 				return err
 			}
-			bs, err := os.ReadFile(o.FileName)
+			patchActions = append(patchActions, pas...)
+		case pkg.PatchTypeJson:
+			jpos := []pkg.JSONPatchOperation{}
+			err = UnmarshalPatch(pc.bs, pc.format, &jpos)
 			if err != nil {
-				err = fmt.Errorf("--file %s read error: %s", o.FileName, err.Error())
+				err = fmt.Errorf("%s parse error: %s", pc.name, err.Error())
 				return err
 			}
-			switch ext {
-			case ".json":
-				err = json.Unmarshal(bs, &pas)
-				if err != nil {
-					err = fmt.Errorf("--file %s parse error: %s", o.FileName, err.Error())
-					return err
-				}
-			case ".yaml", ".yml":
-				err = yaml.Unmarshal(bs, &pas)
+			for _, jpo := range jpos {
+				err = jpo.Validate()
 				if err != nil {
-					err = fmt.Errorf("--file %s parse error: %s", o.FileName, err.Error())
+					bs, _ := json.Marshal(jpo)
+					err = fmt.Errorf("%s parse error: %s %s", pc.name, string(bs), err.Error())
 					return err
 				}
 			}
-		} else {
-			bs, err := io.ReadAll(os.Stdin)
+			o.Param.JSONPatches = append(o.Param.JSONPatches, jpos...)
+		case pkg.PatchTypeMerge:
+			mergePatch := map[string]interface{}{}
+			err = UnmarshalPatch(pc.bs, pc.format, &mergePatch)
 			if err != nil {
+				err = fmt.Errorf("%s parse error: merge patch must be an object, %s", pc.name, err.Error())
 				return err
 			}
-			if len(bs) == 0 {
-				err = fmt.Errorf("--file - required os.stdin\n example: echo 'xxx' | %s def patch [...] -f -", pkg.BaseCmdName)
-				return err
-			}
-			if json.Unmarshal(bs, &pas) != nil {
-				err = yaml.Unmarshal(bs, &pas)
-				if err != nil {
-					err = fmt.Errorf("--file %s parse error: %s", o.FileName, err.Error())
-					return err
-				}
-			}
-		}
-	}
-	for _, pa := range pas {
-		patchActions = append(patchActions, pa)
-	}
-
-	if o.Patch != "" {
-		pas = []pkg.PatchAction{}
-		err = json.Unmarshal([]byte(o.Patch), &pas)
-		if err != nil {
-			err = fmt.Errorf("--patch %s parse error: %s", o.Patch, err.Error())
-			return err
-		}
-		for _, pa := range pas {
-			patchActions = append(patchActions, pa)
+			o.Param.MergePatches = append(o.Param.MergePatches, mergePatch)
 		}
 	}
 
@@ -614,14 +658,16 @@ func (o *OptionsDefPatch) Run(args []string) error {
 		}
 	}
 
+	isEnvStep := len(o.EnvNames) > 0
 	if o.StepName != "" {
 		var found bool
 		for _, conf := range project.CustomStepConfs {
 			if conf.CustomStepName == o.StepName {
-				if len(o.EnvNames) == 0 && !conf.IsEnvDiff {
-					found = true
-					break
-				} else if len(o.EnvNames) > 0 && conf.IsEnvDiff {
+				if len(o.EnvNames) == 0 && len(o.Param.Selector) > 0 {
+					// without --envs, the selector selects the step definitions in project or in environments
+					isEnvStep = conf.IsEnvDiff
+				}
+				if isEnvStep == conf.IsEnvDiff {
 					found = true
 					break
 				}
@@ -686,13 +732,7 @@ func (o *OptionsDefPatch) Run(args []string) error {
 		defs := []pkg.BuildDef{}
 		ds := []pkg.BuildDef{}
 		for _, def := range project.ProjectDef.BuildDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.BuildName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.BuildName, o.ModuleNames, o.Param.ModulePattern)
 			if found == true {
 				ds = append(ds, def)
 			}
@@ -728,13 +768,7 @@ func (o *OptionsDefPatch) Run(args []string) error {
 		defs := []pkg.PackageDef{}
 		ds := []pkg.PackageDef{}
 		for _, def := range project.ProjectDef.PackageDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.PackageName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.PackageName, o.ModuleNames, o.Param.ModulePattern)
 			if found == true {
 				ds = append(ds, def)
 			}
@@ -752,14 +786,15 @@ func (o *OptionsDefPatch) Run(args []string) error {
 		defUpdateFilters = append(defUpdateFilters, defUpdateFilter)
 	case "deploy":
 		for _, pae := range project.ProjectAvailableEnvs {
-			var found bool
+			found := len(o.EnvNames) == 0
 			for _, envName := range o.EnvNames {
 				if pae.EnvName == envName {
 					found = true
 					break
 				}
 			}
-			if found {
+			metadata := pkg.DefMetadata{Labels: map[string]string{"envName": pae.EnvName}}
+			if found && o.Param.Selector.Match(metadata) {
 				sort.SliceStable(pae.DeployContainerDefs, func(i, j int) bool {
 					return pae.DeployContainerDefs[i].DeployName < pae.DeployContainerDefs[j].DeployName
 				})
@@ -779,13 +814,7 @@ func (o *OptionsDefPatch) Run(args []string) error {
 				defs := []pkg.DeployContainerDef{}
 				ds := []pkg.DeployContainerDef{}
 				for _, def := range pae.DeployContainerDefs {
-					var found bool
-					for _, moduleName := range o.ModuleNames {
-						if def.DeployName == moduleName {
-							found = true
-							break
-						}
-					}
+					found := MatchModuleName(def.DeployName, o.ModuleNames, o.Param.ModulePattern)
 					if found == true {
 						ds = append(ds, def)
 					}
@@ -805,9 +834,10 @@ func (o *OptionsDefPatch) Run(args []string) error {
 			}
 		}
 	case "step":
-		if len(o.EnvNames) == 0 {
+		if !isEnvStep {
 			for stepName, csd := range project.ProjectDef.CustomStepDefs {
-				if stepName == o.StepName {
+				metadata := pkg.DefMetadata{Labels: map[string]string{"stepName": stepName, "enableMode": csd.EnableMode}}
+				if stepName == o.StepName && o.Param.Selector.Match(metadata) {
 					sort.SliceStable(csd.CustomStepModuleDefs, func(i, j int) bool {
 						return csd.CustomStepModuleDefs[i].ModuleName < csd.CustomStepModuleDefs[j].ModuleName
 					})
@@ -827,13 +857,7 @@ func (o *OptionsDefPatch) Run(args []string) error {
 					defs := []pkg.CustomStepModuleDef{}
 					ds := []pkg.CustomStepModuleDef{}
 					for _, def := range csd.CustomStepModuleDefs {
-						var found bool
-						for _, moduleName := range o.ModuleNames {
-							if def.ModuleName == moduleName {
-								found = true
-								break
-							}
-						}
+						found := MatchModuleName(def.ModuleName, o.ModuleNames, o.Param.ModulePattern)
 						if found == true {
 							ds = append(ds, def)
 						}
@@ -858,14 +882,15 @@ func (o *OptionsDefPatch) Run(args []string) error {
 		} else {
 			for _, pae := range project.ProjectAvailableEnvs {
 				for stepName, csd := range pae.CustomStepDefs {
-					var found bool
+					found := len(o.EnvNames) == 0
 					for _, envName := range o.EnvNames {
 						if pae.EnvName == envName {
 							found = true
 							break
 						}
 					}
-					if found {
+					metadata := pkg.DefMetadata{Labels: map[string]string{"envName": pae.EnvName, "stepName": stepName, "enableMode": csd.EnableMode}}
+					if found && o.Param.Selector.Match(metadata) {
 						sort.SliceStable(csd.CustomStepModuleDefs, func(i, j int) bool {
 							return csd.CustomStepModuleDefs[i].ModuleName < csd.CustomStepModuleDefs[j].ModuleName
 						})
@@ -885,13 +910,7 @@ func (o *OptionsDefPatch) Run(args []string) error {
 						defs := []pkg.CustomStepModuleDef{}
 						ds := []pkg.CustomStepModuleDef{}
 						for _, def := range csd.CustomStepModuleDefs {
-							var found bool
-							for _, moduleName := range o.ModuleNames {
-								if def.ModuleName == moduleName {
-									found = true
-									break
-								}
-							}
+							found := MatchModuleName(def.ModuleName, o.ModuleNames, o.Param.ModulePattern)
 							if found == true {
 								ds = append(ds, def)
 							}
@@ -917,14 +936,14 @@ func (o *OptionsDefPatch) Run(args []string) error {
 		}
 	case "pipeline":
 		for _, pp := range project.ProjectPipelines {
-			var found bool
+			found := len(o.BranchNames) == 0
 			for _, branchName := range o.BranchNames {
 				if pp.BranchName == branchName {
 					found = true
 					break
 				}
 			}
-			if found {
+			if found && o.Param.Selector.Match(PipelineDefMetadata(pp)) {
 				defUpdate := pkg.DefUpdate{
 					Kind:        pkg.DefCmdKinds[o.Param.Kind],
 					ProjectName: project.ProjectInfo.ProjectName,
@@ -956,13 +975,7 @@ func (o *OptionsDefPatch) Run(args []string) error {
 		defs := []pkg.CustomOpsDef{}
 		ds := []pkg.CustomOpsDef{}
 		for _, def := range project.ProjectDef.CustomOpsDefs {
-			var found bool
-			for _, moduleName := range o.ModuleNames {
-				if def.CustomOpsName == moduleName {
-					found = true
-					break
-				}
-			}
+			found := MatchModuleName(def.CustomOpsName, o.ModuleNames, o.Param.ModulePattern)
 			if found == true {
 				ds = append(ds, def)
 			}
@@ -986,7 +999,7 @@ func (o *OptionsDefPatch) Run(args []string) error {
 	}
 
 	defPatches := []pkg.DefUpdate{}
-	if len(o.Param.PatchActions) > 0 {
+	if len(o.Param.PatchActions) > 0 || len(o.Param.JSONPatches) > 0 || len(o.Param.MergePatches) > 0 {
 		for idx, defUpdate := range defUpdates {
 			bs, _ := json.Marshal(defUpdate.Def)
 			switch defUpdate.Kind {
@@ -998,30 +1011,14 @@ func (o *OptionsDefPatch) Run(args []string) error {
 					if d.IsPatch {
 						var dp pkg.BuildDef
 						bs, _ := json.Marshal(d)
-						var s string
-						for _, patchAction := range o.Param.PatchActions {
-							switch patchAction.Action {
-							case "update":
-								s, err = sjson.Set(string(bs), patchAction.Path, patchAction.Value)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s value=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, patchAction.Str, err.Error(), string(bs))
-									return err
-								}
-							case "delete":
-								s, err = sjson.Delete(string(bs), patchAction.Path)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, err.Error(), string(bs))
-									return err
-								}
-							}
-							var dd pkg.BuildDef
-							err = json.Unmarshal([]byte(s), &dd)
-							if err != nil {
-								err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), s)
-								return err
-							}
-							bs = []byte(s)
-							dp = dd
+						bsPatch, err := o.PatchDef(defUpdate.Kind, bs)
+						if err != nil {
+							return err
+						}
+						err = json.Unmarshal(bsPatch, &dp)
+						if err != nil {
+							err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), string(bsPatch))
+							return err
 						}
 						defs[i] = dp
 						dps = append(dps, dp)
@@ -1041,30 +1038,14 @@ func (o *OptionsDefPatch) Run(args []string) error {
 					if d.IsPatch {
 						var dp pkg.PackageDef
 						bs, _ := json.Marshal(d)
-						var s string
-						for _, patchAction := range o.Param.PatchActions {
-							switch patchAction.Action {
-							case "update":
-								s, err = sjson.Set(string(bs), patchAction.Path, patchAction.Value)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s value=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, patchAction.Str, err.Error(), string(bs))
-									return err
-								}
-							case "delete":
-								s, err = sjson.Delete(string(bs), patchAction.Path)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, err.Error(), string(bs))
-									return err
-								}
-							}
-							var dd pkg.PackageDef
-							err = json.Unmarshal([]byte(s), &dd)
-							if err != nil {
-								err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), s)
-								return err
-							}
-							bs = []byte(s)
-							dp = dd
+						bsPatch, err := o.PatchDef(defUpdate.Kind, bs)
+						if err != nil {
+							return err
+						}
+						err = json.Unmarshal(bsPatch, &dp)
+						if err != nil {
+							err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), string(bsPatch))
+							return err
 						}
 						defs[i] = dp
 						dps = append(dps, dp)
@@ -1084,30 +1065,14 @@ func (o *OptionsDefPatch) Run(args []string) error {
 					if d.IsPatch {
 						var dp pkg.DeployContainerDef
 						bs, _ := json.Marshal(d)
-						var s string
-						for _, patchAction := range o.Param.PatchActions {
-							switch patchAction.Action {
-							case "update":
-								s, err = sjson.Set(string(bs), patchAction.Path, patchAction.Value)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s value=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, patchAction.Str, err.Error(), string(bs))
-									return err
-								}
-							case "delete":
-								s, err = sjson.Delete(string(bs), patchAction.Path)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, err.Error(), string(bs))
-									return err
-								}
-							}
-							var dd pkg.DeployContainerDef
-							err = json.Unmarshal([]byte(s), &dd)
-							if err != nil {
-								err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), s)
-								return err
-							}
-							bs = []byte(s)
-							dp = dd
+						bsPatch, err := o.PatchDef(defUpdate.Kind, bs)
+						if err != nil {
+							return err
+						}
+						err = json.Unmarshal(bsPatch, &dp)
+						if err != nil {
+							err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), string(bsPatch))
+							return err
 						}
 						defs[i] = dp
 						dps = append(dps, dp)
@@ -1127,30 +1092,14 @@ func (o *OptionsDefPatch) Run(args []string) error {
 					if d.IsPatch {
 						var dp pkg.CustomStepModuleDef
 						bs, _ := json.Marshal(d)
-						var s string
-						for _, patchAction := range o.Param.PatchActions {
-							switch patchAction.Action {
-							case "update":
-								s, err = sjson.Set(string(bs), patchAction.Path, patchAction.Value)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s value=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, patchAction.Str, err.Error(), string(bs))
-									return err
-								}
-							case "delete":
-								s, err = sjson.Delete(string(bs), patchAction.Path)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, err.Error(), string(bs))
-									return err
-								}
-							}
-							var dd pkg.CustomStepModuleDef
-							err = json.Unmarshal([]byte(s), &dd)
-							if err != nil {
-								err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), s)
-								return err
-							}
-							bs = []byte(s)
-							dp = dd
+						bsPatch, err := o.PatchDef(defUpdate.Kind, bs)
+						if err != nil {
+							return err
+						}
+						err = json.Unmarshal(bsPatch, &dp)
+						if err != nil {
+							err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), string(bsPatch))
+							return err
 						}
 						defs.CustomStepModuleDefs[i] = dp
 						dps = append(dps, dp)
@@ -1167,30 +1116,14 @@ func (o *OptionsDefPatch) Run(args []string) error {
 				def := pkg.PipelineDef{}
 				_ = json.Unmarshal(bs, &def)
 				var dp pkg.PipelineDef
-				var s string
-				for _, patchAction := range o.Param.PatchActions {
-					switch patchAction.Action {
-					case "update":
-						s, err = sjson.Set(string(bs), patchAction.Path, patchAction.Value)
-						if err != nil {
-							err = fmt.Errorf("patch %s action=%s path=%s value=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, patchAction.Str, err.Error(), string(bs))
-							return err
-						}
-					case "delete":
-						s, err = sjson.Delete(string(bs), patchAction.Path)
-						if err != nil {
-							err = fmt.Errorf("patch %s action=%s path=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, err.Error(), string(bs))
-							return err
-						}
-					}
-					var dd pkg.PipelineDef
-					err = json.Unmarshal([]byte(s), &dd)
-					if err != nil {
-						err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), s)
-						return err
-					}
-					bs = []byte(s)
-					dp = dd
+				bsPatch, err := o.PatchDef(defUpdate.Kind, bs)
+				if err != nil {
+					return err
+				}
+				err = json.Unmarshal(bsPatch, &dp)
+				if err != nil {
+					err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), string(bsPatch))
+					return err
 				}
 				defUpdate.Def = dp
 				defUpdates[idx] = defUpdate
@@ -1203,30 +1136,14 @@ func (o *OptionsDefPatch) Run(args []string) error {
 					if d.IsPatch {
 						var dp pkg.CustomOpsDef
 						bs, _ := json.Marshal(d)
-						var s string
-						for _, patchAction := range o.Param.PatchActions {
-							switch patchAction.Action {
-							case "update":
-								s, err = sjson.Set(string(bs), patchAction.Path, patchAction.Value)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s value=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, patchAction.Str, err.Error(), string(bs))
-									return err
-								}
-							case "delete":
-								s, err = sjson.Delete(string(bs), patchAction.Path)
-								if err != nil {
-									err = fmt.Errorf("patch %s action=%s path=%s error: %s\n%s", defUpdate.Kind, patchAction.Action, patchAction.Path, err.Error(), string(bs))
-									return err
-								}
-							}
-							var dd pkg.CustomOpsDef
-							err = json.Unmarshal([]byte(s), &dd)
-							if err != nil {
-								err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), s)
-								return err
-							}
-							bs = []byte(s)
-							dp = dd
+						bsPatch, err := o.PatchDef(defUpdate.Kind, bs)
+						if err != nil {
+							return err
+						}
+						err = json.Unmarshal(bsPatch, &dp)
+						if err != nil {
+							err = fmt.Errorf("parse %s error: %s\n%s", defUpdate.Kind, err.Error(), string(bsPatch))
+							return err
 						}
 						defs[i] = dp
 						dps = append(dps, dp)
@@ -1271,49 +1188,71 @@ func (o *OptionsDefPatch) Run(args []string) error {
 	}
 
 	if !o.Try && len(defPatches) > 0 {
-		for _, defUpdate := range defUpdates {
-			bs, _ = pkg.YamlIndent(defUpdate.Def)
+		err = o.ApplyDefUpdates(defUpdates)
+		if err != nil {
+			return err
+		}
+	}
 
-			param := map[string]interface{}{
-				"envName":        defUpdate.EnvName,
-				"customStepName": defUpdate.CustomStepName,
-				"branchName":     defUpdate.BranchName,
-			}
-			paramOutput := map[string]interface{}{}
-			for k, v := range param {
-				paramOutput[k] = v
+	return err
+}
+
+// PatchDef apply the patches in --type format to the definition JSON, then apply the --runs and --no-runs patch actions
+func (o *OptionsDefPatch) PatchDef(kind string, bs []byte) ([]byte, error) {
+	var err error
+	switch o.PatchType {
+	case pkg.PatchTypeJson:
+		bsPatch, err := pkg.ApplyJSONPatch(bs, o.Param.JSONPatches)
+		if err != nil {
+			err = fmt.Errorf("patch %s type=%s %s\n%s", kind, o.PatchType, err.Error(), string(bs))
+			return bs, err
+		}
+		bs = bsPatch
+	case pkg.PatchTypeMerge:
+		for _, mergePatch := range o.Param.MergePatches {
+			bsPatch, err := pkg.ApplyMergePatch(bs, mergePatch)
+			if err != nil {
+				err = fmt.Errorf("patch %s type=%s error: %s\n%s", kind, o.PatchType, err.Error(), string(bs))
+				return bs, err
 			}
+			bs = bsPatch
+		}
+	}
 
-			urlKind := defUpdate.Kind
-			switch defUpdate.Kind {
-			case "buildDefs":
-				param["buildDefsYaml"] = string(bs)
-			case "packageDefs":
-				param["packageDefsYaml"] = string(bs)
-			case "deployContainerDefs":
-				param["deployContainerDefsYaml"] = string(bs)
-			case "customStepDef":
-				param["customStepDefYaml"] = string(bs)
-				if defUpdate.EnvName != "" {
-					urlKind = fmt.Sprintf("%s/env", urlKind)
-				}
-			case "customOpsDefs":
-				param["customOpsDefsYaml"] = string(bs)
-			case "pipelineDef":
-				param["pipelineDefYaml"] = string(bs)
+	for _, patchAction := range o.Param.PatchActions {
+		var s string
+		switch patchAction.Action {
+		case "update":
+			s, err = sjson.Set(string(bs), patchAction.Path, patchAction.Value)
+			if err != nil {
+				err = fmt.Errorf("patch %s action=%s path=%s value=%s error: %s\n%s", kind, patchAction.Action, patchAction.Path, patchAction.Str, err.Error(), string(bs))
+				return bs, err
 			}
-			paramOutput = pkg.RemoveMapEmptyItems(paramOutput)
-			bs, _ = json.Marshal(paramOutput)
-			logHeader := fmt.Sprintf("[%s/%s] %s", defUpdate.ProjectName, defUpdate.Kind, string(bs))
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/projectDef/%s/%s", defUpdate.ProjectName, urlKind), http.MethodPost, "", param, false)
+		case "delete":
+			s, err = sjson.Delete(string(bs), patchAction.Path)
 			if err != nil {
-				err = fmt.Errorf("%s: %s", logHeader, err.Error())
-				return err
+				err = fmt.Errorf("patch %s action=%s path=%s error: %s\n%s", kind, patchAction.Action, patchAction.Path, err.Error(), string(bs))
+				return bs, err
 			}
-			msg := result.Get("msg").String()
-			log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
 		}
+		bs = []byte(s)
 	}
+	return bs, err
+}
 
+// UnmarshalPatch parse the patch content in json or yaml format, try json then yaml if format is empty
+func UnmarshalPatch(bs []byte, format string, obj interface{}) error {
+	var err error
+	switch format {
+	case "json":
+		err = json.Unmarshal(bs, obj)
+	case "yaml":
+		err = yaml.Unmarshal(bs, obj)
+	default:
+		err = json.Unmarshal(bs, obj)
+		if err != nil {
+			err = yaml.Unmarshal(bs, obj)
+		}
+	}
 	return err
 }
@@ -0,0 +1,464 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/spf13/cobra"
+	"sort"
+)
+
+type OptionsDefRename struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Try            bool   `yaml:"try" json:"try" bson:"try" validate:""`
+	Full           bool   `yaml:"full" json:"full" bson:"full" validate:""`
+	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	Param          struct {
+		ProjectName   string `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		OldModuleName string `yaml:"oldModuleName" json:"oldModuleName" bson:"oldModuleName" validate:""`
+		NewModuleName string `yaml:"newModuleName" json:"newModuleName" bson:"newModuleName" validate:""`
+	}
+}
+
+func NewOptionsDefRename() *OptionsDefRename {
+	var o OptionsDefRename
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdDefRename() *cobra.Command {
+	o := NewOptionsDefRename()
+
+	msgUse := fmt.Sprintf(`rename [projectName] [oldModuleName] [newModuleName] [--output=json|yaml]`)
+	msgShort := fmt.Sprintf("rename module in all project definitions")
+	msgLong := fmt.Sprintf(`rename module in all project definitions in dory-core server, all changes are planned and applied as one batch
+# renamed fields: buildDefs buildName, packageDefs packageName and relatedBuilds,
+#   deployContainerDefs deployName, relatedPackage and dependServices dependName in all environments,
+#   customStepDef moduleName and relatedStepModules in project and all environments, pipelineDef builds name
+# rename fails if newModuleName already exists in the same definition
+# all definitions are checked before apply, if a definition failed to apply,
+#   the applied definitions are listed, and the commands to resume or revert the rename are printed
+# with --output, the rename plan is printed to stderr, only the changed project definitions are printed to stdout`)
+	msgExample := fmt.Sprintf(`  # rename module in all project definitions
+  doryctl def rename test-project1 tp1-gin-demo tp1-gin-api
+
+  # show the rename plan and the changed project definitions only, not apply to dory-core server
+  doryctl def rename test-project1 tp1-gin-demo tp1-gin-api --try -o yaml`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+	cmd.Flags().BoolVar(&o.Full, "full", false, "output project definitions in full version, use with --output option")
+	cmd.Flags().BoolVar(&o.Try, "try", false, "try to check input project definitions only, not apply to dory-core server, use with --output option")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsDefRename) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			projectNames, err := o.GetProjectNames()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return projectNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) == 1 {
+			project, err := o.GetProjectDef(args[0])
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			m := map[string]string{}
+			for _, def := range project.ProjectDef.BuildDefs {
+				m[def.BuildName] = def.BuildName
+			}
+			for _, def := range project.ProjectDef.PackageDefs {
+				m[def.PackageName] = def.PackageName
+			}
+			for _, pae := range project.ProjectAvailableEnvs {
+				for _, def := range pae.DeployContainerDefs {
+					m[def.DeployName] = def.DeployName
+				}
+			}
+			moduleNames := []string{}
+			for k, _ := range m {
+				moduleNames = append(moduleNames, k)
+			}
+			sort.Strings(moduleNames)
+			return moduleNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsDefRename) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		err = fmt.Errorf("projectName required")
+		return err
+	}
+	if len(args) == 1 {
+		err = fmt.Errorf("oldModuleName required")
+		return err
+	}
+	if len(args) == 2 {
+		err = fmt.Errorf("newModuleName required")
+		return err
+	}
+	projectName := args[0]
+	oldModuleName := args[1]
+	newModuleName := args[2]
+
+	err = pkg.ValidateMinusNameID(projectName)
+	if err != nil {
+		err = fmt.Errorf("projectName %s format error: %s", projectName, err.Error())
+		return err
+	}
+	err = pkg.ValidateMinusNameID(oldModuleName)
+	if err != nil {
+		err = fmt.Errorf("oldModuleName %s format error: %s", oldModuleName, err.Error())
+		return err
+	}
+	err = pkg.ValidateMinusNameID(newModuleName)
+	if err != nil {
+		err = fmt.Errorf("newModuleName %s format error: %s", newModuleName, err.Error())
+		return err
+	}
+	if oldModuleName == newModuleName {
+		err = fmt.Errorf("newModuleName %s is the same as oldModuleName", newModuleName)
+		return err
+	}
+	o.Param.ProjectName = projectName
+	o.Param.OldModuleName = oldModuleName
+	o.Param.NewModuleName = newModuleName
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" {
+			err = fmt.Errorf("--output must be yaml or json")
+			return err
+		}
+	}
+	return err
+}
+
+func (o *OptionsDefRename) Run(args []string) error {
+	var err error
+
+	// the rename plan is printed to stderr with --output, the output can be applied by def apply
+	if o.Output != "" {
+		log.SetStderr(true)
+	}
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	project, err := o.GetProjectDef(o.Param.ProjectName)
+	if err != nil {
+		return err
+	}
+
+	desiredProject, err := CopyProjectOutput(project)
+	if err != nil {
+		return err
+	}
+	desiredProject, plan, err := RenameModule(desiredProject, o.Param.OldModuleName, o.Param.NewModuleName)
+	if err != nil {
+		return err
+	}
+	for _, s := range plan {
+		log.Info(s)
+	}
+
+	defKinds := []pkg.DefKind{}
+	defUpdates := GetDefUpdates(desiredProject)
+	SortDefUpdates(defUpdates)
+	for _, defUpdate := range defUpdates {
+		defKinds = append(defKinds, DefUpdateDefKind(defUpdate))
+	}
+	log.Info(fmt.Sprintf("rename plan: %d modules renamed or updated, %d definitions will be changed", len(plan), len(defUpdates)))
+
+	// check the whole batch before apply, nothing is applied if any definition is invalid
+	for i, defUpdate := range defUpdates {
+		err = CheckDefKind(defKinds[i])
+		if err != nil {
+			err = fmt.Errorf("check %s error: %s", DefUpdatePath(defUpdate), err.Error())
+			return err
+		}
+		_, _, err = client.DefUpdateURLParam(defUpdate)
+		if err != nil {
+			err = fmt.Errorf("check %s error: %s", DefUpdatePath(defUpdate), err.Error())
+			return err
+		}
+	}
+
+	defKindList := pkg.DefKindList{
+		Kind: "list",
+		Defs: defKinds,
+	}
+
+	dataOutput := map[string]interface{}{}
+	m := map[string]interface{}{}
+	bs, _ = json.Marshal(defKindList)
+	_ = json.Unmarshal(bs, &m)
+	if o.Full {
+		dataOutput = m
+	} else {
+		dataOutput = pkg.RemoveMapEmptyItems(m)
+	}
+
+	switch o.Output {
+	case "json":
+		bs, _ := json.MarshalIndent(dataOutput, "", "  ")
+		fmt.Println(string(bs))
+	case "yaml":
+		bs, _ := pkg.YamlIndent(dataOutput)
+		fmt.Println(string(bs))
+	}
+
+	if !o.Try {
+		err = o.ApplyRenameDefUpdates(defUpdates)
+		if err != nil {
+			return err
+		}
+		log.Success(fmt.Sprintf("rename module %s to %s in project %s success", o.Param.OldModuleName, o.Param.NewModuleName, o.Param.ProjectName))
+	}
+
+	return err
+}
+
+// ApplyRenameDefUpdates apply the renamed definitions in order, if a definition failed,
+// report the applied and not applied definitions, and the commands to resume or revert the rename
+func (o *OptionsDefRename) ApplyRenameDefUpdates(defUpdates []pkg.DefUpdate) error {
+	var err error
+	c := o.Client()
+	for i, defUpdate := range defUpdates {
+		logHeader := client.DefUpdateHeader(defUpdate)
+		msg, err := c.ApplyDef(defUpdate)
+		if err != nil {
+			err = fmt.Errorf("%s: %s", logHeader, err.Error())
+			if i == 0 {
+				return err
+			}
+			for _, du := range defUpdates[:i] {
+				log.Warning(fmt.Sprintf("applied: %s", DefUpdatePath(du)))
+			}
+			for _, du := range defUpdates[i:] {
+				log.Warning(fmt.Sprintf("not applied: %s", DefUpdatePath(du)))
+			}
+			log.Warning(fmt.Sprintf("rename partially applied, %d of %d definitions applied", i, len(defUpdates)))
+			log.Warning(fmt.Sprintf("resume the rename: doryctl def rename %s %s %s", o.Param.ProjectName, o.Param.OldModuleName, o.Param.NewModuleName))
+			log.Warning(fmt.Sprintf("revert the rename: doryctl def rename %s %s %s", o.Param.ProjectName, o.Param.NewModuleName, o.Param.OldModuleName))
+			return err
+		}
+		log.Info(fmt.Sprintf("%s: %s", logHeader, msg))
+	}
+	return err
+}
+
+// RenameModule rename the module and the references to it in all project definitions,
+// return the changed project with update flags and the rename plan,
+// error if the module not exists or newName already exists in the same definition
+func RenameModule(project pkg.ProjectOutput, oldName, newName string) (pkg.ProjectOutput, []string, error) {
+	var err error
+	plan := []string{}
+	projectName := project.ProjectInfo.ProjectName
+	modulePath := func(kind, envName, stepName, branchName, moduleName string) string {
+		defUpdate := pkg.DefUpdate{
+			Kind:           kind,
+			ProjectName:    projectName,
+			EnvName:        envName,
+			CustomStepName: stepName,
+			BranchName:     branchName,
+		}
+		return DefModulePath(defUpdate, moduleName)
+	}
+	// check the module names in the same definition, return true if oldName exists
+	checkNames := func(kind, envName, stepName string, names []string) (bool, error) {
+		var hasOld, hasNew bool
+		for _, name := range names {
+			if name == oldName {
+				hasOld = true
+			}
+			if name == newName {
+				hasNew = true
+			}
+		}
+		if hasOld && hasNew {
+			return false, fmt.Errorf("rename module %s to %s error: %s already exists", oldName, newName, modulePath(kind, envName, stepName, "", newName))
+		}
+		return hasOld, nil
+	}
+	renameNames := func(names []string) bool {
+		var changed bool
+		for i, name := range names {
+			if name == oldName {
+				names[i] = newName
+				changed = true
+			}
+		}
+		return changed
+	}
+	renameCustomStepDefs := func(envName string, csds pkg.CustomStepDefs) error {
+		stepNames := []string{}
+		for stepName := range csds {
+			stepNames = append(stepNames, stepName)
+		}
+		sort.Strings(stepNames)
+		for _, stepName := range stepNames {
+			csd := csds[stepName]
+			names := []string{}
+			for _, csmd := range csd.CustomStepModuleDefs {
+				names = append(names, csmd.ModuleName)
+			}
+			found, err := checkNames("customStepDef", envName, stepName, names)
+			if err != nil {
+				return err
+			}
+			var changed bool
+			for i, csmd := range csd.CustomStepModuleDefs {
+				if found && csmd.ModuleName == oldName {
+					csd.CustomStepModuleDefs[i].ModuleName = newName
+					changed = true
+					plan = append(plan, fmt.Sprintf("rename %s: moduleName to %s", modulePath("customStepDef", envName, stepName, "", oldName), newName))
+				}
+				if renameNames(csd.CustomStepModuleDefs[i].RelatedStepModules) {
+					changed = true
+					plan = append(plan, fmt.Sprintf("update %s: relatedStepModules %s to %s", modulePath("customStepDef", envName, stepName, "", csd.CustomStepModuleDefs[i].ModuleName), oldName, newName))
+				}
+			}
+			if changed {
+				csd.UpdateCustomStepModuleDefs = true
+				csds[stepName] = csd
+			}
+		}
+		return nil
+	}
+
+	names := []string{}
+	for _, def := range project.ProjectDef.BuildDefs {
+		names = append(names, def.BuildName)
+	}
+	found, err := checkNames("buildDefs", "", "", names)
+	if err != nil {
+		return project, plan, err
+	}
+	if found {
+		for i, def := range project.ProjectDef.BuildDefs {
+			if def.BuildName == oldName {
+				project.ProjectDef.BuildDefs[i].BuildName = newName
+			}
+		}
+		project.ProjectDef.UpdateBuildDefs = true
+		plan = append(plan, fmt.Sprintf("rename %s: buildName to %s", modulePath("buildDefs", "", "", "", oldName), newName))
+	}
+
+	names = []string{}
+	for _, def := range project.ProjectDef.PackageDefs {
+		names = append(names, def.PackageName)
+	}
+	found, err = checkNames("packageDefs", "", "", names)
+	if err != nil {
+		return project, plan, err
+	}
+	for i, def := range project.ProjectDef.PackageDefs {
+		if found && def.PackageName == oldName {
+			project.ProjectDef.PackageDefs[i].PackageName = newName
+			project.ProjectDef.UpdatePackageDefs = true
+			plan = append(plan, fmt.Sprintf("rename %s: packageName to %s", modulePath("packageDefs", "", "", "", oldName), newName))
+		}
+		if renameNames(project.ProjectDef.PackageDefs[i].RelatedBuilds) {
+			project.ProjectDef.UpdatePackageDefs = true
+			plan = append(plan, fmt.Sprintf("update %s: relatedBuilds %s to %s", modulePath("packageDefs", "", "", "", project.ProjectDef.PackageDefs[i].PackageName), oldName, newName))
+		}
+	}
+
+	for i, pae := range project.ProjectAvailableEnvs {
+		names = []string{}
+		for _, def := range pae.DeployContainerDefs {
+			names = append(names, def.DeployName)
+		}
+		found, err = checkNames("deployContainerDefs", pae.EnvName, "", names)
+		if err != nil {
+			return project, plan, err
+		}
+		for j, def := range pae.DeployContainerDefs {
+			if found && def.DeployName == oldName {
+				project.ProjectAvailableEnvs[i].DeployContainerDefs[j].DeployName = newName
+				project.ProjectAvailableEnvs[i].UpdateDeployContainerDefs = true
+				plan = append(plan, fmt.Sprintf("rename %s: deployName to %s", modulePath("deployContainerDefs", pae.EnvName, "", "", oldName), newName))
+			}
+			deployName := project.ProjectAvailableEnvs[i].DeployContainerDefs[j].DeployName
+			if def.RelatedPackage == oldName {
+				project.ProjectAvailableEnvs[i].DeployContainerDefs[j].RelatedPackage = newName
+				project.ProjectAvailableEnvs[i].UpdateDeployContainerDefs = true
+				plan = append(plan, fmt.Sprintf("update %s: relatedPackage %s to %s", modulePath("deployContainerDefs", pae.EnvName, "", "", deployName), oldName, newName))
+			}
+			for k, ds := range def.DependServices {
+				if ds.DependName == oldName {
+					project.ProjectAvailableEnvs[i].DeployContainerDefs[j].DependServices[k].DependName = newName
+					project.ProjectAvailableEnvs[i].UpdateDeployContainerDefs = true
+					plan = append(plan, fmt.Sprintf("update %s: dependServices %s to %s", modulePath("deployContainerDefs", pae.EnvName, "", "", deployName), oldName, newName))
+				}
+			}
+		}
+		err = renameCustomStepDefs(pae.EnvName, pae.CustomStepDefs)
+		if err != nil {
+			return project, plan, err
+		}
+	}
+
+	err = renameCustomStepDefs("", project.ProjectDef.CustomStepDefs)
+	if err != nil {
+		return project, plan, err
+	}
+
+	for i, pp := range project.ProjectPipelines {
+		for j, build := range pp.PipelineDef.Builds {
+			if build.Name == oldName {
+				project.ProjectPipelines[i].PipelineDef.Builds[j].Name = newName
+				project.ProjectPipelines[i].UpdatePipelineDef = true
+				plan = append(plan, fmt.Sprintf("update %s: builds %s to %s", modulePath("pipelineDef", "", "", pp.BranchName, ""), oldName, newName))
+			}
+		}
+	}
+
+	if len(plan) == 0 {
+		err = fmt.Errorf("module %s not exists in project %s definitions", oldName, projectName)
+		return project, plan, err
+	}
+
+	return project, plan, err
+}
@@ -0,0 +1,400 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strings"
+)
+
+type OptionsDefSync struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	FileNames      []string `yaml:"fileNames" json:"fileNames" bson:"fileNames" validate:""`
+	Recursive      bool     `yaml:"recursive" json:"recursive" bson:"recursive" validate:""`
+	Prune          bool     `yaml:"prune" json:"prune" bson:"prune" validate:""`
+	Selector       string   `yaml:"selector" json:"selector" bson:"selector" validate:""`
+	DryRun         bool     `yaml:"dryRun" json:"dryRun" bson:"dryRun" validate:""`
+	Unified        int      `yaml:"unified" json:"unified" bson:"unified" validate:""`
+	Param          struct {
+		FileNames []string      `yaml:"fileNames" json:"fileNames" bson:"fileNames" validate:""`
+		Defs      []pkg.DefKind `yaml:"defs" json:"defs" bson:"defs" validate:""`
+		Selector  pkg.Selector  `yaml:"selector" json:"selector" bson:"selector" validate:""`
+	}
+}
+
+func NewOptionsDefSync() *OptionsDefSync {
+	var o OptionsDefSync
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdDefSync() *cobra.Command {
+	o := NewOptionsDefSync()
+
+	msgUse := fmt.Sprintf(`sync -f [filename] [--prune] [--dry-run]`)
+	msgShort := fmt.Sprintf("sync project definitions files to dory-core server")
+	msgLong := fmt.Sprintf(`sync project definitions in dory-core server with project definitions files, files are the desired state.
+# it will update or insert project definitions items which are different from dory-core server, unchanged definitions are not applied.
+# with --prune, modules exist in dory-core server but not in files will be deleted,
+# prune only touches the definitions (kind / env / step) which metadata labels or annotations match --selector,
+# default selector is %s, example:
+#   kind: buildDefs
+#   metadata:
+#     projectName: test-project1
+#     annotations:
+#       dory-engine.io/managed-by: doryctl
+# JSON and YAML formats are accepted, the same as def apply.`, pkg.DefManagedSelectorDefault)
+	msgExample := fmt.Sprintf(`  # sync project definitions from directory recursively
+  doryctl def sync -f defs/ -r
+
+  # sync project definitions from directory, and delete modules not in files
+  doryctl def sync -f defs/ -r --prune
+
+  # show the changes will be applied and the modules will be deleted, but not apply them
+  doryctl def sync -f defs/ -r --prune --dry-run
+
+  # sync project definitions and prune the definitions match the selector
+  doryctl def sync -f defs/ -r --prune --selector=envName=test`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().BoolVarP(&o.Recursive, "recursive", "r", false, "process the directory used in -f, --files recursively")
+	cmd.Flags().StringSliceVarP(&o.FileNames, "files", "f", []string{}, "project definitions file name or directory, support *.json and *.yaml and *.yml files")
+	cmd.Flags().BoolVar(&o.Prune, "prune", false, "delete modules exist in dory-core server but not in files, only for definitions match --selector")
+	cmd.Flags().StringVar(&o.Selector, "selector", pkg.DefManagedSelectorDefault, "definitions metadata labels or annotations selector to prune, format: key1=value1,key2!=value2,key3 in (value3,value4)")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", false, "show the changes will be applied in unified diff format, but not apply them")
+	cmd.Flags().IntVarP(&o.Unified, "unified", "U", 3, "number of context lines around the differences, use with --dry-run option")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsDefSync) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.MarkFlagRequired("files")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsDefSync) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if o.Unified < 0 {
+		err = fmt.Errorf("--unified must be greater than or equal to 0")
+		return err
+	}
+
+	o.Param.Selector, err = pkg.ParseSelector(o.Selector)
+	if err != nil {
+		err = fmt.Errorf("--selector %s format error: %s", o.Selector, err.Error())
+		return err
+	}
+	if o.Prune && len(o.Param.Selector) == 0 {
+		err = fmt.Errorf("--selector required when use --prune")
+		return err
+	}
+
+	o.Param.FileNames, o.Param.Defs, err = GetDefKindsFromFiles(o.FileNames, o.Recursive)
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+// MatchModuleName check the module name is in moduleNames and match the module name pattern, empty moduleNames match all module names
+func MatchModuleName(moduleName string, moduleNames []string, pattern pkg.NamePattern) bool {
+	if !pattern.Match(moduleName) {
+		return false
+	}
+	if len(moduleNames) == 0 {
+		return true
+	}
+	for _, name := range moduleNames {
+		if name == moduleName {
+			return true
+		}
+	}
+	return false
+}
+
+// DefKindModuleNames return the module names of definitions items
+func DefKindModuleNames(def pkg.DefKind) []string {
+	moduleNames := []string{}
+	for _, item := range def.Items {
+		var moduleName string
+		bs, _ := pkg.YamlIndent(item)
+		switch def.Kind {
+		case "buildDefs":
+			var d pkg.BuildDef
+			_ = yaml.Unmarshal(bs, &d)
+			moduleName = d.BuildName
+		case "packageDefs":
+			var d pkg.PackageDef
+			_ = yaml.Unmarshal(bs, &d)
+			moduleName = d.PackageName
+		case "deployContainerDefs":
+			var d pkg.DeployContainerDef
+			_ = yaml.Unmarshal(bs, &d)
+			moduleName = d.DeployName
+		case "customOpsDefs":
+			var d pkg.CustomOpsDef
+			_ = yaml.Unmarshal(bs, &d)
+			moduleName = d.CustomOpsName
+		case "customStepDef":
+			var d pkg.CustomStepModuleDef
+			_ = yaml.Unmarshal(bs, &d)
+			moduleName = d.ModuleName
+		}
+		if moduleName != "" {
+			moduleNames = append(moduleNames, moduleName)
+		}
+	}
+	return moduleNames
+}
+
+// PruneDefKinds delete modules not in defs from project, defs are the complete desired modules of each kind / env / step,
+// pipelineDef and dockerIgnoreDefs have no modules, they are replaced by def apply already.
+// return the pruned project and the paths of deleted modules
+func PruneDefKinds(project pkg.ProjectOutput, defs []pkg.DefKind) (pkg.ProjectOutput, []string) {
+	pruned := []string{}
+
+	// merge module names of the same kind / env / step in different files
+	type scope struct {
+		kind     string
+		envName  string
+		stepName string
+		names    map[string]bool
+	}
+	scopes := map[string]*scope{}
+	keys := []string{}
+	for _, def := range defs {
+		switch def.Kind {
+		case "buildDefs", "packageDefs", "deployContainerDefs", "customOpsDefs", "customStepDef":
+		default:
+			continue
+		}
+		s := scope{
+			kind:     def.Kind,
+			envName:  def.Metadata.Labels["envName"],
+			stepName: def.Metadata.Labels["stepName"],
+			names:    map[string]bool{},
+		}
+		key := fmt.Sprintf("%s/%s/%s", s.kind, s.envName, s.stepName)
+		if _, ok := scopes[key]; !ok {
+			scopes[key] = &s
+			keys = append(keys, key)
+		}
+		for _, name := range DefKindModuleNames(def) {
+			scopes[key].names[name] = true
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := scopes[key]
+		items := []string{project.ProjectInfo.ProjectName, s.kind}
+		for _, item := range []string{s.envName, s.stepName} {
+			if item != "" {
+				items = append(items, item)
+			}
+		}
+		scopePath := strings.Join(items, "/")
+
+		switch s.kind {
+		case "buildDefs":
+			defs := []pkg.BuildDef{}
+			for _, def := range project.ProjectDef.BuildDefs {
+				if s.names[def.BuildName] {
+					defs = append(defs, def)
+				} else {
+					pruned = append(pruned, fmt.Sprintf("%s/%s", scopePath, def.BuildName))
+					project.ProjectDef.UpdateBuildDefs = true
+				}
+			}
+			project.ProjectDef.BuildDefs = defs
+		case "packageDefs":
+			defs := []pkg.PackageDef{}
+			for _, def := range project.ProjectDef.PackageDefs {
+				if s.names[def.PackageName] {
+					defs = append(defs, def)
+				} else {
+					pruned = append(pruned, fmt.Sprintf("%s/%s", scopePath, def.PackageName))
+					project.ProjectDef.UpdatePackageDefs = true
+				}
+			}
+			project.ProjectDef.PackageDefs = defs
+		case "customOpsDefs":
+			defs := []pkg.CustomOpsDef{}
+			for _, def := range project.ProjectDef.CustomOpsDefs {
+				if s.names[def.CustomOpsName] {
+					defs = append(defs, def)
+				} else {
+					pruned = append(pruned, fmt.Sprintf("%s/%s", scopePath, def.CustomOpsName))
+					project.ProjectDef.UpdateCustomOpsDefs = true
+				}
+			}
+			project.ProjectDef.CustomOpsDefs = defs
+		case "deployContainerDefs":
+			for i, pae := range project.ProjectAvailableEnvs {
+				if pae.EnvName != s.envName {
+					continue
+				}
+				defs := []pkg.DeployContainerDef{}
+				for _, def := range pae.DeployContainerDefs {
+					if s.names[def.DeployName] {
+						defs = append(defs, def)
+					} else {
+						pruned = append(pruned, fmt.Sprintf("%s/%s", scopePath, def.DeployName))
+						pae.UpdateDeployContainerDefs = true
+					}
+				}
+				pae.DeployContainerDefs = defs
+				project.ProjectAvailableEnvs[i] = pae
+			}
+		case "customStepDef":
+			csds := project.ProjectDef.CustomStepDefs
+			if s.envName != "" {
+				csds = pkg.CustomStepDefs{}
+				for _, pae := range project.ProjectAvailableEnvs {
+					if pae.EnvName == s.envName {
+						csds = pae.CustomStepDefs
+						break
+					}
+				}
+			}
+			csd, ok := csds[s.stepName]
+			if !ok {
+				continue
+			}
+			defs := []pkg.CustomStepModuleDef{}
+			for _, def := range csd.CustomStepModuleDefs {
+				if s.names[def.ModuleName] {
+					defs = append(defs, def)
+				} else {
+					pruned = append(pruned, fmt.Sprintf("%s/%s", scopePath, def.ModuleName))
+					csd.UpdateCustomStepModuleDefs = true
+				}
+			}
+			csd.CustomStepModuleDefs = defs
+			csds[s.stepName] = csd
+		}
+	}
+	return project, pruned
+}
+
+func (o *OptionsDefSync) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	mapDefProjects := map[string][]pkg.DefKind{}
+	projectNames := []string{}
+	for _, def := range o.Param.Defs {
+		if _, ok := mapDefProjects[def.Metadata.ProjectName]; !ok {
+			projectNames = append(projectNames, def.Metadata.ProjectName)
+		}
+		mapDefProjects[def.Metadata.ProjectName] = append(mapDefProjects[def.Metadata.ProjectName], def)
+	}
+	sort.Strings(projectNames)
+
+	defUpdates := []pkg.DefUpdate{}
+	var pruneCount int
+	for _, projectName := range projectNames {
+		defs := mapDefProjects[projectName]
+		project, err := o.GetProjectDef(projectName)
+		if err != nil {
+			return err
+		}
+		desiredProject, err := CopyProjectOutput(project)
+		if err != nil {
+			return err
+		}
+		desiredProject, err = MergeDefKinds(desiredProject, defs)
+		if err != nil {
+			return err
+		}
+
+		if o.Prune {
+			managedDefs := []pkg.DefKind{}
+			for _, def := range defs {
+				if o.Param.Selector.Match(def.Metadata) {
+					managedDefs = append(managedDefs, def)
+				}
+			}
+			if len(managedDefs) == 0 {
+				log.Warning(fmt.Sprintf("project %s no definitions match selector %s, nothing to prune", projectName, o.Selector))
+			}
+			var pruned []string
+			desiredProject, pruned = PruneDefKinds(desiredProject, managedDefs)
+			for _, s := range pruned {
+				log.Info(fmt.Sprintf("%s will be pruned", s))
+			}
+			pruneCount = pruneCount + len(pruned)
+		}
+
+		updates := GetDefUpdates(desiredProject)
+		SortDefUpdates(updates)
+		for _, defUpdate := range updates {
+			current := GetProjectDefUpdate(project, defUpdate)
+			diffs, err := DiffDefUpdate(current, defUpdate, o.Unified)
+			if err != nil {
+				return err
+			}
+			if len(diffs) == 0 {
+				log.Debug(fmt.Sprintf("%s not changed, skip", DefUpdatePath(defUpdate)))
+				continue
+			}
+			if o.DryRun {
+				for _, diff := range diffs {
+					fmt.Println(ColorDiff(diff))
+				}
+			}
+			defUpdates = append(defUpdates, defUpdate)
+		}
+	}
+
+	if len(defUpdates) == 0 {
+		log.Success("project definitions are the same as dory-core server, nothing to sync")
+		return err
+	}
+
+	if o.DryRun {
+		log.Info(fmt.Sprintf("dry run: %d definitions will be applied, %d modules will be pruned", len(defUpdates), pruneCount))
+		return err
+	}
+
+	err = o.ApplyDefUpdates(defUpdates)
+	if err != nil {
+		return err
+	}
+	log.Success(fmt.Sprintf("sync project definitions success, %d definitions applied, %d modules pruned", len(defUpdates), pruneCount))
+
+	return err
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
 	"golang.org/x/crypto/ssh/terminal"
-	"net/http"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -31,7 +31,9 @@ func NewCmdLogin() *cobra.Command {
 
 	msgUse := fmt.Sprintf("login")
 	msgShort := fmt.Sprintf("login to dory-core server")
-	msgLong := fmt.Sprintf("login first before use doryctl to control your dory-core server, it will save dory-core server settings in doryctl config file")
+	msgLong := fmt.Sprintf(`login first before use doryctl to control your dory-core server, it will save dory-core server settings as a context in doryctl config file and switch to this context
+# the context is the context set by --context, or the context with the same serverURL, or a new context named by the serverURL hostname
+# a new context only use the settings set by flags or the default settings, settings of the current context are not inherited`)
 	msgExample := fmt.Sprintf(`  # login with username and password input prompt
   doryctl login --serverURL http://dory.example.com:8080
 
@@ -39,7 +41,10 @@ func NewCmdLogin() *cobra.Command {
   doryctl login --serverURL http://dory.example.com:8080 --username test-user
 
   # login without input prompt
-  doryctl login --serverURL http://dory.example.com:8080 --username test-user --password xxx`)
+  doryctl login --serverURL http://dory.example.com:8080 --username test-user --password xxx
+
+  # login and save dory-core server settings as context production
+  doryctl login --serverURL http://dory.example.com:8080 --context production`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -74,6 +79,7 @@ func (o *OptionsLogin) Complete(cmd *cobra.Command) error {
 func (o *OptionsLogin) Validate(args []string) error {
 	var err error
 
+	o.allowNewContext = true
 	err = o.GetOptionsCommon()
 	if err != nil {
 		return err
@@ -96,6 +102,32 @@ func (o *OptionsLogin) Validate(args []string) error {
 		return err
 	}
 
+	// login to the context with the same serverURL, or a new context named by serverURL hostname,
+	// reload options from the login context, values not set by flags are not inherited from the current context
+	if o.Context == "" {
+		doryConfig, err := o.ReadDoryConfig()
+		if err != nil {
+			return err
+		}
+		for _, doryContext := range doryConfig.Contexts {
+			if doryContext.ServerURL == o.ServerURL {
+				o.Context = doryContext.Name
+				break
+			}
+		}
+		if o.Context == "" {
+			o.Context = pkg.ContextNameDefault
+			u, err := url.Parse(o.ServerURL)
+			if err == nil && u.Hostname() != "" {
+				o.Context = u.Hostname()
+			}
+		}
+		err = o.GetOptionsCommon()
+		if err != nil {
+			return err
+		}
+	}
+
 	return err
 }
 
@@ -131,46 +163,47 @@ func (o *OptionsLogin) Run(args []string) error {
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{
-		"username": o.Username,
-		"password": o.Password,
-	}
-	_, xUserToken, err := o.QueryAPI("api/public/login", http.MethodPost, "", param, true)
+	c := o.Client()
+	xUserToken, err := c.Login(o.Username, o.Password)
 	if err != nil {
 		return err
 	}
 
 	accessTokenName := fmt.Sprintf("doryctl-%s", time.Now().Format("20060102030405"))
-	param = map[string]interface{}{
-		"accessTokenName": accessTokenName,
-		"expireDays":      o.ExpireDays,
-	}
-	result, _, err := o.QueryAPI("api/account/accessToken", http.MethodPost, xUserToken, param, true)
+	accessToken, err := c.CreateAccessToken(xUserToken, accessTokenName, o.ExpireDays)
 	if err != nil {
+		err = fmt.Errorf("get accessToken error: %s", err.Error())
 		return err
 	}
-	accessToken := result.Get("data.accessToken").String()
-	if accessToken == "" {
-		err = fmt.Errorf("get accessToken error: accessToken is empty")
+	accessTokenBase64 := base64.StdEncoding.EncodeToString([]byte(accessToken))
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
 		return err
 	}
-	accessTokenBase64 := base64.StdEncoding.EncodeToString([]byte(accessToken))
-	o.AccessToken = accessTokenBase64
-	doryConfig := pkg.DoryConfig{
-		ServerURL:   o.ServerURL,
-		Insecure:    o.Insecure,
-		Timeout:     o.Timeout,
-		AccessToken: o.AccessToken,
-		Language:    o.Language,
-	}
-	bs, _ = pkg.YamlIndent(doryConfig)
-	err = os.WriteFile(o.ConfigFile, bs, 0600)
+	contextName := o.Context
+	doryContext := pkg.DoryContext{
+		Name:                 contextName,
+		ServerURL:            o.ServerURL,
+		Insecure:             o.Insecure,
+		CertificateAuthority: AbsPath(o.CertificateAuthority),
+		ClientCertificate:    AbsPath(o.ClientCertificate),
+		ClientKey:            AbsPath(o.ClientKey),
+		Timeout:              o.Timeout,
+		Retries:              &o.Retries,
+		RetryMaxWait:         o.RetryMaxWait,
+		RetryMethods:         o.RetryMethods,
+		AccessToken:          accessTokenBase64,
+		Language:             o.Language,
+	}
+	doryConfig.SetContext(doryContext)
+	doryConfig.CurrentContext = contextName
+	err = o.WriteDoryConfig(doryConfig)
 	if err != nil {
 		return err
 	}
 
-	log.Success("login success")
-	log.Debug(fmt.Sprintf("update %s success", o.ConfigFile))
+	log.Success(fmt.Sprintf("login success, switched to context %s", contextName))
 
 	return err
 }
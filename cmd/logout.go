@@ -2,9 +2,7 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 type OptionsLogout struct {
@@ -22,9 +20,12 @@ func NewCmdLogout() *cobra.Command {
 
 	msgUse := fmt.Sprintf("logout")
 	msgShort := fmt.Sprintf("logout from dory-core server")
-	msgLong := fmt.Sprintf("it will clear dory-core server settings from doryctl config file")
+	msgLong := fmt.Sprintf("it will clear dory-core server access token of current context from doryctl config file")
 	msgExample := fmt.Sprintf(`  # logout from dory-core server
-  doryctl logout`)
+  doryctl logout
+
+  # logout from dory-core server of context production
+  doryctl logout --context production`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -71,21 +72,25 @@ func (o *OptionsLogout) Validate(args []string) error {
 
 func (o *OptionsLogout) Run(args []string) error {
 	var err error
-	doryConfig := pkg.DoryConfig{
-		ServerURL:   "",
-		Insecure:    o.Insecure,
-		Timeout:     o.Timeout,
-		AccessToken: "",
-		Language:    o.Language,
+
+	doryConfig, err := o.ReadDoryConfig()
+	if err != nil {
+		return err
+	}
+	contextName := o.ContextName(doryConfig)
+	doryContext, idx := doryConfig.GetContext(contextName)
+	if idx < 0 {
+		err = fmt.Errorf("context %s not exists", contextName)
+		return err
 	}
-	bs, _ := pkg.YamlIndent(doryConfig)
-	err = os.WriteFile(o.ConfigFile, bs, 0600)
+	doryContext.AccessToken = ""
+	doryConfig.SetContext(doryContext)
+	err = o.WriteDoryConfig(doryConfig)
 	if err != nil {
 		return err
 	}
 
-	log.Success("logout success")
-	log.Debug(fmt.Sprintf("update %s success", o.ConfigFile))
+	log.Success(fmt.Sprintf("logout context %s success", contextName))
 
 	return err
 }
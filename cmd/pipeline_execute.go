@@ -2,19 +2,27 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
-	"net/http"
 	"strings"
+	"time"
 )
 
 type OptionsPipelineExecute struct {
 	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
 	Batch          string `yaml:"batch" json:"batch" bson:"batch" validate:""`
+	Wait           bool   `yaml:"wait" json:"wait" bson:"wait" validate:""`
+	RunTimeout     int    `yaml:"runTimeout" json:"runTimeout" bson:"runTimeout" validate:""`
+	Detach         bool   `yaml:"detach" json:"detach" bson:"detach" validate:""`
+	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	InputsFile     string `yaml:"inputsFile" json:"inputsFile" bson:"inputsFile" validate:""`
+	InputsDefault  string `yaml:"inputsDefault" json:"inputsDefault" bson:"inputsDefault" validate:""`
 	Param          struct {
-		PipelineName string   `yaml:"pipelineName" json:"pipelineName" bson:"pipelineName" validate:""`
-		Batches      []string `yaml:"Batches" json:"Batches" bson:"Batches" validate:""`
+		PipelineName string    `yaml:"pipelineName" json:"pipelineName" bson:"pipelineName" validate:""`
+		Batches      []string  `yaml:"Batches" json:"Batches" bson:"Batches" validate:""`
+		RunInputs    RunInputs `yaml:"runInputs" json:"runInputs" bson:"runInputs" validate:""`
 	}
 }
 
@@ -29,12 +37,38 @@ func NewCmdPipelineExecute() *cobra.Command {
 
 	msgUse := fmt.Sprintf("execute [pipelineName]")
 	msgShort := fmt.Sprintf("execute pipeline")
-	msgLong := fmt.Sprintf(`execute pipeline in dory-core server`)
+	msgLong := fmt.Sprintf(`execute pipeline in dory-core server
+# with --wait or --run-timeout, wait until the pipeline run finished, process exit codes:
+#   %d: pipeline run status is SUCCESS
+#   %d: command error
+#   %d: pipeline run status is FAIL
+#   %d: pipeline run status is ABORT
+#   %d: pipeline run not finished before --run-timeout, the pipeline run is aborted
+#   %d: with --output, pipeline run is waiting for input not answered by --batch, --inputs-file or --inputs-default
+# with --output, logs are not shown, inputs are answered by --batch, --inputs-file or --inputs-default, prompts are printed to stderr.`, pkg.ExitCodeSuccess, pkg.ExitCodeError, pkg.ExitCodeFail, pkg.ExitCodeAbort, pkg.ExitCodeTimeout, pkg.ExitCodeInput)
 	msgExample := fmt.Sprintf(`  # execute pipeline
   doryctl pipeline execute test-project1-develop
 
   # execute pipeline with batch input automatically
-  doryctl pipeline execute test-project1-ops --batch "develop::inputCheckDeploy::tp1-gin-demo,tp1-go-demo"`)
+  doryctl pipeline execute test-project1-ops --batch "develop::inputCheckDeploy::tp1-gin-demo,tp1-go-demo"
+
+  # execute pipeline with input answers file, abort the pipeline run if an input not answered
+  doryctl pipeline execute test-project1-develop --inputs-file answers.yaml --inputs-default abort
+
+  # execute pipeline and wait until the pipeline run finished, exit code is not 0 if the pipeline run not succeeded
+  doryctl pipeline execute test-project1-develop --wait
+
+  # execute pipeline, abort the pipeline run if not finished in 30 minutes
+  doryctl pipeline execute test-project1-develop --run-timeout 1800
+
+  # execute pipeline and wait without logs, print the pipeline run summary in JSON format
+  doryctl pipeline execute test-project1-develop --wait -o json
+
+  # execute pipeline and wait without logs, choose the first option if an input not answered in file
+  doryctl pipeline execute test-project1-develop --wait -o json --inputs-file answers.yaml --inputs-default first
+
+  # execute pipeline and print the runName only, not wait for the pipeline run
+  doryctl pipeline execute test-project1-develop --detach`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -48,6 +82,12 @@ func NewCmdPipelineExecute() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&o.Batch, "batch", "b", "", "send input in run automatically, input values split with ::, example: develop::inputCheckDeploy::tp1-gin-demo,tp1-go-demo")
+	cmd.Flags().BoolVar(&o.Wait, "wait", false, "wait until the pipeline run finished, exit code is not 0 if the pipeline run not succeeded")
+	cmd.Flags().IntVar(&o.RunTimeout, "run-timeout", 0, "wait seconds for the pipeline run, abort the pipeline run if not finished in time, 0 means no timeout, it implies --wait")
+	cmd.Flags().BoolVar(&o.Detach, "detach", false, "print the runName and exit, not show the pipeline run logs")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "print the pipeline run summary in output format without logs, use with --wait or --detach option (options: yaml / json)")
+	cmd.Flags().StringVar(&o.InputsFile, "inputs-file", "", "answer the pipeline run inputs by phaseID or title from YAML file")
+	cmd.Flags().StringVar(&o.InputsDefault, "inputs-default", "", fmt.Sprintf("policy for the pipeline run inputs not answered, override the unanswered policy in inputs file (options: %s / %s / %s)", pkg.InputPolicyPrompt, pkg.InputPolicyAbort, pkg.InputPolicyFirst))
 
 	CheckError(o.Complete(cmd))
 	return cmd
@@ -72,6 +112,20 @@ func (o *OptionsPipelineExecute) Complete(cmd *cobra.Command) error {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("inputs-default", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{pkg.InputPolicyPrompt, pkg.InputPolicyAbort, pkg.InputPolicyFirst}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
 	return err
 }
 
@@ -105,46 +159,237 @@ func (o *OptionsPipelineExecute) Validate(args []string) error {
 			o.Param.Batches = append(o.Param.Batches, val)
 		}
 	}
+
+	if o.RunTimeout < 0 {
+		err = fmt.Errorf("--run-timeout must greater than or equal to 0")
+		return err
+	}
+	if o.RunTimeout > 0 {
+		o.Wait = true
+	}
+	if o.Detach && o.Wait {
+		err = fmt.Errorf("--detach can not use with --wait or --run-timeout")
+		return err
+	}
+	if o.Detach && len(o.Param.Batches) > 0 {
+		err = fmt.Errorf("--detach can not use with --batch")
+		return err
+	}
+	o.Param.RunInputs = RunInputs{Batches: o.Param.Batches, Unanswered: o.InputsDefault}
+	if o.InputsFile != "" || o.InputsDefault != "" {
+		if o.Detach {
+			err = fmt.Errorf("--inputs-file and --inputs-default can not use with --detach")
+			return err
+		}
+		if len(o.Param.Batches) > 0 {
+			err = fmt.Errorf("--inputs-file and --inputs-default can not use with --batch")
+			return err
+		}
+		o.Param.RunInputs, err = GetRunInputs(o.InputsFile, o.InputsDefault)
+		if err != nil {
+			return err
+		}
+	}
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" {
+			err = fmt.Errorf("--output must be yaml or json")
+			return err
+		}
+		if !o.Wait && !o.Detach {
+			err = fmt.Errorf("--output must use with --wait or --detach")
+			return err
+		}
+	}
+	return err
+}
+
+// WaitRun wait until the pipeline run finished by polling the pipeline run status,
+// return timedOut true if the pipeline run not finished before deadline, deadline is zero means no timeout,
+// the pending inputs are answered by runInputs if runInputs is not nil, prompt only with the prompt policy,
+// otherwise return ExitError with ExitCodeInput if an input not answered
+func (o *OptionsCommon) WaitRun(runName string, deadline time.Time, runInputs *RunInputs) (pkg.Run, bool, error) {
+	var err error
+	var run pkg.Run
+	c := o.Client()
+	answered := map[string]bool{}
+	for {
+		run, err = c.GetRun(runName)
+		if err != nil {
+			return run, false, err
+		}
+		if IsRunFinished(run.Status.Result) {
+			return run, false, err
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return run, true, err
+		}
+		if runInputs != nil {
+			pendings, err := o.GetPendingRunInputs([]pkg.Run{run})
+			if err != nil {
+				return run, false, err
+			}
+			if runInput, ok := pendings[runName]; ok && !answered[runInput.PhaseID] {
+				err = o.answerRunInput(runName, runInput, runInputs, runInputs.Unanswered == pkg.InputPolicyPrompt)
+				if err != nil {
+					return run, false, err
+				}
+				answered[runInput.PhaseID] = true
+			}
+		}
+		time.Sleep(time.Second * 2)
+	}
+}
+
+// GetRunSummary return the pipeline run summary and process exit code of the pipeline run status
+func GetRunSummary(run pkg.Run, timedOut bool) pkg.RunSummary {
+	summary := pkg.RunSummary{
+		RunName:      run.RunName,
+		ProjectName:  run.ProjectName,
+		PipelineName: run.PipelineName,
+		StartUser:    run.StartUser,
+		AbortUser:    run.AbortUser,
+		Result:       run.Status.Result,
+		StartTime:    run.Status.StartTime,
+		Duration:     run.Status.Duration,
+		TimedOut:     timedOut,
+	}
+	switch {
+	case timedOut:
+		summary.ExitCode = pkg.ExitCodeTimeout
+	case run.Status.Result == pkg.StatusSuccess:
+		summary.ExitCode = pkg.ExitCodeSuccess
+	case run.Status.Result == pkg.StatusFail:
+		summary.ExitCode = pkg.ExitCodeFail
+	case run.Status.Result == pkg.StatusAbort:
+		summary.ExitCode = pkg.ExitCodeAbort
+	default:
+		summary.ExitCode = pkg.ExitCodeError
+	}
+	return summary
+}
+
+// PrintRunSummary print the pipeline run summary in output format, return ExitError if the pipeline run not succeeded
+func PrintRunSummary(summary pkg.RunSummary, output string) error {
+	var err error
+	msg := fmt.Sprintf("pipeline run %s finished, status: %s, duration: %s", summary.RunName, summary.Result, summary.Duration)
+	if summary.TimedOut {
+		msg = fmt.Sprintf("pipeline run %s timeout, status: %s", summary.RunName, summary.Result)
+	}
+	switch output {
+	case "json":
+		bs, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(bs))
+	case "yaml":
+		bs, _ := pkg.YamlIndent(summary)
+		fmt.Println(string(bs))
+	default:
+		if summary.ExitCode == pkg.ExitCodeSuccess {
+			log.Success(msg)
+		}
+	}
+
+	if summary.ExitCode != pkg.ExitCodeSuccess {
+		err = &ExitError{Code: summary.ExitCode, Err: errors.New(msg)}
+		return err
+	}
 	return err
 }
 
 func (o *OptionsPipelineExecute) Run(args []string) error {
 	var err error
 
+	// only the pipeline run summary is printed to stdout with --output
+	if o.Output != "" {
+		log.SetStderr(true)
+	}
+
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/pipeline/%s", o.Param.PipelineName), http.MethodPost, "", param, false)
+	c := o.Client()
+	runName, err := c.ExecutePipeline(o.Param.PipelineName)
 	if err != nil {
 		return err
 	}
-	runName := result.Get("data.runName").String()
-	if runName == "" {
-		err = fmt.Errorf("runName is empty")
-		return err
-	}
 
-	result, _, err = o.QueryAPI(fmt.Sprintf("api/cicd/run/%s", runName), http.MethodGet, "", param, false)
+	run, err := c.GetRun(runName)
 	if err != nil {
 		return err
 	}
-	run := pkg.Run{}
-	err = json.Unmarshal([]byte(result.Get("data.run").Raw), &run)
-	if err != nil {
+
+	if o.Detach {
+		if o.Output == "" {
+			fmt.Println(runName)
+			return err
+		}
+		// the pipeline run is not finished in detach mode, exit code is always success
+		summary := GetRunSummary(run, false)
+		summary.ExitCode = pkg.ExitCodeSuccess
+		err = PrintRunSummary(summary, o.Output)
 		return err
 	}
 
-	if run.RunName == "" {
-		err = fmt.Errorf("runName %s not exists", runName)
+	var deadline time.Time
+	if o.RunTimeout > 0 {
+		deadline = time.Now().Add(time.Second * time.Duration(o.RunTimeout))
+	}
+
+	if o.Output == "" {
+		url := fmt.Sprintf("api/ws/log/run/%s", runName)
+		if deadline.IsZero() {
+			err = o.QueryWebsocketRunLog(url, runName, &o.Param.RunInputs, nil, func(msg pkg.WsRunLog) error {
+				log.RunLog(msg)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		} else {
+			chErr := make(chan error, 1)
+			stop := make(chan struct{})
+			go func() {
+				chErr <- o.QueryWebsocketRunLog(url, runName, &o.Param.RunInputs, stop, func(msg pkg.WsRunLog) error {
+					log.RunLog(msg)
+					return nil
+				})
+			}()
+			select {
+			case err = <-chErr:
+				if err != nil {
+					return err
+				}
+			case <-time.After(time.Until(deadline)):
+				// stop the logs and inputs prompt before abort the pipeline run
+				close(stop)
+			}
+		}
+	}
+
+	if !o.Wait {
 		return err
 	}
 
-	url := fmt.Sprintf("api/ws/log/run/%s", runName)
-	err = o.QueryWebsocket(url, runName, o.Param.Batches)
+	// inputs are answered by the websocket logs without --output
+	var runInputs *RunInputs
+	if o.Output != "" {
+		runInputs = &o.Param.RunInputs
+	}
+	run, timedOut, err := o.WaitRun(runName, deadline, runInputs)
 	if err != nil {
 		return err
 	}
+	if timedOut {
+		log.Warning(fmt.Sprintf("pipeline run %s not finished in %d seconds, abort it", runName, o.RunTimeout))
+		_, err = c.AbortRun(runName)
+		if err != nil {
+			return err
+		}
+		run, err = c.GetRun(runName)
+		if err != nil {
+			return err
+		}
+	}
 
+	err = PrintRunSummary(GetRunSummary(run, timedOut), o.Output)
 	return err
 }
@@ -1,23 +1,22 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
-	"github.com/olekukonko/tablewriter"
+	"github.com/dory-engine/dory-ctl/pkg/printer"
 	"github.com/spf13/cobra"
-	"net/http"
 	"os"
 	"strings"
 )
 
 type OptionsPipelineGet struct {
 	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
-	ProjectNames   string `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
-	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	ProjectNames   string          `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
+	PrinterOptions printer.Options `yaml:"printerOptions" json:"printerOptions" bson:"printerOptions" validate:""`
 	Param          struct {
-		ProjectNames  []string `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
-		PipelineNames []string `yaml:"pipelineNames" json:"pipelineNames" bson:"pipelineNames" validate:""`
+		ProjectNames  []string         `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
+		PipelineNames []string         `yaml:"pipelineNames" json:"pipelineNames" bson:"pipelineNames" validate:""`
+		Printer       *printer.Printer `yaml:"-" json:"-" bson:"-" validate:""`
 	}
 }
 
@@ -40,7 +39,13 @@ func NewCmdPipelineGet() *cobra.Command {
   doryctl pipeline get test-project1-develop
 
   # get multiple pipeline resources
-  doryctl pipeline get test-project1-develop test-project1-ops`)
+  doryctl pipeline get test-project1-develop test-project1-ops
+
+  # get pipeline resources of project, sorted by success count
+  doryctl pipeline get --projects=test-project1 --sort-by=.successCount
+
+  # get pipelineNames and branchNames without table headers
+  doryctl pipeline get -o custom-columns=NAME:.pipelineName,BRANCH:.branchName --no-headers`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -54,7 +59,7 @@ func NewCmdPipelineGet() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&o.ProjectNames, "projects", "p", "", "filters by projectNames, example: test-project1,test-project2")
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+	AddPrinterFlags(cmd, &o.PrinterOptions)
 
 	CheckError(o.Complete(cmd))
 	return cmd
@@ -90,9 +95,7 @@ func (o *OptionsPipelineGet) Complete(cmd *cobra.Command) error {
 		return err
 	}
 
-	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
-	})
+	err = RegisterPrinterFlagCompletion(cmd)
 	if err != nil {
 		return err
 	}
@@ -132,11 +135,9 @@ func (o *OptionsPipelineGet) Validate(args []string) error {
 			o.Param.ProjectNames = append(o.Param.ProjectNames, s)
 		}
 	}
-	if o.Output != "" {
-		if o.Output != "yaml" && o.Output != "json" {
-			err = fmt.Errorf("--output must be yaml or json")
-			return err
-		}
+	o.Param.Printer, err = printer.NewPrinter(o.PrinterOptions)
+	if err != nil {
+		return err
 	}
 	return err
 }
@@ -147,27 +148,10 @@ func (o *OptionsPipelineGet) Run(args []string) error {
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{
-		"projectNames": o.Param.ProjectNames,
-		"page":         1,
-		"perPage":      1000,
-	}
-	result, _, err := o.QueryAPI("api/cicd/projects", http.MethodPost, "", param, false)
+	pipelines, err := o.Client().ListPipelines(o.Param.ProjectNames)
 	if err != nil {
 		return err
 	}
-	rs := result.Get("data.projects").Array()
-	pipelines := []pkg.Pipeline{}
-	for _, r := range rs {
-		project := pkg.Project{}
-		err = json.Unmarshal([]byte(r.Raw), &project)
-		if err != nil {
-			return err
-		}
-		for _, pipeline := range project.Pipelines {
-			pipelines = append(pipelines, pipeline)
-		}
-	}
 
 	if len(pipelines) > 0 {
 		if len(o.Param.PipelineNames) > 0 {
@@ -183,54 +167,48 @@ func (o *OptionsPipelineGet) Run(args []string) error {
 			}
 		}
 
+		p := o.Param.Printer
+		err = p.Sort(pipelines)
+		if err != nil {
+			return err
+		}
 		dataOutput := map[string]interface{}{}
 		if len(o.Param.PipelineNames) == 1 && len(pipelines) == 1 && o.Param.PipelineNames[0] == pipelines[0].PipelineName {
 			dataOutput["pipeline"] = pipelines[0]
 		} else {
 			dataOutput["pipelines"] = pipelines
 		}
-		switch o.Output {
-		case "json":
-			bs, _ = json.MarshalIndent(dataOutput, "", "  ")
-			fmt.Println(string(bs))
-		case "yaml":
-			bs, _ = pkg.YamlIndent(dataOutput)
-			fmt.Println(string(bs))
-		default:
-			data := [][]string{}
-			for _, pipeline := range pipelines {
-				pipelineName := pipeline.PipelineName
-				branchName := pipeline.BranchName
-				envs := strings.Join(pipeline.Envs, ",")
-				envProds := strings.Join(pipeline.EnvProductions, ",")
-				successCount := fmt.Sprintf("%d", pipeline.SuccessCount)
-				failCount := fmt.Sprintf("%d", pipeline.FailCount)
-				abortCount := fmt.Sprintf("%d", pipeline.AbortCount)
-				var statusResult string
-				if pipeline.Status.StartTime != "" {
-					statusResult = pipeline.Status.StartTime
-					if pipeline.Status.Result != "" {
-						statusResult = fmt.Sprintf("%s [%s]", statusResult, pipeline.Status.Result)
-					}
+
+		table := printer.Table{
+			Headers:     []string{"Name", "Branch", "Envs", "EnvProds", "Success", "Fail", "Abort", "LastRun"},
+			WideHeaders: []string{"Duration"},
+		}
+		for _, pipeline := range pipelines {
+			pipelineName := pipeline.PipelineName
+			branchName := pipeline.BranchName
+			envs := strings.Join(pipeline.Envs, ",")
+			envProds := strings.Join(pipeline.EnvProductions, ",")
+			successCount := fmt.Sprintf("%d", pipeline.SuccessCount)
+			failCount := fmt.Sprintf("%d", pipeline.FailCount)
+			abortCount := fmt.Sprintf("%d", pipeline.AbortCount)
+			var statusResult string
+			if pipeline.Status.StartTime != "" {
+				statusResult = pipeline.Status.StartTime
+				if pipeline.Status.Result != "" {
+					statusResult = fmt.Sprintf("%s [%s]", statusResult, pipeline.Status.Result)
 				}
-				data = append(data, []string{pipelineName, branchName, envs, envProds, successCount, failCount, abortCount, statusResult})
 			}
+			table.Rows = append(table.Rows, printer.Row{
+				Name:      fmt.Sprintf("pipeline/%s", pipelineName),
+				Cells:     []string{pipelineName, branchName, envs, envProds, successCount, failCount, abortCount, statusResult},
+				WideCells: []string{pipeline.Status.Duration},
+				Object:    pipeline,
+			})
+		}
 
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"Name", "Branch", "Envs", "EnvProds", "Success", "Fail", "Abort", "LastRun"})
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(data)
-			table.Render()
+		err = p.Print(os.Stdout, dataOutput, []printer.Table{table})
+		if err != nil {
+			return err
 		}
 	}
 
@@ -14,7 +14,16 @@ func NewCmdProject() *cobra.Command {
   doryctl project get
 
   # create a new project with flags, admin permission required
-  doryctl project add apply --name=test-project1 --desc=TEST-PROJECT1 --short=tp1 --team=TP --env=test`)
+  doryctl project add apply --name=test-project1 --desc=TEST-PROJECT1 --short=tp1 --team=TP --env=test
+
+  # export project metadata and all definitions to directory
+  doryctl project export test-project1 -o backup/test-project1
+
+  # import project metadata and all definitions from directory
+  doryctl project import backup/test-project1
+
+  # check project definitions cross references
+  doryctl project check test-project1`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -32,5 +41,8 @@ func NewCmdProject() *cobra.Command {
 
 	cmd.AddCommand(NewCmdProjectGet())
 	cmd.AddCommand(NewCmdProjectAdd())
+	cmd.AddCommand(NewCmdProjectExport())
+	cmd.AddCommand(NewCmdProjectImport())
+	cmd.AddCommand(NewCmdProjectCheck())
 	return cmd
 }
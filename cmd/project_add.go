@@ -7,7 +7,6 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -369,22 +368,10 @@ func (o *OptionsProjectAdd) Run(args []string) error {
 		for _, pa := range o.Param.ProjectAdds {
 			log.Info(fmt.Sprintf("##############################"))
 			log.Info(fmt.Sprintf("# start to create project %s", pa.ProjectName))
-			param := map[string]interface{}{
-				"projectName":      pa.ProjectName,
-				"projectDesc":      pa.ProjectDesc,
-				"projectShortName": pa.ProjectShortName,
-				"projectTeam":      pa.ProjectTeam,
-				"envName":          pa.EnvName,
-			}
-			result, _, err := o.QueryAPI("api/admin/project", http.MethodPost, "", param, false)
+			auditID, err := o.Client().AddProject(pa)
 			if err != nil {
 				return err
 			}
-			auditID := result.Get("data.auditID").String()
-			if auditID == "" {
-				err = fmt.Errorf("can not get auditID")
-				return err
-			}
 
 			url := fmt.Sprintf("api/ws/log/audit/admin/%s", auditID)
 			err = o.QueryWebsocket(url, "", []string{})
@@ -0,0 +1,473 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/dory-engine/dory-ctl/pkg/printer"
+	"github.com/spf13/cobra"
+	"os"
+	"sort"
+	"strings"
+)
+
+type OptionsProjectCheck struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Severity       string          `yaml:"severity" json:"severity" bson:"severity" validate:""`
+	PrinterOptions printer.Options `yaml:"printerOptions" json:"printerOptions" bson:"printerOptions" validate:""`
+	Param          struct {
+		ProjectName string           `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+		Printer     *printer.Printer `yaml:"-" json:"-" bson:"-" validate:""`
+	}
+}
+
+func NewOptionsProjectCheck() *OptionsProjectCheck {
+	var o OptionsProjectCheck
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdProjectCheck() *cobra.Command {
+	o := NewOptionsProjectCheck()
+
+	msgUse := fmt.Sprintf(`check [projectName] [--severity=error|warning|info]`)
+	msgShort := fmt.Sprintf("check project definitions cross references")
+	msgLong := fmt.Sprintf(`check all definitions of project in dory-core server, report dangling references and conflicts with severity levels.
+# error: packageDefs relatedBuilds, deployContainerDefs relatedPackage and pipelineDef builds refer to modules not exist,
+#   pipelineDef envs, customStepInsertDefs and customStepDefs refer to environments or custom steps not exist,
+#   customStepModuleDefs relatedStepModules refer to modules not exist, nodePorts outside project nodePorts ranges,
+#   duplicate nodePorts in the same environment, definitions error messages from dory-core server
+# warning: the same nodePort used by different modules in different environments, deploy modules missing in some environments,
+#   customStepPhaseDefs refer to custom steps not exist
+# info: the same nodePort used by a module in multiple environments
+# exit code is %d if errors found.`, pkg.ExitCodeFail)
+	msgExample := fmt.Sprintf(`  # check project definitions cross references
+  doryctl project check test-project1
+
+  # check project definitions, show errors only
+  doryctl project check test-project1 --severity=error
+
+  # check project definitions, output problems in json format
+  doryctl project check test-project1 -o json`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringVar(&o.Severity, "severity", pkg.SeverityInfo, fmt.Sprintf("minimum severity of problems to show (options: %s / %s / %s)", pkg.SeverityError, pkg.SeverityWarning, pkg.SeverityInfo))
+	AddPrinterFlags(cmd, &o.PrinterOptions)
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsProjectCheck) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			projectNames, err := o.GetProjectNames()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return projectNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("severity", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{pkg.SeverityError, pkg.SeverityWarning, pkg.SeverityInfo}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = RegisterPrinterFlagCompletion(cmd)
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsProjectCheck) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		err = fmt.Errorf("projectName required")
+		return err
+	}
+	o.Param.ProjectName = args[0]
+	err = pkg.ValidateMinusNameID(o.Param.ProjectName)
+	if err != nil {
+		err = fmt.Errorf("projectName %s format error: %s", o.Param.ProjectName, err.Error())
+		return err
+	}
+
+	if SeverityRank(o.Severity) < 0 {
+		err = fmt.Errorf("--severity must be %s, %s or %s", pkg.SeverityError, pkg.SeverityWarning, pkg.SeverityInfo)
+		return err
+	}
+
+	o.Param.Printer, err = printer.NewPrinter(o.PrinterOptions)
+	if err != nil {
+		return err
+	}
+	return err
+}
+
+// SeverityRank return the rank of the severity, error is 0, -1 if the severity is not correct
+func SeverityRank(severity string) int {
+	for i, s := range []string{pkg.SeverityError, pkg.SeverityWarning, pkg.SeverityInfo} {
+		if s == severity {
+			return i
+		}
+	}
+	return -1
+}
+
+// projectCheckNodePort is a nodePort used by a deploy module in an environment
+type projectCheckNodePort struct {
+	envName    string
+	deployName string
+}
+
+// CheckProject check the cross references of all definitions in project, projectNodePorts are the nodePorts ranges of project,
+// nodePorts ranges are not checked if projectNodePorts is empty, issues are sorted by severity
+func CheckProject(project pkg.ProjectOutput, projectNodePorts []pkg.ProjectNodePort) []pkg.ProjectCheckIssue {
+	issues := []pkg.ProjectCheckIssue{}
+	addIssue := func(severity, rule, resource, envName, msg string) {
+		issues = append(issues, pkg.ProjectCheckIssue{
+			Severity: severity,
+			Rule:     rule,
+			Resource: resource,
+			EnvName:  envName,
+			Message:  msg,
+		})
+	}
+
+	buildNames := map[string]bool{}
+	for _, d := range project.ProjectDef.BuildDefs {
+		buildNames[d.BuildName] = true
+	}
+	packageNames := map[string]bool{}
+	for _, d := range project.ProjectDef.PackageDefs {
+		packageNames[d.PackageName] = true
+	}
+	envNames := []string{}
+	deployNames := map[string]bool{}
+	for _, pae := range project.ProjectAvailableEnvs {
+		envNames = append(envNames, pae.EnvName)
+		for _, d := range pae.DeployContainerDefs {
+			deployNames[d.DeployName] = true
+		}
+	}
+	stepNames := map[string]bool{}
+	for _, conf := range project.CustomStepConfs {
+		stepNames[conf.CustomStepName] = true
+	}
+	isEnvName := func(envName string) bool {
+		for _, name := range envNames {
+			if name == envName {
+				return true
+			}
+		}
+		return false
+	}
+	isModuleName := func(name string) bool {
+		return buildNames[name] || packageNames[name] || deployNames[name]
+	}
+
+	// error messages from dory-core server
+	if project.ProjectDef.ErrMsgPackageDefs != "" {
+		addIssue(pkg.SeverityError, pkg.CheckRuleServerError, "packageDefs", "", project.ProjectDef.ErrMsgPackageDefs)
+	}
+	if project.ProjectDef.ErrMsgCustomOpsDefs != "" {
+		addIssue(pkg.SeverityError, pkg.CheckRuleServerError, "customOpsDefs", "", project.ProjectDef.ErrMsgCustomOpsDefs)
+	}
+	for stepName, errMsg := range project.ProjectDef.ErrMsgCustomStepDefs {
+		if errMsg != "" {
+			addIssue(pkg.SeverityError, pkg.CheckRuleServerError, fmt.Sprintf("customStepDef/%s", stepName), "", errMsg)
+		}
+	}
+	for _, pae := range project.ProjectAvailableEnvs {
+		if pae.ErrMsgDeployContainerDefs != "" {
+			addIssue(pkg.SeverityError, pkg.CheckRuleServerError, "deployContainerDefs", pae.EnvName, pae.ErrMsgDeployContainerDefs)
+		}
+		for stepName, errMsg := range pae.ErrMsgCustomStepDefs {
+			if errMsg != "" {
+				addIssue(pkg.SeverityError, pkg.CheckRuleServerError, fmt.Sprintf("customStepDef/%s", stepName), pae.EnvName, errMsg)
+			}
+		}
+	}
+	for _, pp := range project.ProjectPipelines {
+		if pp.ErrMsgPipelineDef != "" {
+			addIssue(pkg.SeverityError, pkg.CheckRuleServerError, fmt.Sprintf("pipelineDef/%s", pp.BranchName), "", pp.ErrMsgPipelineDef)
+		}
+	}
+
+	// packageDefs relatedBuilds
+	for _, d := range project.ProjectDef.PackageDefs {
+		for _, name := range d.RelatedBuilds {
+			if !buildNames[name] {
+				addIssue(pkg.SeverityError, pkg.CheckRuleReference, fmt.Sprintf("packageDefs/%s", d.PackageName), "", fmt.Sprintf("relatedBuilds %s not exists in buildDefs", name))
+			}
+		}
+	}
+
+	// pipelineDef builds, envs and custom steps
+	for _, pp := range project.ProjectPipelines {
+		resource := fmt.Sprintf("pipelineDef/%s", pp.BranchName)
+		for _, build := range pp.PipelineDef.Builds {
+			if !buildNames[build.Name] {
+				addIssue(pkg.SeverityError, pkg.CheckRuleReference, resource, "", fmt.Sprintf("builds %s not exists in buildDefs", build.Name))
+			}
+		}
+		for _, envName := range pp.Envs {
+			if !isEnvName(envName) {
+				addIssue(pkg.SeverityError, pkg.CheckRuleReference, resource, "", fmt.Sprintf("envs %s not exists in projectAvailableEnvs", envName))
+			}
+		}
+		for _, envName := range pp.EnvProductions {
+			if !isEnvName(envName) {
+				addIssue(pkg.SeverityError, pkg.CheckRuleReference, resource, "", fmt.Sprintf("envProductions %s not exists in projectAvailableEnvs", envName))
+			}
+		}
+		insertKeys := []string{}
+		for key := range pp.PipelineDef.CustomStepInsertDefs {
+			insertKeys = append(insertKeys, key)
+		}
+		sort.Strings(insertKeys)
+		for _, key := range insertKeys {
+			for _, stepName := range pp.PipelineDef.CustomStepInsertDefs[key] {
+				if !stepNames[stepName] {
+					addIssue(pkg.SeverityError, pkg.CheckRuleReference, resource, "", fmt.Sprintf("customStepInsertDefs.%s %s not exists in customStepConfs", key, stepName))
+				}
+			}
+		}
+		phaseKeys := []string{}
+		for key := range pp.PipelineDef.CustomStepPhaseDefs {
+			phaseKeys = append(phaseKeys, key)
+		}
+		sort.Strings(phaseKeys)
+		for _, stepName := range phaseKeys {
+			if !stepNames[stepName] {
+				addIssue(pkg.SeverityWarning, pkg.CheckRuleReference, resource, "", fmt.Sprintf("customStepPhaseDefs %s not exists in customStepConfs", stepName))
+			}
+		}
+	}
+
+	// customStepDefs steps and relatedStepModules
+	checkCustomStepDefs := func(envName string, csds pkg.CustomStepDefs) {
+		keys := []string{}
+		for key := range csds {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, stepName := range keys {
+			resource := fmt.Sprintf("customStepDef/%s", stepName)
+			if !stepNames[stepName] {
+				addIssue(pkg.SeverityError, pkg.CheckRuleReference, resource, envName, fmt.Sprintf("custom step %s not exists in customStepConfs", stepName))
+			}
+			for _, csmd := range csds[stepName].CustomStepModuleDefs {
+				for _, name := range csmd.RelatedStepModules {
+					if !isModuleName(name) {
+						addIssue(pkg.SeverityError, pkg.CheckRuleReference, resource, envName, fmt.Sprintf("%s relatedStepModules %s not exists in project modules", csmd.ModuleName, name))
+					}
+				}
+			}
+		}
+	}
+	checkCustomStepDefs("", project.ProjectDef.CustomStepDefs)
+	for _, pae := range project.ProjectAvailableEnvs {
+		checkCustomStepDefs(pae.EnvName, pae.CustomStepDefs)
+	}
+
+	// deployContainerDefs relatedPackage and nodePorts
+	nodePorts := map[int][]projectCheckNodePort{}
+	deployEnvNames := map[string][]string{}
+	for _, pae := range project.ProjectAvailableEnvs {
+		for _, d := range pae.DeployContainerDefs {
+			resource := fmt.Sprintf("deployContainerDefs/%s", d.DeployName)
+			deployEnvNames[d.DeployName] = append(deployEnvNames[d.DeployName], pae.EnvName)
+			if !packageNames[d.RelatedPackage] {
+				addIssue(pkg.SeverityError, pkg.CheckRuleReference, resource, pae.EnvName, fmt.Sprintf("relatedPackage %s not exists in packageDefs", d.RelatedPackage))
+			}
+			for _, np := range d.DeployNodePorts {
+				nodePorts[np.NodePort] = append(nodePorts[np.NodePort], projectCheckNodePort{envName: pae.EnvName, deployName: d.DeployName})
+				if len(projectNodePorts) == 0 {
+					continue
+				}
+				var inRange bool
+				ranges := []string{}
+				for _, pnp := range projectNodePorts {
+					ranges = append(ranges, fmt.Sprintf("%d-%d", pnp.NodePortStart, pnp.NodePortEnd))
+					if np.NodePort >= pnp.NodePortStart && np.NodePort <= pnp.NodePortEnd {
+						inRange = true
+					}
+				}
+				if !inRange {
+					addIssue(pkg.SeverityError, pkg.CheckRuleNodePort, resource, pae.EnvName, fmt.Sprintf("nodePort %d not in project nodePorts ranges %s", np.NodePort, strings.Join(ranges, ",")))
+				}
+			}
+		}
+	}
+
+	// nodePorts conflicts
+	ports := []int{}
+	for port := range nodePorts {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	for _, port := range ports {
+		users := nodePorts[port]
+		for i := 1; i < len(users); i++ {
+			// the same environment conflict is checked against all earlier users before the different environments warning
+			sameEnv, otherDeploy := -1, -1
+			for j := 0; j < i; j++ {
+				if users[j].envName == users[i].envName {
+					sameEnv = j
+					break
+				}
+				if otherDeploy < 0 && users[j].deployName != users[i].deployName {
+					otherDeploy = j
+				}
+			}
+			resource := fmt.Sprintf("deployContainerDefs/%s", users[i].deployName)
+			if sameEnv >= 0 {
+				addIssue(pkg.SeverityError, pkg.CheckRuleDuplicate, resource, users[i].envName, fmt.Sprintf("nodePort %d already used by %s in the same environment", port, users[sameEnv].deployName))
+			} else if otherDeploy >= 0 {
+				addIssue(pkg.SeverityWarning, pkg.CheckRuleDuplicate, resource, users[i].envName, fmt.Sprintf("nodePort %d also used by %s in environment %s", port, users[otherDeploy].deployName, users[otherDeploy].envName))
+			}
+		}
+		envNamesUsed := []string{}
+		for _, user := range users {
+			if user.deployName == users[0].deployName {
+				envNamesUsed = append(envNamesUsed, user.envName)
+			}
+		}
+		if len(envNamesUsed) > 1 && len(envNamesUsed) == len(users) {
+			addIssue(pkg.SeverityInfo, pkg.CheckRuleDuplicate, fmt.Sprintf("deployContainerDefs/%s", users[0].deployName), "", fmt.Sprintf("nodePort %d used in environments %s", port, strings.Join(envNamesUsed, ",")))
+		}
+	}
+
+	// deploy modules missing in some environments
+	names := []string{}
+	for name := range deployEnvNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		missingEnvNames := []string{}
+		for _, envName := range envNames {
+			var found bool
+			for _, s := range deployEnvNames[name] {
+				if s == envName {
+					found = true
+					break
+				}
+			}
+			if !found {
+				missingEnvNames = append(missingEnvNames, envName)
+			}
+		}
+		if len(missingEnvNames) > 0 {
+			addIssue(pkg.SeverityWarning, pkg.CheckRuleMissingEnv, fmt.Sprintf("deployContainerDefs/%s", name), "", fmt.Sprintf("module not defined in environments %s", strings.Join(missingEnvNames, ",")))
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return SeverityRank(issues[i].Severity) < SeverityRank(issues[j].Severity)
+	})
+	return issues
+}
+
+func (o *OptionsProjectCheck) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	project, err := o.Client().GetProjectDef(o.Param.ProjectName)
+	if err != nil {
+		return err
+	}
+	projects, err := o.Client().ListAllProjects([]string{o.Param.ProjectName}, "")
+	if err != nil {
+		return err
+	}
+	var projectNodePorts []pkg.ProjectNodePort
+	for _, p := range projects {
+		if p.ProjectInfo.ProjectName == o.Param.ProjectName {
+			projectNodePorts = p.ProjectNodePorts
+		}
+	}
+	p := o.Param.Printer
+	if len(projectNodePorts) == 0 && p.IsTable() {
+		log.Warning(fmt.Sprintf("project %s nodePorts ranges not found, skip nodePorts ranges check", o.Param.ProjectName))
+	}
+
+	issues := []pkg.ProjectCheckIssue{}
+	counts := map[string]int{}
+	for _, issue := range CheckProject(project, projectNodePorts) {
+		counts[issue.Severity]++
+		if SeverityRank(issue.Severity) <= SeverityRank(o.Severity) {
+			issues = append(issues, issue)
+		}
+	}
+
+	if len(issues) > 0 || !p.IsTable() {
+		err = p.Sort(issues)
+		if err != nil {
+			return err
+		}
+		dataOutput := map[string]interface{}{}
+		dataOutput["issues"] = issues
+		table := printer.Table{
+			Headers: []string{"Severity", "Rule", "Resource", "Env", "Message"},
+		}
+		for _, issue := range issues {
+			table.Rows = append(table.Rows, printer.Row{
+				Name:   issue.Resource,
+				Cells:  []string{issue.Severity, issue.Rule, issue.Resource, issue.EnvName, issue.Message},
+				Object: issue,
+			})
+		}
+		err = p.Print(os.Stdout, dataOutput, []printer.Table{table})
+		if err != nil {
+			return err
+		}
+	}
+
+	// only the issues are printed with --output, the output can be parsed
+	if !p.IsTable() {
+		if counts[pkg.SeverityError] > 0 {
+			err = &ExitError{Code: pkg.ExitCodeFail}
+		}
+		return err
+	}
+	msg := fmt.Sprintf("project %s check: %d errors, %d warnings, %d infos", o.Param.ProjectName, counts[pkg.SeverityError], counts[pkg.SeverityWarning], counts[pkg.SeverityInfo])
+	if counts[pkg.SeverityError] > 0 {
+		err = &ExitError{Code: pkg.ExitCodeFail, Err: fmt.Errorf("%s", msg)}
+		return err
+	}
+	log.Success(msg)
+
+	return err
+}
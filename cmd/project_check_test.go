@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"testing"
+)
+
+// checkProjectJSON return the project with one build b1 and one package p1, envs are the projectAvailableEnvs in json format
+func checkProjectJSON(envs string) string {
+	return fmt.Sprintf(`{
+  "projectDef": {
+    "buildDefs": [{"buildName": "b1"}],
+    "packageDefs": [{"packageName": "p1", "relatedBuilds": ["b1"]}]
+  },
+  "projectAvailableEnvs": %s
+}`, envs)
+}
+
+// checkDeployJSON return the deploy module related to package p1 with nodePorts in json format
+func checkDeployJSON(deployName string, nodePorts ...int) string {
+	s := ""
+	for i, nodePort := range nodePorts {
+		if i > 0 {
+			s = s + ","
+		}
+		s = s + fmt.Sprintf(`{"port": 8000, "nodePort": %d}`, nodePort)
+	}
+	return fmt.Sprintf(`{"deployName": "%s", "relatedPackage": "p1", "deployNodePorts": [%s]}`, deployName, s)
+}
+
+func TestCheckProject(t *testing.T) {
+	ranges := []pkg.ProjectNodePort{{NodePortStart: 30000, NodePortEnd: 30010}}
+	tests := []struct {
+		name   string
+		json   string
+		ranges []pkg.ProjectNodePort
+		want   []string
+	}{
+		{
+			name:   "no problems",
+			json:   checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s]}]`, checkDeployJSON("a", 30001))),
+			ranges: ranges,
+			want:   []string{},
+		},
+		{
+			name: "dangling references",
+			json: `{
+  "projectDef": {
+    "buildDefs": [{"buildName": "b1"}],
+    "packageDefs": [{"packageName": "p1", "relatedBuilds": ["b1", "b2"]}]
+  },
+  "projectAvailableEnvs": [{"envName": "test", "deployContainerDefs": [{"deployName": "a", "relatedPackage": "p2"}]}],
+  "pipelines": [{"branchName": "develop", "envs": ["test", "uat"], "pipelineDef": {"builds": [{"name": "b3"}], "customStepInsertDefs": {"build": ["scan"]}}}]
+}`,
+			want: []string{
+				"error reference packageDefs/p1 ",
+				"error reference pipelineDef/develop ",
+				"error reference pipelineDef/develop ",
+				"error reference pipelineDef/develop ",
+				"error reference deployContainerDefs/a test",
+			},
+		},
+		{
+			name:   "nodePort not in ranges",
+			json:   checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s]}]`, checkDeployJSON("a", 31000))),
+			ranges: ranges,
+			want:   []string{"error node-port deployContainerDefs/a test"},
+		},
+		{
+			name: "nodePort ranges not checked without ranges",
+			json: checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s]}]`, checkDeployJSON("a", 31000))),
+			want: []string{},
+		},
+		{
+			name: "nodePort used by a module in multiple environments",
+			json: checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s]}, {"envName": "uat", "deployContainerDefs": [%s]}]`,
+				checkDeployJSON("a", 30001), checkDeployJSON("a", 30001))),
+			want: []string{"info duplicate deployContainerDefs/a "},
+		},
+		{
+			name: "nodePort used twice in the same environment",
+			json: checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s, %s]}]`,
+				checkDeployJSON("a", 30001), checkDeployJSON("b", 30001))),
+			want: []string{"error duplicate deployContainerDefs/b test"},
+		},
+		{
+			name: "nodePort used by different modules in different environments",
+			json: checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s, %s]}, {"envName": "uat", "deployContainerDefs": [%s, %s]}]`,
+				checkDeployJSON("a", 30001), checkDeployJSON("b", 30002), checkDeployJSON("a", 30002), checkDeployJSON("b", 30001))),
+			want: []string{
+				"warning duplicate deployContainerDefs/b uat",
+				"warning duplicate deployContainerDefs/a uat",
+			},
+		},
+		{
+			name: "nodePort conflicts in the same environment after a different environment",
+			json: checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s]}, {"envName": "uat", "deployContainerDefs": [%s, %s]}]`,
+				checkDeployJSON("a", 30001), checkDeployJSON("b", 30001), checkDeployJSON("c", 30001))),
+			want: []string{
+				"error duplicate deployContainerDefs/c uat",
+				"warning duplicate deployContainerDefs/b uat",
+				"warning missing-env deployContainerDefs/a ",
+				"warning missing-env deployContainerDefs/b ",
+				"warning missing-env deployContainerDefs/c ",
+			},
+		},
+		{
+			name: "deploy module missing in environments",
+			json: checkProjectJSON(fmt.Sprintf(`[{"envName": "test", "deployContainerDefs": [%s, %s]}, {"envName": "uat", "deployContainerDefs": [%s]}]`,
+				checkDeployJSON("a"), checkDeployJSON("b"), checkDeployJSON("a"))),
+			want: []string{"warning missing-env deployContainerDefs/b "},
+		},
+		{
+			name: "server error messages",
+			json: `{"projectDef": {"errMsgPackageDefs": "package error"}, "pipelines": [{"branchName": "develop", "errMsgPipelineDef": "pipeline error"}]}`,
+			want: []string{
+				"error server-error packageDefs ",
+				"error server-error pipelineDef/develop ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var project pkg.ProjectOutput
+			err := json.Unmarshal([]byte(tt.json), &project)
+			if err != nil {
+				t.Fatalf("parse project error: %s", err.Error())
+			}
+			got := []string{}
+			for _, issue := range CheckProject(project, tt.ranges) {
+				got = append(got, fmt.Sprintf("%s %s %s %s", issue.Severity, issue.Rule, issue.Resource, issue.EnvName))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("CheckProject = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("CheckProject = %q, want %q", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type OptionsProjectExport struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	OutputDir      string `yaml:"outputDir" json:"outputDir" bson:"outputDir" validate:""`
+	Param          struct {
+		ProjectName string `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+	}
+}
+
+func NewOptionsProjectExport() *OptionsProjectExport {
+	var o OptionsProjectExport
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdProjectExport() *cobra.Command {
+	o := NewOptionsProjectExport()
+
+	msgUse := fmt.Sprintf(`export [projectName] -o [directory]`)
+	msgShort := fmt.Sprintf("export project metadata and all definitions to directory")
+	msgLong := fmt.Sprintf(`export project metadata and all project definitions in dory-core server to directory, for backup or migration.
+# directory layout:
+#   project.yaml: project metadata, environments and pipelines branches
+#   defs/buildDefs.yaml, defs/packageDefs.yaml, defs/customOpsDefs.yaml, defs/dockerIgnoreDefs.yaml
+#   defs/customStepDefs/[stepName].yaml
+#   defs/pipelineDefs/[branchName].yaml
+#   defs/envs/[envName]/deployContainerDefs.yaml
+#   defs/envs/[envName]/customStepDefs/[stepName].yaml
+# definitions files are the same format as def get output, can be applied by def apply -f [directory]/defs -r
+# use project import to recreate the project from the directory.`)
+	msgExample := fmt.Sprintf(`  # export project to directory
+  doryctl project export test-project1 -o backup/test-project1`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringVarP(&o.OutputDir, "output", "o", "", "directory to export project, existing export in this directory will be replaced")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsProjectExport) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			projectNames, err := o.GetProjectNames()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return projectNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.MarkFlagRequired("output")
+	if err != nil {
+		return err
+	}
+
+	err = cmd.MarkFlagDirname("output")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsProjectExport) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		err = fmt.Errorf("projectName required")
+		return err
+	}
+	o.Param.ProjectName = args[0]
+	err = pkg.ValidateMinusNameID(o.Param.ProjectName)
+	if err != nil {
+		err = fmt.Errorf("projectName %s format error: %s", o.Param.ProjectName, err.Error())
+		return err
+	}
+
+	if o.OutputDir == "" {
+		err = fmt.Errorf("--output required")
+		return err
+	}
+	infos, err := os.ReadDir(o.OutputDir)
+	if err != nil && !os.IsNotExist(err) {
+		err = fmt.Errorf("--output %s read error: %s", o.OutputDir, err.Error())
+		return err
+	}
+	err = nil
+	if len(infos) > 0 {
+		_, err = os.Stat(filepath.Join(o.OutputDir, pkg.ProjectExportFile))
+		if err != nil {
+			err = fmt.Errorf("--output %s is not empty and not a project export directory", o.OutputDir)
+			return err
+		}
+	}
+
+	return err
+}
+
+// GetProjectExport return project metadata, environments and pipelines branches of project
+func GetProjectExport(project pkg.ProjectOutput) pkg.ProjectExport {
+	projectExport := pkg.ProjectExport{
+		Kind: "project",
+		Metadata: pkg.DefMetadata{
+			ProjectName: project.ProjectInfo.ProjectName,
+		},
+		ProjectInfo: project.ProjectInfo,
+		EnvNames:    []string{},
+		Pipelines:   []pkg.ProjectExportPipeline{},
+	}
+	for _, pae := range project.ProjectAvailableEnvs {
+		projectExport.EnvNames = append(projectExport.EnvNames, pae.EnvName)
+	}
+	for _, pp := range project.ProjectPipelines {
+		pipeline := pkg.ProjectExportPipeline{
+			BranchName:       pp.BranchName,
+			IsDefault:        pp.IsDefault,
+			WebhookPushEvent: pp.WebhookPushEvent,
+			TagSuffix:        pp.TagSuffix,
+			Envs:             pp.Envs,
+			EnvProductions:   pp.EnvProductions,
+		}
+		projectExport.Pipelines = append(projectExport.Pipelines, pipeline)
+	}
+	return projectExport
+}
+
+// GetProjectDefKindFiles return all definitions of project as DefKind, the key is the relative file path in export directory,
+// modules are sorted by name to keep the export files stable
+func GetProjectDefKindFiles(project pkg.ProjectOutput) map[string]pkg.DefKind {
+	defKindFiles := map[string]pkg.DefKind{}
+	newDefKind := func(kind string, labels map[string]string) pkg.DefKind {
+		return pkg.DefKind{
+			Kind: kind,
+			Metadata: pkg.DefMetadata{
+				ProjectName: project.ProjectInfo.ProjectName,
+				Labels:      labels,
+			},
+			Items: []interface{}{},
+		}
+	}
+
+	if len(project.ProjectDef.BuildDefs) > 0 {
+		defs := append([]pkg.BuildDef{}, project.ProjectDef.BuildDefs...)
+		sort.SliceStable(defs, func(i, j int) bool {
+			return defs[i].BuildName < defs[j].BuildName
+		})
+		defKind := newDefKind("buildDefs", map[string]string{})
+		for _, def := range defs {
+			defKind.Items = append(defKind.Items, def)
+		}
+		defKindFiles["buildDefs.yaml"] = defKind
+	}
+
+	if len(project.ProjectDef.PackageDefs) > 0 {
+		defs := append([]pkg.PackageDef{}, project.ProjectDef.PackageDefs...)
+		sort.SliceStable(defs, func(i, j int) bool {
+			return defs[i].PackageName < defs[j].PackageName
+		})
+		defKind := newDefKind("packageDefs", map[string]string{})
+		for _, def := range defs {
+			defKind.Items = append(defKind.Items, def)
+		}
+		defKindFiles["packageDefs.yaml"] = defKind
+	}
+
+	for _, pae := range project.ProjectAvailableEnvs {
+		if len(pae.DeployContainerDefs) > 0 {
+			defs := append([]pkg.DeployContainerDef{}, pae.DeployContainerDefs...)
+			sort.SliceStable(defs, func(i, j int) bool {
+				return defs[i].DeployName < defs[j].DeployName
+			})
+			defKind := newDefKind("deployContainerDefs", map[string]string{
+				"envName": pae.EnvName,
+			})
+			for _, def := range defs {
+				defKind.Items = append(defKind.Items, def)
+			}
+			defKindFiles[filepath.Join("envs", pae.EnvName, "deployContainerDefs.yaml")] = defKind
+		}
+
+		for stepName, csd := range pae.CustomStepDefs {
+			defs := append([]pkg.CustomStepModuleDef{}, csd.CustomStepModuleDefs...)
+			sort.SliceStable(defs, func(i, j int) bool {
+				return defs[i].ModuleName < defs[j].ModuleName
+			})
+			defKind := newDefKind("customStepDef", map[string]string{
+				"envName":    pae.EnvName,
+				"stepName":   stepName,
+				"enableMode": csd.EnableMode,
+			})
+			for _, def := range defs {
+				defKind.Items = append(defKind.Items, def)
+			}
+			defKindFiles[filepath.Join("envs", pae.EnvName, "customStepDefs", fmt.Sprintf("%s.yaml", stepName))] = defKind
+		}
+	}
+
+	for stepName, csd := range project.ProjectDef.CustomStepDefs {
+		defs := append([]pkg.CustomStepModuleDef{}, csd.CustomStepModuleDefs...)
+		sort.SliceStable(defs, func(i, j int) bool {
+			return defs[i].ModuleName < defs[j].ModuleName
+		})
+		defKind := newDefKind("customStepDef", map[string]string{
+			"stepName":   stepName,
+			"enableMode": csd.EnableMode,
+		})
+		for _, def := range defs {
+			defKind.Items = append(defKind.Items, def)
+		}
+		defKindFiles[filepath.Join("customStepDefs", fmt.Sprintf("%s.yaml", stepName))] = defKind
+	}
+
+	for _, pp := range project.ProjectPipelines {
+		defKind := newDefKind("pipelineDef", map[string]string{
+			"branchName": pp.BranchName,
+		})
+		defKind.Items = append(defKind.Items, pp.PipelineDef)
+		defKindFiles[filepath.Join("pipelineDefs", fmt.Sprintf("%s.yaml", pp.BranchName))] = defKind
+	}
+
+	if len(project.ProjectDef.CustomOpsDefs) > 0 {
+		defs := append([]pkg.CustomOpsDef{}, project.ProjectDef.CustomOpsDefs...)
+		sort.SliceStable(defs, func(i, j int) bool {
+			return defs[i].CustomOpsName < defs[j].CustomOpsName
+		})
+		defKind := newDefKind("customOpsDefs", map[string]string{})
+		for _, def := range defs {
+			defKind.Items = append(defKind.Items, def)
+		}
+		defKindFiles["customOpsDefs.yaml"] = defKind
+	}
+
+	if len(project.ProjectDef.DockerIgnoreDefs) > 0 {
+		defKind := newDefKind("dockerIgnoreDefs", map[string]string{})
+		for _, def := range project.ProjectDef.DockerIgnoreDefs {
+			defKind.Items = append(defKind.Items, def)
+		}
+		defKindFiles["dockerIgnoreDefs.yaml"] = defKind
+	}
+
+	return defKindFiles
+}
+
+// WriteYamlFile write obj to yaml file without empty items, create the parent directory if not exists
+func WriteYamlFile(fileName string, obj interface{}) error {
+	var err error
+	m := map[string]interface{}{}
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(bs, &m)
+	if err != nil {
+		return err
+	}
+	bs, err = pkg.YamlIndent(pkg.RemoveMapEmptyItems(m))
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), 0755)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(fileName, bs, 0644)
+	if err != nil {
+		err = fmt.Errorf("write file %s error: %s", fileName, err.Error())
+		return err
+	}
+	return err
+}
+
+func (o *OptionsProjectExport) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	project, err := o.GetProjectDef(o.Param.ProjectName)
+	if err != nil {
+		return err
+	}
+
+	defsDir := filepath.Join(o.OutputDir, pkg.ProjectExportDefsDir)
+	err = os.RemoveAll(defsDir)
+	if err != nil {
+		err = fmt.Errorf("remove %s error: %s", defsDir, err.Error())
+		return err
+	}
+
+	fileName := filepath.Join(o.OutputDir, pkg.ProjectExportFile)
+	err = WriteYamlFile(fileName, GetProjectExport(project))
+	if err != nil {
+		return err
+	}
+	log.Info(fmt.Sprintf("export %s success", fileName))
+
+	defKindFiles := GetProjectDefKindFiles(project)
+	fileNames := []string{}
+	for name := range defKindFiles {
+		fileNames = append(fileNames, name)
+	}
+	sort.Strings(fileNames)
+	for _, name := range fileNames {
+		fileName := filepath.Join(defsDir, name)
+		err = WriteYamlFile(fileName, defKindFiles[name])
+		if err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("export %s success", fileName))
+	}
+
+	log.Success(fmt.Sprintf("export project %s to %s success, %d definitions files", o.Param.ProjectName, o.OutputDir, len(fileNames)))
+
+	return err
+}
@@ -1,22 +1,21 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
-	"github.com/olekukonko/tablewriter"
+	"github.com/dory-engine/dory-ctl/pkg/printer"
 	"github.com/spf13/cobra"
-	"net/http"
 	"os"
 	"strings"
 )
 
 type OptionsProjectGet struct {
 	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
-	ProjectTeam    string `yaml:"projectTeam" json:"projectTeam" bson:"projectTeam" validate:""`
-	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	ProjectTeam    string          `yaml:"projectTeam" json:"projectTeam" bson:"projectTeam" validate:""`
+	PrinterOptions printer.Options `yaml:"printerOptions" json:"printerOptions" bson:"printerOptions" validate:""`
 	Param          struct {
-		ProjectNames []string `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
+		ProjectNames []string         `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
+		Printer      *printer.Printer `yaml:"-" json:"-" bson:"-" validate:""`
 	}
 }
 
@@ -39,7 +38,16 @@ func NewCmdProjectGet() *cobra.Command {
   doryctl project get test-project1
 
   # get multiple project resources
-  doryctl project get test-project1 test-project2`)
+  doryctl project get test-project1 test-project2
+
+  # get project resources with team and description columns
+  doryctl project get -o wide
+
+  # get projectNames and their pipelines, sorted by projectName
+  doryctl project get -o custom-columns=NAME:.projectInfo.projectName,PIPELINES:.pipelines[*].pipelineName --sort-by=.projectInfo.projectName
+
+  # get projectNames only
+  doryctl project get -o jsonpath='{range .projects[*]}{.projectInfo.projectName}{"\n"}{end}'`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -53,7 +61,7 @@ func NewCmdProjectGet() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVar(&o.ProjectTeam, "team", "", "filters by projectTeam")
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+	AddPrinterFlags(cmd, &o.PrinterOptions)
 
 	CheckError(o.Complete(cmd))
 	return cmd
@@ -79,9 +87,7 @@ func (o *OptionsProjectGet) Complete(cmd *cobra.Command) error {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
-	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
-	})
+	err = RegisterPrinterFlagCompletion(cmd)
 	if err != nil {
 		return err
 	}
@@ -108,11 +114,9 @@ func (o *OptionsProjectGet) Validate(args []string) error {
 		o.Param.ProjectNames = append(o.Param.ProjectNames, s)
 	}
 
-	if o.Output != "" {
-		if o.Output != "yaml" && o.Output != "json" {
-			err = fmt.Errorf("--output must be yaml or json")
-			return err
-		}
+	o.Param.Printer, err = printer.NewPrinter(o.PrinterOptions)
+	if err != nil {
+		return err
 	}
 	return err
 }
@@ -123,81 +127,59 @@ func (o *OptionsProjectGet) Run(args []string) error {
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{
-		"projectNames": o.Param.ProjectNames,
-		"projectTeam":  o.ProjectTeam,
-		"page":         1,
-		"perPage":      1000,
-	}
-	result, _, err := o.QueryAPI("api/cicd/projects", http.MethodPost, "", param, false)
+	projects, err := o.Client().ListAllProjects(o.Param.ProjectNames, o.ProjectTeam)
 	if err != nil {
 		return err
 	}
-	rs := result.Get("data.projects").Array()
-	projects := []pkg.Project{}
-	for _, r := range rs {
-		project := pkg.Project{}
-		err = json.Unmarshal([]byte(r.Raw), &project)
+
+	if len(projects) > 0 {
+		p := o.Param.Printer
+		err = p.Sort(projects)
 		if err != nil {
 			return err
 		}
-		projects = append(projects, project)
-	}
-
-	if len(projects) > 0 {
 		dataOutput := map[string]interface{}{}
 		if len(o.Param.ProjectNames) == 1 && len(projects) == 1 && o.Param.ProjectNames[0] == projects[0].ProjectInfo.ProjectName {
 			dataOutput["project"] = projects[0]
 		} else {
 			dataOutput["projects"] = projects
 		}
-		switch o.Output {
-		case "json":
-			bs, _ = json.MarshalIndent(dataOutput, "", "  ")
-			fmt.Println(string(bs))
-		case "yaml":
-			bs, _ = pkg.YamlIndent(dataOutput)
-			fmt.Println(string(bs))
-		default:
-			data := [][]string{}
-			for _, project := range projects {
-				projectName := project.ProjectInfo.ProjectName
-				projectShortName := project.ProjectInfo.ProjectShortName
-				projectEnvs := []string{}
-				for _, pae := range project.ProjectAvailableEnvs {
-					projectEnvs = append(projectEnvs, pae.EnvName)
-				}
-				projectEnvNames := strings.Join(projectEnvs, ",")
-				projectNodePorts := []string{}
-				for _, pnp := range project.ProjectNodePorts {
-					np := fmt.Sprintf("%d-%d", pnp.NodePortStart, pnp.NodePortEnd)
-					projectNodePorts = append(projectNodePorts, np)
-				}
-				projectNodePortNames := strings.Join(projectNodePorts, ",")
-				pipelines := []string{}
-				for _, pp := range project.Pipelines {
-					pipelines = append(pipelines, pp.PipelineName)
-				}
-				pipelineNames := strings.Join(pipelines, ",")
-
-				data = append(data, []string{projectName, projectShortName, projectEnvNames, projectNodePortNames, pipelineNames})
+
+		table := printer.Table{
+			Headers:     []string{"Name", "ShortName", "EnvNames", "NodePorts", "Pipelines"},
+			WideHeaders: []string{"Team", "Desc"},
+		}
+		for _, project := range projects {
+			projectName := project.ProjectInfo.ProjectName
+			projectShortName := project.ProjectInfo.ProjectShortName
+			projectEnvs := []string{}
+			for _, pae := range project.ProjectAvailableEnvs {
+				projectEnvs = append(projectEnvs, pae.EnvName)
 			}
+			projectEnvNames := strings.Join(projectEnvs, ",")
+			projectNodePorts := []string{}
+			for _, pnp := range project.ProjectNodePorts {
+				np := fmt.Sprintf("%d-%d", pnp.NodePortStart, pnp.NodePortEnd)
+				projectNodePorts = append(projectNodePorts, np)
+			}
+			projectNodePortNames := strings.Join(projectNodePorts, ",")
+			pipelines := []string{}
+			for _, pp := range project.Pipelines {
+				pipelines = append(pipelines, pp.PipelineName)
+			}
+			pipelineNames := strings.Join(pipelines, ",")
+
+			table.Rows = append(table.Rows, printer.Row{
+				Name:      fmt.Sprintf("project/%s", projectName),
+				Cells:     []string{projectName, projectShortName, projectEnvNames, projectNodePortNames, pipelineNames},
+				WideCells: []string{project.ProjectInfo.ProjectTeam, project.ProjectInfo.ProjectDesc},
+				Object:    project,
+			})
+		}
 
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"Name", "ShortName", "EnvNames", "NodePorts", "Pipelines"})
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(data)
-			table.Render()
+		err = p.Print(os.Stdout, dataOutput, []printer.Table{table})
+		if err != nil {
+			return err
 		}
 	}
 
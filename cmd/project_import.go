@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type OptionsProjectImport struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	EnvName        string `yaml:"envName" json:"envName" bson:"envName" validate:""`
+	Try            bool   `yaml:"try" json:"try" bson:"try" validate:""`
+	SkipMissing    bool   `yaml:"skipMissing" json:"skipMissing" bson:"skipMissing" validate:""`
+	Param          struct {
+		Dir           string            `yaml:"dir" json:"dir" bson:"dir" validate:""`
+		ProjectExport pkg.ProjectExport `yaml:"projectExport" json:"projectExport" bson:"projectExport" validate:""`
+		FileNames     []string          `yaml:"fileNames" json:"fileNames" bson:"fileNames" validate:""`
+		Defs          []pkg.DefKind     `yaml:"defs" json:"defs" bson:"defs" validate:""`
+	}
+}
+
+func NewOptionsProjectImport() *OptionsProjectImport {
+	var o OptionsProjectImport
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdProjectImport() *cobra.Command {
+	o := NewOptionsProjectImport()
+
+	msgUse := fmt.Sprintf(`import [directory]`)
+	msgShort := fmt.Sprintf("import project metadata and all definitions from directory")
+	msgLong := fmt.Sprintf(`import project from the directory created by project export.
+# if the project not exists, create the project first in one environment (--env), admin permission required.
+# then apply all project definitions in dependency order: %s.
+# the import is planned before any change, if environments, pipelines branches or custom steps of the definitions not exist in the project,
+# the import fails before the project created or definitions applied, use --try to show the plan.
+# environments except --env and pipelines branches can not be created by import, it is not a full restore of a new project,
+# add them to the project in dory-core server first and import again, or use --skip-missing to skip these definitions and import the others.`, strings.Join(pkg.DefKindsApplyOrder, " / "))
+	msgExample := fmt.Sprintf(`  # import project from directory
+  doryctl project import backup/test-project1
+
+  # import project from directory, if the project not exists, create it in environment test
+  doryctl project import backup/test-project1 --env test
+
+  # print the import plan, the definitions will be applied or skipped, but not create project or apply them
+  doryctl project import backup/test-project1 --try
+
+  # import project from directory, skip the definitions of environments, pipelines branches or custom steps not exist
+  doryctl project import backup/test-project1 --skip-missing`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringVar(&o.EnvName, "env", "", "which environment project will create if the project not exists, default is the first environment in project export")
+	cmd.Flags().BoolVar(&o.Try, "try", false, "try to check the project import, but not create project or apply definitions")
+	cmd.Flags().BoolVar(&o.SkipMissing, "skip-missing", false, "skip the definitions of environments, pipelines branches or custom steps not exist in project, otherwise the import fails")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsProjectImport) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("env", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		envNames, err := o.GetEnvNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return envNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsProjectImport) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		err = fmt.Errorf("directory required")
+		return err
+	}
+	o.Param.Dir = args[0]
+
+	fileName := filepath.Join(o.Param.Dir, pkg.ProjectExportFile)
+	bs, err := os.ReadFile(fileName)
+	if err != nil {
+		err = fmt.Errorf("read file %s error: %s", fileName, err.Error())
+		return err
+	}
+	var projectExport pkg.ProjectExport
+	err = yaml.Unmarshal(bs, &projectExport)
+	if err != nil {
+		err = fmt.Errorf("parse file %s error: %s", fileName, err.Error())
+		return err
+	}
+	if projectExport.Kind != "project" {
+		err = fmt.Errorf("parse file %s error: kind must be project", fileName)
+		return err
+	}
+	projectName := projectExport.ProjectInfo.ProjectName
+	err = pkg.ValidateMinusNameID(projectName)
+	if err != nil {
+		err = fmt.Errorf("parse file %s error: projectInfo.projectName %s format error: %s", fileName, projectName, err.Error())
+		return err
+	}
+	o.Param.ProjectExport = projectExport
+
+	defsDir := filepath.Join(o.Param.Dir, pkg.ProjectExportDefsDir)
+	_, err = os.Stat(defsDir)
+	if err == nil {
+		o.Param.FileNames, o.Param.Defs, err = GetDefKindsFromFiles([]string{defsDir}, true)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	err = nil
+	for _, def := range o.Param.Defs {
+		if def.Metadata.ProjectName != projectName {
+			err = fmt.Errorf("kind %s metadata.projectName %s must be %s", def.Kind, def.Metadata.ProjectName, projectName)
+			return err
+		}
+	}
+
+	return err
+}
+
+// FilterImportDefKinds return the definitions can be applied to project and the reasons of skipped definitions,
+// definitions of environments, pipelines branches or custom steps not exist in project are skipped
+func FilterImportDefKinds(project pkg.ProjectOutput, defs []pkg.DefKind) ([]pkg.DefKind, []string) {
+	envSteps := map[string]map[string]bool{}
+	for _, pae := range project.ProjectAvailableEnvs {
+		envSteps[pae.EnvName] = map[string]bool{}
+		for stepName := range pae.CustomStepDefs {
+			envSteps[pae.EnvName][stepName] = true
+		}
+	}
+	branchNames := map[string]bool{}
+	for _, pp := range project.ProjectPipelines {
+		branchNames[pp.BranchName] = true
+	}
+
+	defKinds := []pkg.DefKind{}
+	skips := []string{}
+	for _, def := range defs {
+		envName := def.Metadata.Labels["envName"]
+		stepName := def.Metadata.Labels["stepName"]
+		branchName := def.Metadata.Labels["branchName"]
+		switch def.Kind {
+		case "deployContainerDefs":
+			if _, ok := envSteps[envName]; !ok {
+				skips = append(skips, fmt.Sprintf("skip %s envName=%s: environment not exists in project", def.Kind, envName))
+				continue
+			}
+		case "customStepDef":
+			if envName != "" {
+				if _, ok := envSteps[envName]; !ok {
+					skips = append(skips, fmt.Sprintf("skip %s stepName=%s envName=%s: environment not exists in project", def.Kind, stepName, envName))
+					continue
+				}
+				if !envSteps[envName][stepName] {
+					skips = append(skips, fmt.Sprintf("skip %s stepName=%s envName=%s: custom step not exists in project", def.Kind, stepName, envName))
+					continue
+				}
+			} else if _, ok := project.ProjectDef.CustomStepDefs[stepName]; !ok {
+				skips = append(skips, fmt.Sprintf("skip %s stepName=%s: custom step not exists in project", def.Kind, stepName))
+				continue
+			}
+		case "pipelineDef":
+			if !branchNames[branchName] {
+				skips = append(skips, fmt.Sprintf("skip %s branchName=%s: pipeline branch not exists in project", def.Kind, branchName))
+				continue
+			}
+		}
+		defKinds = append(defKinds, def)
+	}
+	return defKinds, skips
+}
+
+// ImportMissingNames return the environments and pipeline branches of project export not exist in project
+func ImportMissingNames(project pkg.ProjectOutput, projectExport pkg.ProjectExport) ([]string, []string) {
+	missingEnvNames := []string{}
+	for _, envName := range projectExport.EnvNames {
+		var found bool
+		for _, pae := range project.ProjectAvailableEnvs {
+			if pae.EnvName == envName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missingEnvNames = append(missingEnvNames, envName)
+		}
+	}
+	missingBranchNames := []string{}
+	for _, pipeline := range projectExport.Pipelines {
+		var found bool
+		for _, pp := range project.ProjectPipelines {
+			if pp.BranchName == pipeline.BranchName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missingBranchNames = append(missingBranchNames, pipeline.BranchName)
+		}
+	}
+	return missingEnvNames, missingBranchNames
+}
+
+// NewImportProject return the project will be created by project add, it has only one environment and no pipeline branches,
+// all custom steps in dory-core server are available in the project
+func NewImportProject(pa pkg.ProjectAdd, stepNames []string) pkg.ProjectOutput {
+	customStepDefs := pkg.CustomStepDefs{}
+	for _, stepName := range stepNames {
+		customStepDefs[stepName] = pkg.CustomStepDef{}
+	}
+	project := pkg.ProjectOutput{}
+	project.ProjectInfo.ProjectName = pa.ProjectName
+	project.ProjectDef.CustomStepDefs = customStepDefs
+	project.ProjectAvailableEnvs = []pkg.ProjectAvailableEnv{
+		{
+			EnvName:        pa.EnvName,
+			CustomStepDefs: customStepDefs,
+		},
+	}
+	return project
+}
+
+// SortDefUpdates sort definitions updates by pkg.DefKindsApplyOrder, definitions depend on the previous kinds
+func SortDefUpdates(defUpdates []pkg.DefUpdate) {
+	kindOrders := map[string]int{}
+	for i, kind := range pkg.DefKindsApplyOrder {
+		kindOrders[kind] = i
+	}
+	sort.SliceStable(defUpdates, func(i, j int) bool {
+		return kindOrders[defUpdates[i].Kind] < kindOrders[defUpdates[j].Kind]
+	})
+}
+
+func (o *OptionsProjectImport) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	projectExport := o.Param.ProjectExport
+	projectName := projectExport.ProjectInfo.ProjectName
+
+	// plan the import before create the project, the missing environments and pipeline branches fail the import before any change
+	var pa *pkg.ProjectAdd
+	project, err := o.GetProjectDef(projectName)
+	if client.IsNotFound(err) {
+		envName := o.EnvName
+		if envName == "" && len(projectExport.EnvNames) > 0 {
+			envName = projectExport.EnvNames[0]
+		}
+		if envName == "" {
+			err = fmt.Errorf("project %s not exists, --env required to create project", projectName)
+			return err
+		}
+		envNames, err := o.GetEnvNames()
+		if err != nil {
+			return err
+		}
+		var found bool
+		for _, name := range envNames {
+			if name == envName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			err = fmt.Errorf("project %s not exists, envName %s not exists to create project", projectName, envName)
+			return err
+		}
+		stepNames, err := o.GetStepNames()
+		if err != nil {
+			return err
+		}
+		pa = &pkg.ProjectAdd{
+			ProjectName:      projectName,
+			ProjectDesc:      projectExport.ProjectInfo.ProjectDesc,
+			ProjectShortName: projectExport.ProjectInfo.ProjectShortName,
+			ProjectTeam:      projectExport.ProjectInfo.ProjectTeam,
+			EnvName:          envName,
+		}
+		project = NewImportProject(*pa, stepNames)
+		log.Info(fmt.Sprintf("project %s not exists, it will be created in environment %s", projectName, envName))
+	} else if err != nil {
+		return err
+	}
+
+	missingEnvNames, missingBranchNames := ImportMissingNames(project, projectExport)
+	for _, envName := range missingEnvNames {
+		log.Warning(fmt.Sprintf("project %s environment %s not exists, add it to project in dory-core server first", projectName, envName))
+	}
+	for _, branchName := range missingBranchNames {
+		log.Warning(fmt.Sprintf("project %s pipeline branch %s not exists, add it to project in dory-core server first", projectName, branchName))
+	}
+	defs, skips := FilterImportDefKinds(project, o.Param.Defs)
+	for _, skip := range skips {
+		log.Warning(skip)
+	}
+	incomplete := len(skips) > 0 || len(missingEnvNames) > 0 || len(missingBranchNames) > 0
+	var errIncomplete error
+	if incomplete && !o.SkipMissing {
+		errIncomplete = fmt.Errorf("import project %s error: project incomplete, %d definitions can not be applied, missing environments: [%s], missing pipeline branches: [%s], add them and custom steps to project in dory-core server first, or use --skip-missing to import the others", projectName, len(skips), strings.Join(missingEnvNames, ","), strings.Join(missingBranchNames, ","))
+	}
+
+	if o.Try {
+		projectPlan, err := MergeDefKinds(project, defs)
+		if err != nil {
+			return err
+		}
+		defUpdates := GetDefUpdates(projectPlan)
+		SortDefUpdates(defUpdates)
+		for _, defUpdate := range defUpdates {
+			log.Info(fmt.Sprintf("%s will be applied", DefUpdatePath(defUpdate)))
+		}
+		if errIncomplete != nil {
+			return errIncomplete
+		}
+		log.Info(fmt.Sprintf("import project %s plan: %d definitions will be applied, %d definitions will be skipped", projectName, len(defUpdates), len(skips)))
+		return err
+	}
+	if errIncomplete != nil {
+		return errIncomplete
+	}
+
+	if pa != nil {
+		log.Info(fmt.Sprintf("##############################"))
+		log.Info(fmt.Sprintf("# start to create project %s", projectName))
+		auditID, err := o.Client().AddProject(*pa)
+		if err != nil {
+			return err
+		}
+		url := fmt.Sprintf("api/ws/log/audit/admin/%s", auditID)
+		err = o.QueryWebsocket(url, "", []string{})
+		if err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("##############################"))
+		log.Success(fmt.Sprintf("# finish create project %s", projectName))
+
+		// filter the definitions again by the created project, it may have more pipeline branches than planned
+		project, err = o.GetProjectDef(projectName)
+		if err != nil {
+			return err
+		}
+		missingEnvNames, missingBranchNames = ImportMissingNames(project, projectExport)
+		defs, skips = FilterImportDefKinds(project, o.Param.Defs)
+		incomplete = len(skips) > 0 || len(missingEnvNames) > 0 || len(missingBranchNames) > 0
+		if incomplete && !o.SkipMissing {
+			for _, skip := range skips {
+				log.Warning(skip)
+			}
+			err = fmt.Errorf("import project %s error: project created, but %d definitions can not be applied, add the missing custom steps to project in dory-core server first, or use --skip-missing to import the others", projectName, len(skips))
+			return err
+		}
+	}
+
+	project, err = MergeDefKinds(project, defs)
+	if err != nil {
+		return err
+	}
+	defUpdates := GetDefUpdates(project)
+	SortDefUpdates(defUpdates)
+
+	err = o.ApplyDefUpdates(defUpdates)
+	if err != nil {
+		return err
+	}
+	if incomplete {
+		log.Warning(fmt.Sprintf("import project %s from %s partially, %d definitions applied, %d definitions skipped", projectName, o.Param.Dir, len(defUpdates), len(skips)))
+		return err
+	}
+	log.Success(fmt.Sprintf("import project %s from %s success, %d definitions applied", projectName, o.Param.Dir, len(defUpdates)))
+
+	return err
+}
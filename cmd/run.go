@@ -17,7 +17,19 @@ func NewCmdRun() *cobra.Command {
   doryctl run logs test-project1-develop-1
   
   # delete run, project maintainer permission required
-  doryctl run abort test-project1-develop-1`)
+  doryctl run abort test-project1-develop-1
+  
+  # answer pipeline run pending input
+  doryctl run input test-project1-develop-1 --value=CONFIRM
+  
+  # rerun the pipeline of pipeline run, replay the input answers
+  doryctl run rerun test-project1-develop-1 --replay-inputs
+  
+  # wait until pipeline runs finished
+  doryctl run wait test-project1-develop-1 test-project1-develop-2
+  
+  # show pipeline run statistics of the last 30 days
+  doryctl run stats`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -36,5 +48,9 @@ func NewCmdRun() *cobra.Command {
 	cmd.AddCommand(NewCmdRunGet())
 	cmd.AddCommand(NewCmdRunLog())
 	cmd.AddCommand(NewCmdRunAbort())
+	cmd.AddCommand(NewCmdRunInput())
+	cmd.AddCommand(NewCmdRunRerun())
+	cmd.AddCommand(NewCmdRunWait())
+	cmd.AddCommand(NewCmdRunStats())
 	return cmd
 }
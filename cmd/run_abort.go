@@ -1,11 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
-	"net/http"
 	"strings"
 )
 
@@ -101,31 +99,22 @@ func (o *OptionsRunAbort) Run(args []string) error {
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/run/%s", o.Param.RunName), http.MethodGet, "", param, false)
-	if err != nil {
-		return err
-	}
-	run := pkg.Run{}
-	err = json.Unmarshal([]byte(result.Get("data.run").Raw), &run)
+	c := o.Client()
+	run, err := c.GetRun(o.Param.RunName)
 	if err != nil {
 		return err
 	}
 
-	if run.RunName == "" {
-		err = fmt.Errorf("runName %s not exists", o.Param.RunName)
-		return err
-	}
 	if run.Status.Duration != "" {
 		err = fmt.Errorf("runName %s already stop, status: %s", o.Param.RunName, run.Status.Result)
 		return err
 	}
 
-	result, _, err = o.QueryAPI(fmt.Sprintf("api/cicd/run/%s", o.Param.RunName), http.MethodPatch, "", param, false)
+	msg, err := c.AbortRun(o.Param.RunName)
 	if err != nil {
 		return err
 	}
-	log.Success(result.Get("msg").String())
+	log.Success(msg)
 
 	if o.Log {
 		url := fmt.Sprintf("api/ws/log/run/%s", o.Param.RunName)
@@ -1,12 +1,14 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
-	"github.com/olekukonko/tablewriter"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/dory-engine/dory-ctl/pkg/printer"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"net/http"
+	"golang.org/x/crypto/ssh/terminal"
 	"os"
 	"strings"
 	"time"
@@ -14,18 +16,24 @@ import (
 
 type OptionsRunGet struct {
 	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
-	ProjectNames   []string `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
-	PipelineNames  []string `yaml:"pipelineNames" json:"pipelineNames" bson:"pipelineNames" validate:""`
-	StatusResults  []string `yaml:"statusResults" json:"statusResults" bson:"statusResults" validate:""`
-	StartDate      string   `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
-	EndDate        string   `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
-	Page           int      `yaml:"page" json:"page" bson:"page" validate:""`
-	Number         int      `yaml:"number" json:"number" bson:"number" validate:""`
-	Output         string   `yaml:"output" json:"output" bson:"output" validate:""`
+	ProjectNames   []string        `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
+	PipelineNames  []string        `yaml:"pipelineNames" json:"pipelineNames" bson:"pipelineNames" validate:""`
+	StatusResults  []string        `yaml:"statusResults" json:"statusResults" bson:"statusResults" validate:""`
+	StartDate      string          `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
+	EndDate        string          `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
+	Page           int             `yaml:"page" json:"page" bson:"page" validate:""`
+	Number         int             `yaml:"number" json:"number" bson:"number" validate:""`
+	Watch          bool            `yaml:"watch" json:"watch" bson:"watch" validate:""`
+	UntilDone      bool            `yaml:"untilDone" json:"untilDone" bson:"untilDone" validate:""`
+	Interval       int             `yaml:"interval" json:"interval" bson:"interval" validate:""`
+	All            bool            `yaml:"all" json:"all" bson:"all" validate:""`
+	Limit          int             `yaml:"limit" json:"limit" bson:"limit" validate:""`
+	PrinterOptions printer.Options `yaml:"printerOptions" json:"printerOptions" bson:"printerOptions" validate:""`
 	Param          struct {
-		StartDate time.Time `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
-		EndDate   time.Time `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
-		RunNames  []string  `yaml:"runNames" json:"runNames" bson:"runNames" validate:""`
+		StartDate time.Time        `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
+		EndDate   time.Time        `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
+		RunNames  []string         `yaml:"runNames" json:"runNames" bson:"runNames" validate:""`
+		Printer   *printer.Printer `yaml:"-" json:"-" bson:"-" validate:""`
 	}
 }
 
@@ -45,7 +53,34 @@ func NewCmdRunGet() *cobra.Command {
   doryctl run get
 
   # get single pipeline run resoure
-  doryctl run get test-project1-develop-1`)
+  doryctl run get test-project1-develop-1
+
+  # get all pipeline runs of project in January 2022, iterate all pages
+  doryctl run get --projects=test-project1 --start=2022-01-01 --end=2022-01-31 --all -o json
+
+  # get the latest 500 pipeline runs
+  doryctl run get --limit=500
+
+  # watch running pipeline runs, refresh the table every 2 seconds
+  doryctl run get --statuses=RUNNING,INPUT --watch
+
+  # get pipeline runs waiting for input, with the pending input title, answer them by run input command
+  doryctl run get --statuses=INPUT
+
+  # get the runNames of failed pipeline runs, one runName per line
+  doryctl run get --statuses=FAIL -o name
+
+  # get pipeline runs with projectName and pipelineName columns, sorted by startTime
+  doryctl run get -o wide --sort-by=.status.startTime
+
+  # get pipeline runs with custom columns
+  doryctl run get -o custom-columns=NAME:.runName,USER:.startUser,STATUS:.status.result
+
+  # get pipeline runs by go-template
+  doryctl run get -o go-template='{{range .runs}}{{.runName}} {{.status.result}}{{"\n"}}{{end}}'
+
+  # watch pipeline runs until all of them finished
+  doryctl run get test-project1-develop-1 test-project1-develop-2 -w --until-done`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -65,7 +100,12 @@ func NewCmdRunGet() *cobra.Command {
 	cmd.Flags().StringVar(&o.EndDate, "end", "", "filters by pipeline run startTime in time range, example: 2022-01-31")
 	cmd.Flags().IntVar(&o.Page, "page", 1, "pagination number")
 	cmd.Flags().IntVarP(&o.Number, "number", "n", 200, "show how many items each page")
-	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+	AddPrinterFlags(cmd, &o.PrinterOptions)
+	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", false, "watch pipeline runs and refresh the table in place, highlight the status transitions")
+	cmd.Flags().BoolVar(&o.UntilDone, "until-done", false, "stop watching when all watched pipeline runs finished, use with --watch option")
+	cmd.Flags().IntVar(&o.Interval, "interval", 2, "refresh interval seconds, use with --watch option")
+	cmd.Flags().BoolVar(&o.All, "all", false, "get pipeline runs of all pages from --page, items are printed page by page")
+	cmd.Flags().IntVar(&o.Limit, "limit", 0, "max number of pipeline runs to get from --page, pages are iterated until the limit reached, 0 means no limit")
 
 	CheckError(o.Complete(cmd))
 	return cmd
@@ -143,9 +183,7 @@ func (o *OptionsRunGet) Complete(cmd *cobra.Command) error {
 		return err
 	}
 
-	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
-	})
+	err = RegisterPrinterFlagCompletion(cmd)
 	if err != nil {
 		return err
 	}
@@ -252,13 +290,182 @@ func (o *OptionsRunGet) Validate(args []string) error {
 		return err
 	}
 
-	if o.Output != "" {
-		if o.Output != "yaml" && o.Output != "json" {
-			err = fmt.Errorf("--output must be yaml or json")
+	o.Param.Printer, err = printer.NewPrinter(o.PrinterOptions)
+	if err != nil {
+		return err
+	}
+
+	if o.Watch && !o.Param.Printer.IsTable() {
+		err = fmt.Errorf("--watch can only use with table or wide output")
+		return err
+	}
+	if o.UntilDone && !o.Watch {
+		err = fmt.Errorf("--until-done must use with --watch")
+		return err
+	}
+	if o.Interval < 1 {
+		err = fmt.Errorf("--interval must greater than 1")
+		return err
+	}
+	if o.Limit < 0 {
+		err = fmt.Errorf("--limit must greater than or equal to 0")
+		return err
+	}
+	if o.Watch && (o.All || o.Limit > 0) {
+		err = fmt.Errorf("--watch can not use with --all or --limit")
+		return err
+	}
+	return err
+}
+
+// IsRunFinished check the pipeline run status is finished, INPUT status is waiting for input, not finished
+func IsRunFinished(statusResult string) bool {
+	return statusResult != pkg.StatusRunning && statusResult != pkg.StatusInput && statusResult != ""
+}
+
+// ColorRunStatus colorize the pipeline run status
+func ColorRunStatus(statusResult string) string {
+	switch statusResult {
+	case pkg.StatusSuccess:
+		return color.GreenString(statusResult)
+	case pkg.StatusFail:
+		return color.RedString(statusResult)
+	case pkg.StatusAbort, pkg.StatusInput:
+		return color.YellowString(statusResult)
+	case pkg.StatusRunning:
+		return color.CyanString(statusResult)
+	}
+	return statusResult
+}
+
+// RunDuration return the duration of pipeline run, return the elapsed time since start time if the pipeline run not finished
+func RunDuration(run pkg.Run) string {
+	if IsRunFinished(run.Status.Result) {
+		return run.Status.Duration
+	}
+	startTime, err := time.ParseInLocation(pkg.RunTimeLayout, run.Status.StartTime, time.Local)
+	if err != nil {
+		return run.Status.Duration
+	}
+	return time.Since(startTime).Round(time.Second).String()
+}
+
+// GetPendingRunInputs return the pending inputs of pipeline runs in INPUT status, the key is runName
+func (o *OptionsCommon) GetPendingRunInputs(runs []pkg.Run) (map[string]pkg.RunInput, error) {
+	var err error
+	runInputs := map[string]pkg.RunInput{}
+	for _, run := range runs {
+		if run.Status.Result != pkg.StatusInput {
+			continue
+		}
+		runInput, err := o.Client().GetRunInput(run.RunName)
+		if err != nil {
+			return runInputs, err
+		}
+		if runInput.PhaseID != "" {
+			runInputs[run.RunName] = runInput
+		}
+	}
+	return runInputs, err
+}
+
+// RunsTable return pipeline runs table, transitions are the status transitions of pipeline runs, the key is runName,
+// Input column shows the title of runInputs if showInput is true
+func RunsTable(runs []pkg.Run, transitions map[string]string, runInputs map[string]pkg.RunInput, showInput bool) printer.Table {
+	table := printer.Table{
+		Headers:     []string{"Name", "StartUser", "AbortUser", "StartTime", "Status", "Duration"},
+		WideHeaders: []string{"Project", "Pipeline"},
+	}
+	if showInput {
+		table.Headers = append(table.Headers, "Input")
+	}
+	for _, run := range runs {
+		runName := run.RunName
+		startUser := run.StartUser
+		abortUser := run.AbortUser
+		startTime := run.Status.StartTime
+		statusResult := run.Status.Result
+		duration := run.Status.Duration
+		if transitions != nil {
+			statusResult = ColorRunStatus(statusResult)
+			if transition, ok := transitions[run.RunName]; ok {
+				statusResult = color.New(color.Bold).Sprintf("%s → %s", transition, statusResult)
+			}
+			duration = RunDuration(run)
+		}
+		cells := []string{runName, startUser, abortUser, startTime, statusResult, duration}
+		if showInput {
+			cells = append(cells, runInputs[run.RunName].Title)
+		}
+		table.Rows = append(table.Rows, printer.Row{
+			Name:      fmt.Sprintf("run/%s", runName),
+			Cells:     cells,
+			WideCells: []string{run.ProjectName, run.PipelineName},
+			Object:    run,
+		})
+	}
+	return table
+}
+
+// WatchRuns poll pipeline runs and refresh the table in place until interrupted,
+// if untilDone is true, return when all pipeline runs finished
+func (o *OptionsRunGet) WatchRuns(query client.RunQuery) error {
+	c := o.Client()
+	isTerminal := terminal.IsTerminal(int(os.Stdout.Fd()))
+	statuses := map[string]string{}
+	transitions := map[string]string{}
+	var lineCount int
+	for {
+		runs, err := c.ListRuns(query)
+		if err != nil {
+			return err
+		}
+		err = o.Param.Printer.Sort(runs)
+		if err != nil {
 			return err
 		}
+		runInputs, err := o.GetPendingRunInputs(runs)
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			status, ok := statuses[run.RunName]
+			if ok && status != run.Status.Result {
+				transitions[run.RunName] = status
+			}
+			statuses[run.RunName] = run.Status.Result
+		}
+
+		var b bytes.Buffer
+		b.WriteString(fmt.Sprintf("%s every %ds\n", time.Now().Format(pkg.RunTimeLayout), o.Interval))
+		err = o.Param.Printer.PrintTable(&b, RunsTable(runs, transitions, runInputs, len(runInputs) > 0), true)
+		if err != nil {
+			return err
+		}
+		if isTerminal && lineCount > 0 {
+			// move the cursor to the beginning of the previous table and clear it
+			fmt.Printf("\033[%dA\033[J", lineCount)
+		} else if lineCount > 0 {
+			fmt.Println()
+		}
+		fmt.Print(b.String())
+		lineCount = strings.Count(b.String(), "\n")
+
+		if o.UntilDone {
+			finished := true
+			for _, run := range runs {
+				if !IsRunFinished(run.Status.Result) {
+					finished = false
+					break
+				}
+			}
+			if finished {
+				log.Success(fmt.Sprintf("all %d pipeline runs finished", len(runs)))
+				return nil
+			}
+		}
+		time.Sleep(time.Second * time.Duration(o.Interval))
 	}
-	return err
 }
 
 func (o *OptionsRunGet) Run(args []string) error {
@@ -267,71 +474,70 @@ func (o *OptionsRunGet) Run(args []string) error {
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{
-		"projectNames":  o.ProjectNames,
-		"pipelineNames": o.PipelineNames,
-		"runNames":      o.Param.RunNames,
-		"statusResults": o.StatusResults,
-		"startTimeRage": map[string]string{
-			"startDate": o.StartDate,
-			"endDate":   o.EndDate,
-		},
-		"page":    o.Page,
-		"perPage": o.Number,
+	query := client.RunQuery{
+		ProjectNames:  o.ProjectNames,
+		PipelineNames: o.PipelineNames,
+		RunNames:      o.Param.RunNames,
+		StatusResults: o.StatusResults,
+		StartDate:     o.StartDate,
+		EndDate:       o.EndDate,
+		Page:          o.Page,
+		PerPage:       o.Number,
 	}
-	result, _, err := o.QueryAPI("api/cicd/runs", http.MethodPost, "", param, false)
-	if err != nil {
+	if o.Watch {
+		err = o.WatchRuns(query)
 		return err
 	}
-	rs := result.Get("data.runs").Array()
+
+	page := client.PageQuery{
+		Page:    o.Page,
+		PerPage: o.Number,
+		All:     o.All,
+		Limit:   o.Limit,
+	}
+	p := o.Param.Printer
+	lp := p.NewListPrinter(os.Stdout, "runs")
+	runInputsAll := map[string]pkg.RunInput{}
+	var count, lastCount int
 	runs := []pkg.Run{}
-	for _, r := range rs {
-		run := pkg.Run{}
-		err = json.Unmarshal([]byte(r.Raw), &run)
+	err = client.ListPages(page, &runs, func(page, perPage int) error {
+		var err error
+		query.Page = page
+		query.PerPage = perPage
+		runs, err = o.Client().ListRuns(query)
+		return err
+	}, func() error {
+		var err error
+		runInputs, err := o.GetPendingRunInputs(runs)
 		if err != nil {
 			return err
 		}
-		runs = append(runs, run)
-	}
-
-	if len(runs) > 0 {
-		dataOutput := map[string]interface{}{}
-		dataOutput["runs"] = runs
-		switch o.Output {
-		case "json":
-			bs, _ = json.MarshalIndent(dataOutput, "", "  ")
-			fmt.Println(string(bs))
-		case "yaml":
-			bs, _ = pkg.YamlIndent(dataOutput)
-			fmt.Println(string(bs))
-		default:
-			data := [][]string{}
-			for _, run := range runs {
-				runName := run.RunName
-				startUser := run.StartUser
-				abortUser := run.AbortUser
-				startTime := run.Status.StartTime
-				statusResult := run.Status.Result
-				duration := run.Status.Duration
-				data = append(data, []string{runName, startUser, abortUser, startTime, statusResult, duration})
-			}
-
-			table := tablewriter.NewWriter(os.Stdout)
-			table.SetHeader([]string{"Name", "StartUser", "AbortUser", "StartTime", "Status", "Duration"})
-			table.SetAutoWrapText(false)
-			table.SetAutoFormatHeaders(true)
-			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
-			table.SetAlignment(tablewriter.ALIGN_LEFT)
-			table.SetCenterSeparator("")
-			table.SetColumnSeparator("")
-			table.SetRowSeparator("")
-			table.SetHeaderLine(false)
-			table.SetBorder(false)
-			table.SetTablePadding("\t")
-			table.SetNoWhiteSpace(true)
-			table.AppendBulk(data)
-			table.Render()
+		for k, v := range runInputs {
+			runInputsAll[k] = v
+		}
+		lastCount = len(runs)
+		count = count + len(runs)
+		items := []interface{}{}
+		for _, run := range runs {
+			items = append(items, run)
 		}
+		// pages are printed one by one, the Input column is always shown if multiple pages may be printed
+		err = lp.Print(items, RunsTable(runs, nil, runInputs, len(runInputs) > 0 || o.All || o.Limit > 0))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	extra := map[string]interface{}{}
+	if len(runInputsAll) > 0 {
+		extra["runInputs"] = runInputsAll
+	}
+	err = lp.Close(extra)
+	if err != nil {
+		return err
+	}
+	if p.IsTable() && !o.All && o.Limit == 0 && lastCount == o.Number {
+		log.Warning(fmt.Sprintf("only %d pipeline runs of page %d are shown, use --all or --limit to get more pipeline runs", count, o.Page))
 	}
 
 	return err
@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+type OptionsRunInput struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Value          string `yaml:"value" json:"value" bson:"value" validate:""`
+	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	Log            bool   `yaml:"log" json:"log" bson:"log" validate:""`
+	Param          struct {
+		RunName string `yaml:"runName" json:"runName" bson:"runName" validate:""`
+	}
+}
+
+func NewOptionsRunInput() *OptionsRunInput {
+	var o OptionsRunInput
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdRunInput() *cobra.Command {
+	o := NewOptionsRunInput()
+
+	msgUse := fmt.Sprintf("input [runName]")
+	msgShort := fmt.Sprintf("answer pipeline run pending input")
+	msgLong := fmt.Sprintf(`answer the pending input of pipeline run in dory-core server, without streaming the pipeline run logs.
+# show the input title, description and options, then read the input value from stdin, or from --value option.
+# list all pipeline runs waiting for input: doryctl run get --statuses=%s`, pkg.StatusInput)
+	msgExample := fmt.Sprintf(`  # show the pending input of pipeline run, and read the input value from stdin
+  doryctl run input test-project1-develop-1
+
+  # answer the pending input of pipeline run with value
+  doryctl run input test-project1-develop-1 --value=CONFIRM
+
+  # answer the pending input of pipeline run with multiple options, and show the pipeline run logs
+  doryctl run input test-project1-ops-1 --value=tp1-gin-demo,tp1-go-demo --logs
+
+  # show the pending input of pipeline run in YAML format, not answer it
+  doryctl run input test-project1-develop-1 -o yaml`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringVar(&o.Value, "value", "", "input value, multiple options split with comma if the input support multiple options, example: opt1,opt2")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "print the pending input in output format, not answer it (options: yaml / json)")
+	cmd.Flags().BoolVarP(&o.Log, "logs", "l", false, "show run logs after input")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsRunInput) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			runNames := []string{}
+			runs, err := o.Client().ListRuns(client.RunQuery{StatusResults: []string{pkg.StatusInput}, Page: 1, PerPage: 200})
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			for _, run := range runs {
+				runNames = append(runNames, run.RunName)
+			}
+			return runNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsRunInput) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		err = fmt.Errorf("runName error: only accept one runName")
+		return err
+	}
+
+	s := args[0]
+	s = strings.Trim(s, " ")
+	err = pkg.ValidateMinusNameID(s)
+	if err != nil {
+		err = fmt.Errorf("runName error: %s", err.Error())
+		return err
+	}
+	o.Param.RunName = s
+
+	o.Value = strings.Trim(o.Value, " ")
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" {
+			err = fmt.Errorf("--output must be yaml or json")
+			return err
+		}
+		if o.Value != "" || o.Log {
+			err = fmt.Errorf("--output can not use with --value or --logs")
+			return err
+		}
+	}
+	return err
+}
+
+func (o *OptionsRunInput) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	c := o.Client()
+	run, err := c.GetRun(o.Param.RunName)
+	if err != nil {
+		return err
+	}
+
+	if run.Status.Duration != "" {
+		err = fmt.Errorf("runName %s already stop, status: %s", o.Param.RunName, run.Status.Result)
+		return err
+	}
+
+	runInput, err := c.GetRunInput(o.Param.RunName)
+	if err != nil {
+		return err
+	}
+	if runInput.PhaseID == "" {
+		err = fmt.Errorf("runName %s has no pending input, status: %s", o.Param.RunName, run.Status.Result)
+		return err
+	}
+
+	switch o.Output {
+	case "json":
+		bs, _ = json.MarshalIndent(runInput, "", "  ")
+		fmt.Println(string(bs))
+		return err
+	case "yaml":
+		bs, _ = pkg.YamlIndent(runInput)
+		fmt.Println(string(bs))
+		return err
+	}
+
+	log.Warning(fmt.Sprintf("# %s, %s", runInput.Title, runInput.Desc))
+	log.Warning(fmt.Sprintf("# options: %s", strings.Join(RunInputOptions(runInput), ",")))
+
+	inputValue := o.Value
+	if inputValue != "" {
+		err = ValidateRunInputValues(runInput, strings.Split(inputValue, ","))
+		if err != nil {
+			return err
+		}
+	} else {
+		inputValue, err = PromptRunInputValue(runInput)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = c.InputRun(o.Param.RunName, runInput.PhaseID, inputValue)
+	if err != nil {
+		return err
+	}
+	log.Success(fmt.Sprintf("input runName %s phaseID %s value %s success", o.Param.RunName, runInput.PhaseID, inputValue))
+	err = o.RecordRunInput(o.Param.RunName, runInput, inputValue)
+	if err != nil {
+		log.Warning(fmt.Sprintf("record input value error: %s", err.Error()))
+		err = nil
+	}
+
+	if o.Log {
+		url := fmt.Sprintf("api/ws/log/run/%s", o.Param.RunName)
+		err = o.QueryWebsocket(url, o.Param.RunName, []string{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return err
+}
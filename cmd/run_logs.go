@@ -4,18 +4,55 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
-	"net/http"
+	"io"
+	"os"
 	"strings"
+	"time"
 )
 
 type OptionsRunLog struct {
 	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	Output         string        `yaml:"output" json:"output" bson:"output" validate:""`
+	PhaseIDs       []string      `yaml:"phaseIDs" json:"phaseIDs" bson:"phaseIDs" validate:""`
+	StageIDs       []string      `yaml:"stageIDs" json:"stageIDs" bson:"stageIDs" validate:""`
+	StepIDs        []string      `yaml:"stepIDs" json:"stepIDs" bson:"stepIDs" validate:""`
+	Level          string        `yaml:"level" json:"level" bson:"level" validate:""`
+	Since          time.Duration `yaml:"since" json:"since" bson:"since" validate:""`
+	Tail           int           `yaml:"tail" json:"tail" bson:"tail" validate:""`
+	NoColor        bool          `yaml:"noColor" json:"noColor" bson:"noColor" validate:""`
+	SaveFile       string        `yaml:"saveFile" json:"saveFile" bson:"saveFile" validate:""`
+	InputsFile     string        `yaml:"inputsFile" json:"inputsFile" bson:"inputsFile" validate:""`
+	InputsDefault  string        `yaml:"inputsDefault" json:"inputsDefault" bson:"inputsDefault" validate:""`
 	Param          struct {
-		RunName string `yaml:"runName" json:"runName" bson:"runName" validate:""`
+		RunName   string    `yaml:"runName" json:"runName" bson:"runName" validate:""`
+		RunInputs RunInputs `yaml:"runInputs" json:"runInputs" bson:"runInputs" validate:""`
 	}
 }
 
+// RunLogPrinter filter and print pipeline run logs
+type RunLogPrinter struct {
+	Output   string
+	PhaseIDs []string
+	StageIDs []string
+	StepIDs  []string
+	Level    string
+	// Since is the earliest createTime of logs to print, zero means no limit
+	Since time.Time
+	// Tail is the number of the last logs to print, logs are buffered until Flush if Tail is greater than 0
+	Tail   int
+	Writer io.Writer
+	logs   []pkg.WsRunLog
+}
+
+// runLogLevels is the severity of log types, INPUT directives are not logs
+var runLogLevels = map[string]int{
+	pkg.LogTypeInfo:    0,
+	pkg.LogTypeWarning: 1,
+	pkg.LogTypeError:   2,
+}
+
 func NewOptionsRunLog() *OptionsRunLog {
 	var o OptionsRunLog
 	o.OptionsCommon = OptCommon
@@ -27,9 +64,25 @@ func NewCmdRunLog() *cobra.Command {
 
 	msgUse := fmt.Sprintf("logs [runName]")
 	msgShort := fmt.Sprintf("get pipeline run logs")
-	msgLong := fmt.Sprintf(`get pipeline run logs in dory-core server`)
+	msgLong := fmt.Sprintf(`get pipeline run logs in dory-core server
+# logs can be filtered by phase / stage / step IDs, level and create time.
+# with --tail, logs are printed after the pipeline run logs finished.
+# if the pipeline run is waiting for input, input value is read from stdin, or answered by --inputs-file.
+# with -o json, only the logs are printed to stdout, warnings and input prompts are printed to stderr.`)
 	msgExample := fmt.Sprintf(`  # get pipeline run logs
-  doryctl run logs test-project1-develop-1`)
+  doryctl run logs test-project1-develop-1
+
+  # get pipeline run logs in JSON format, one JSON object per line
+  doryctl run logs test-project1-develop-1 -o json
+
+  # get WARNING and ERROR logs of a step
+  doryctl run logs test-project1-develop-1 --step=step1 --level=WARNING
+
+  # get the last 100 logs in the last 10 minutes without color, and save logs to file
+  doryctl run logs test-project1-develop-1 --since=10m --tail=100 --no-color --save=run.log
+
+  # get pipeline run logs, answer the pipeline run inputs from file, choose the first option if an input not answered
+  doryctl run logs test-project1-develop-1 --inputs-file=answers.yaml --inputs-default=first`)
 
 	cmd := &cobra.Command{
 		Use:                   msgUse,
@@ -42,6 +95,17 @@ func NewCmdRunLog() *cobra.Command {
 			CheckError(o.Run(args))
 		},
 	}
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format, json output prints one JSON object per line (options: json)")
+	cmd.Flags().StringSliceVar(&o.PhaseIDs, "phase", []string{}, "filters by phaseIDs, example: phase1,phase2")
+	cmd.Flags().StringSliceVar(&o.StageIDs, "stage", []string{}, "filters by stageIDs, example: stage1,stage2")
+	cmd.Flags().StringSliceVar(&o.StepIDs, "step", []string{}, "filters by stepIDs, example: step1,step2")
+	cmd.Flags().StringVar(&o.Level, "level", "", "show logs at this level or above (options: INFO / WARNING / ERROR)")
+	cmd.Flags().DurationVar(&o.Since, "since", 0, "show logs newer than a relative duration, example: 10s, 5m, 1h")
+	cmd.Flags().IntVar(&o.Tail, "tail", 0, "show the last number of logs, 0 means show all logs")
+	cmd.Flags().BoolVar(&o.NoColor, "no-color", false, "show logs without color")
+	cmd.Flags().StringVar(&o.SaveFile, "save", "", "save the filtered logs to file, in the same format of output")
+	cmd.Flags().StringVar(&o.InputsFile, "inputs-file", "", "answer the pipeline run inputs by phaseID or title from YAML file")
+	cmd.Flags().StringVar(&o.InputsDefault, "inputs-default", "", fmt.Sprintf("policy for the pipeline run inputs not answered, override the unanswered policy in inputs file (options: %s / %s / %s)", pkg.InputPolicyPrompt, pkg.InputPolicyAbort, pkg.InputPolicyFirst))
 
 	CheckError(o.Complete(cmd))
 	return cmd
@@ -66,6 +130,27 @@ func (o *OptionsRunLog) Complete(cmd *cobra.Command) error {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{pkg.LogTypeInfo, pkg.LogTypeWarning, pkg.LogTypeError}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("inputs-default", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{pkg.InputPolicyPrompt, pkg.InputPolicyAbort, pkg.InputPolicyFirst}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
 	return err
 }
 
@@ -90,6 +175,121 @@ func (o *OptionsRunLog) Validate(args []string) error {
 		return err
 	}
 	o.Param.RunName = s
+
+	if o.Output != "" && o.Output != "json" {
+		err = fmt.Errorf("--output must be json")
+		return err
+	}
+	o.Level = strings.ToUpper(o.Level)
+	if o.Level != "" {
+		if _, ok := runLogLevels[o.Level]; !ok {
+			err = fmt.Errorf("--level must be %s / %s / %s", pkg.LogTypeInfo, pkg.LogTypeWarning, pkg.LogTypeError)
+			return err
+		}
+	}
+	if o.Since < 0 {
+		err = fmt.Errorf("--since must greater than or equal to 0")
+		return err
+	}
+	if o.Tail < 0 {
+		err = fmt.Errorf("--tail must greater than or equal to 0")
+		return err
+	}
+	o.Param.RunInputs, err = GetRunInputs(o.InputsFile, o.InputsDefault)
+	if err != nil {
+		return err
+	}
+	return err
+}
+
+// Match check the pipeline run log match the filters
+func (p *RunLogPrinter) Match(msg pkg.WsRunLog) bool {
+	level, ok := runLogLevels[msg.LogType]
+	if !ok {
+		return false
+	}
+	if p.Level != "" && level < runLogLevels[p.Level] {
+		return false
+	}
+	for _, filter := range []struct {
+		ids []string
+		id  string
+	}{
+		{ids: p.PhaseIDs, id: msg.PhaseID},
+		{ids: p.StageIDs, id: msg.StageID},
+		{ids: p.StepIDs, id: msg.StepID},
+	} {
+		if len(filter.ids) == 0 {
+			continue
+		}
+		var found bool
+		for _, id := range filter.ids {
+			if id == filter.id {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if !p.Since.IsZero() {
+		createTime, err := time.ParseInLocation(pkg.RunTimeLayout, msg.CreateTime, time.Local)
+		if err == nil && createTime.Before(p.Since) {
+			return false
+		}
+	}
+	return true
+}
+
+// Print print the pipeline run log if it match the filters
+func (p *RunLogPrinter) Print(msg pkg.WsRunLog) error {
+	var err error
+	if !p.Match(msg) {
+		return err
+	}
+	if p.Tail > 0 {
+		p.logs = append(p.logs, msg)
+		if len(p.logs) > p.Tail {
+			p.logs = p.logs[1:]
+		}
+		return err
+	}
+	err = p.write(msg)
+	return err
+}
+
+// Flush print the buffered logs when use Tail
+func (p *RunLogPrinter) Flush() error {
+	var err error
+	for _, msg := range p.logs {
+		err = p.write(msg)
+		if err != nil {
+			return err
+		}
+	}
+	p.logs = []pkg.WsRunLog{}
+	return err
+}
+
+func (p *RunLogPrinter) write(msg pkg.WsRunLog) error {
+	var err error
+	if p.Output == "json" {
+		bs, _ := json.Marshal(msg)
+		fmt.Println(string(bs))
+		if p.Writer != nil {
+			_, err = fmt.Fprintln(p.Writer, string(bs))
+		}
+	} else {
+		log.RunLog(msg)
+		if p.Writer != nil {
+			_, err = fmt.Fprintln(p.Writer, FormatRunLog(msg))
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("save logs error: %s", err.Error())
+		return err
+	}
 	return err
 }
 
@@ -99,27 +299,54 @@ func (o *OptionsRunLog) Run(args []string) error {
 	bs, _ := pkg.YamlIndent(o)
 	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
 
-	param := map[string]interface{}{}
-	result, _, err := o.QueryAPI(fmt.Sprintf("api/cicd/run/%s", o.Param.RunName), http.MethodGet, "", param, false)
+	_, err = o.Client().GetRun(o.Param.RunName)
 	if err != nil {
 		return err
 	}
-	run := pkg.Run{}
-	err = json.Unmarshal([]byte(result.Get("data.run").Raw), &run)
-	if err != nil {
-		return err
+
+	if o.NoColor {
+		color.NoColor = true
+	}
+	// one JSON object per line in stdout, warnings and input prompts are printed to stderr
+	if o.Output == "json" {
+		log.SetStderr(true)
 	}
 
-	if run.RunName == "" {
-		err = fmt.Errorf("runName %s not exists", o.Param.RunName)
-		return err
+	printer := &RunLogPrinter{
+		Output:   o.Output,
+		PhaseIDs: o.PhaseIDs,
+		StageIDs: o.StageIDs,
+		StepIDs:  o.StepIDs,
+		Level:    o.Level,
+		Tail:     o.Tail,
+	}
+	if o.Since > 0 {
+		printer.Since = time.Now().Add(-o.Since)
+	}
+	if o.SaveFile != "" {
+		f, err := os.Create(o.SaveFile)
+		if err != nil {
+			err = fmt.Errorf("--save %s error: %s", o.SaveFile, err.Error())
+			return err
+		}
+		defer f.Close()
+		printer.Writer = f
 	}
 
 	url := fmt.Sprintf("api/ws/log/run/%s", o.Param.RunName)
-	err = o.QueryWebsocket(url, o.Param.RunName, []string{})
+	err = o.QueryWebsocketRunLog(url, o.Param.RunName, &o.Param.RunInputs, nil, printer.Print)
+	if err != nil {
+		return err
+	}
+
+	err = printer.Flush()
 	if err != nil {
 		return err
 	}
 
+	if o.SaveFile != "" && o.Output == "" {
+		log.Success(fmt.Sprintf("logs saved to %s", o.SaveFile))
+	}
+
 	return err
 }
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+type OptionsRunRerun struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	ReplayInputs   bool   `yaml:"replayInputs" json:"replayInputs" bson:"replayInputs" validate:""`
+	InputsFile     string `yaml:"inputsFile" json:"inputsFile" bson:"inputsFile" validate:""`
+	InputsDefault  string `yaml:"inputsDefault" json:"inputsDefault" bson:"inputsDefault" validate:""`
+	Wait           bool   `yaml:"wait" json:"wait" bson:"wait" validate:""`
+	RunTimeout     int    `yaml:"runTimeout" json:"runTimeout" bson:"runTimeout" validate:""`
+	Detach         bool   `yaml:"detach" json:"detach" bson:"detach" validate:""`
+	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	Param          struct {
+		RunName string `yaml:"runName" json:"runName" bson:"runName" validate:""`
+	}
+}
+
+func NewOptionsRunRerun() *OptionsRunRerun {
+	var o OptionsRunRerun
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdRunRerun() *cobra.Command {
+	o := NewOptionsRunRerun()
+
+	msgUse := fmt.Sprintf("rerun [runName]")
+	msgShort := fmt.Sprintf("rerun the pipeline of pipeline run")
+	msgLong := fmt.Sprintf(`start a new pipeline run of the same pipeline as the pipeline run.
+# with --replay-inputs, the input answers of the pipeline run are replayed in the new pipeline run,
+# input answers are recorded by doryctl in directory %s of config file directory, when answered by pipeline execute, run logs or run input.
+# only the input answers given by doryctl with the same config file are recorded, answers given in the web UI or on another machine are not replayed,
+# records not modified in %d days are removed when a new input answer is recorded.
+# the options --wait, --run-timeout, --detach and --output are the same as pipeline execute.`, pkg.RunInputsRecordDir, pkg.RunInputsRecordKeepDays)
+	msgExample := fmt.Sprintf(`  # rerun the pipeline of pipeline run
+  doryctl run rerun test-project1-develop-1
+
+  # rerun the pipeline of pipeline run, replay the input answers of the pipeline run, prompt the inputs not answered
+  doryctl run rerun test-project1-develop-1 --replay-inputs
+
+  # rerun the pipeline of pipeline run with input answers file, and wait until the new pipeline run finished
+  doryctl run rerun test-project1-develop-1 --inputs-file answers.yaml --inputs-default abort --wait`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().BoolVar(&o.ReplayInputs, "replay-inputs", false, "replay the input answers of the pipeline run recorded by doryctl, answers given in the web UI or on another machine are not recorded")
+	cmd.Flags().StringVar(&o.InputsFile, "inputs-file", "", "answer the pipeline run inputs by phaseID or title from YAML file")
+	cmd.Flags().StringVar(&o.InputsDefault, "inputs-default", "", fmt.Sprintf("policy for the pipeline run inputs not answered, override the unanswered policy in inputs file (options: %s / %s / %s)", pkg.InputPolicyPrompt, pkg.InputPolicyAbort, pkg.InputPolicyFirst))
+	cmd.Flags().BoolVar(&o.Wait, "wait", false, "wait until the pipeline run finished, exit code is not 0 if the pipeline run not succeeded")
+	cmd.Flags().IntVar(&o.RunTimeout, "run-timeout", 0, "wait seconds for the pipeline run, abort the pipeline run if not finished in time, 0 means no timeout, it implies --wait")
+	cmd.Flags().BoolVar(&o.Detach, "detach", false, "print the runName and exit, not show the pipeline run logs")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "print the pipeline run summary in output format without logs, use with --wait or --detach option (options: yaml / json)")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsRunRerun) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			runNames, err := o.GetRunNames()
+			if err != nil {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return runNames, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("inputs-default", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{pkg.InputPolicyPrompt, pkg.InputPolicyAbort, pkg.InputPolicyFirst}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsRunRerun) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		err = fmt.Errorf("runName error: only accept one runName")
+		return err
+	}
+
+	s := args[0]
+	s = strings.Trim(s, " ")
+	err = pkg.ValidateMinusNameID(s)
+	if err != nil {
+		err = fmt.Errorf("runName error: %s", err.Error())
+		return err
+	}
+	o.Param.RunName = s
+
+	if o.ReplayInputs && o.InputsFile != "" {
+		err = fmt.Errorf("--replay-inputs can not use with --inputs-file")
+		return err
+	}
+	if o.ReplayInputs && (o.Detach || o.Output != "") {
+		err = fmt.Errorf("--replay-inputs can not use with --detach or --output")
+		return err
+	}
+	return err
+}
+
+func (o *OptionsRunRerun) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	run, err := o.Client().GetRun(o.Param.RunName)
+	if err != nil {
+		return err
+	}
+	if run.PipelineName == "" {
+		err = fmt.Errorf("runName %s not exists", o.Param.RunName)
+		return err
+	}
+
+	oe := NewOptionsPipelineExecute()
+	oe.Wait = o.Wait
+	oe.RunTimeout = o.RunTimeout
+	oe.Detach = o.Detach
+	oe.Output = o.Output
+	oe.InputsFile = o.InputsFile
+	oe.InputsDefault = o.InputsDefault
+	if o.ReplayInputs {
+		fileName := o.RunInputsRecordFile(o.Param.RunName)
+		_, err = os.Stat(fileName)
+		if err == nil {
+			oe.InputsFile = fileName
+		} else if os.IsNotExist(err) {
+			log.Warning(fmt.Sprintf("runName %s has no input answers recorded, inputs will not be replayed", o.Param.RunName))
+			err = nil
+		} else {
+			return err
+		}
+	}
+	err = oe.Validate([]string{run.PipelineName})
+	if err != nil {
+		return err
+	}
+
+	if o.Output == "" {
+		log.Info(fmt.Sprintf("rerun pipeline %s of runName %s", run.PipelineName, o.Param.RunName))
+	}
+	err = oe.Run([]string{run.PipelineName})
+	return err
+}
@@ -0,0 +1,366 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/dory-engine/dory-ctl/pkg/client"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+type OptionsRunStats struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	ProjectNames   []string `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
+	PipelineNames  []string `yaml:"pipelineNames" json:"pipelineNames" bson:"pipelineNames" validate:""`
+	StartDate      string   `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
+	EndDate        string   `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
+	Output         string   `yaml:"output" json:"output" bson:"output" validate:""`
+	Param          struct {
+		StartDate time.Time `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
+		EndDate   time.Time `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
+	}
+}
+
+func NewOptionsRunStats() *OptionsRunStats {
+	var o OptionsRunStats
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdRunStats() *cobra.Command {
+	o := NewOptionsRunStats()
+
+	msgUse := fmt.Sprintf("stats")
+	msgShort := fmt.Sprintf("show pipeline run statistics")
+	msgLong := fmt.Sprintf(`show pipeline run statistics in date range, total / by start user / by pipeline.
+# success rate is the percentage of SUCCESS in finished pipeline runs (SUCCESS / FAIL / ABORT).
+# mean and p95 duration are calculated from finished pipeline runs.
+# default date range is the last 30 days.`)
+	msgExample := fmt.Sprintf(`  # show pipeline run statistics of the last 30 days
+  doryctl run stats
+
+  # show pipeline run statistics of projects in January 2022
+  doryctl run stats --projects=test-project1,test-project2 --start=2022-01-01 --end=2022-01-31
+
+  # show pipeline run statistics in CSV format
+  doryctl run stats --start=2022-01-01 --end=2022-01-31 -o csv > stats.csv`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringSliceVar(&o.ProjectNames, "projects", []string{}, "filters by projectNames, example: test-project1,test-project2")
+	cmd.Flags().StringSliceVar(&o.PipelineNames, "pipelines", []string{}, "filters by pipelineNames, example: test-project1-develop,test-project2-ops")
+	cmd.Flags().StringVar(&o.StartDate, "start", "", "filters by pipeline run startTime in time range, default is 30 days before --end, example: 2022-01-01")
+	cmd.Flags().StringVar(&o.EndDate, "end", "", "filters by pipeline run startTime in time range, default is today, example: 2022-01-31")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json / csv)")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsRunStats) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("projects", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		projectNames, err := o.GetProjectNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return projectNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("pipelines", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		pipelineNames, err := o.GetPipelineNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return pipelineNames, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("start", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		s := time.Now().AddDate(0, 0, -30).Format("2006-01-02")
+		return []string{s}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("end", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		s := time.Now().Format("2006-01-02")
+		return []string{s}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml", "csv"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsRunStats) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range o.ProjectNames {
+		err = pkg.ValidateMinusNameID(name)
+		if err != nil {
+			err = fmt.Errorf("--projects %s error: %s", name, err.Error())
+			return err
+		}
+	}
+
+	for _, name := range o.PipelineNames {
+		err = pkg.ValidateMinusNameID(name)
+		if err != nil {
+			err = fmt.Errorf("--pipelines %s error: %s", name, err.Error())
+			return err
+		}
+	}
+
+	if o.EndDate == "" {
+		o.EndDate = time.Now().Format("2006-01-02")
+	}
+	o.Param.EndDate, err = time.Parse("2006-01-02", o.EndDate)
+	if err != nil {
+		err = fmt.Errorf("--end error: %s", err.Error())
+		return err
+	}
+	if o.StartDate == "" {
+		o.StartDate = o.Param.EndDate.AddDate(0, 0, -30).Format("2006-01-02")
+	}
+	o.Param.StartDate, err = time.Parse("2006-01-02", o.StartDate)
+	if err != nil {
+		err = fmt.Errorf("--start error: %s", err.Error())
+		return err
+	}
+	if o.Param.StartDate.After(o.Param.EndDate) {
+		err = fmt.Errorf("--start must before --end")
+		return err
+	}
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" && o.Output != "csv" {
+			err = fmt.Errorf("--output must be yaml or json or csv")
+			return err
+		}
+	}
+	return err
+}
+
+// GetRunStatsItem return the statistics of pipeline runs, durations of unfinished pipeline runs are ignored
+func GetRunStatsItem(name string, runs []pkg.Run) pkg.RunStatsItem {
+	item := pkg.RunStatsItem{
+		Name:     name,
+		RunCount: len(runs),
+	}
+	durations := []time.Duration{}
+	for _, run := range runs {
+		switch run.Status.Result {
+		case pkg.StatusSuccess:
+			item.SuccessCount++
+		case pkg.StatusFail:
+			item.FailCount++
+		case pkg.StatusAbort:
+			item.AbortCount++
+		}
+		if !IsRunFinished(run.Status.Result) {
+			continue
+		}
+		d, err := time.ParseDuration(run.Status.Duration)
+		if err != nil {
+			log.Debug(fmt.Sprintf("runName %s duration %s parse error: %s", run.RunName, run.Status.Duration, err.Error()))
+			continue
+		}
+		durations = append(durations, d)
+	}
+
+	finishCount := item.SuccessCount + item.FailCount + item.AbortCount
+	if finishCount > 0 {
+		item.SuccessRate = math.Round(float64(item.SuccessCount)*10000/float64(finishCount)) / 100
+	}
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool {
+			return durations[i] < durations[j]
+		})
+		var sum time.Duration
+		for _, d := range durations {
+			sum = sum + d
+		}
+		item.DurationMean = (sum / time.Duration(len(durations))).Round(time.Second).String()
+		// nearest-rank percentile
+		rank := int(math.Ceil(float64(len(durations))*0.95)) - 1
+		item.DurationP95 = durations[rank].Round(time.Second).String()
+	}
+	return item
+}
+
+// GetRunStats return the statistics of pipeline runs, total / by start user / by pipeline, sorted by name
+func GetRunStats(runs []pkg.Run) pkg.RunStats {
+	stats := pkg.RunStats{
+		Total:     GetRunStatsItem("total", runs),
+		Users:     []pkg.RunStatsItem{},
+		Pipelines: []pkg.RunStatsItem{},
+	}
+
+	userRuns := map[string][]pkg.Run{}
+	pipelineRuns := map[string][]pkg.Run{}
+	for _, run := range runs {
+		userRuns[run.StartUser] = append(userRuns[run.StartUser], run)
+		pipelineRuns[run.PipelineName] = append(pipelineRuns[run.PipelineName], run)
+	}
+	for name, items := range userRuns {
+		stats.Users = append(stats.Users, GetRunStatsItem(name, items))
+	}
+	for name, items := range pipelineRuns {
+		stats.Pipelines = append(stats.Pipelines, GetRunStatsItem(name, items))
+	}
+	sort.Slice(stats.Users, func(i, j int) bool {
+		return stats.Users[i].Name < stats.Users[j].Name
+	})
+	sort.Slice(stats.Pipelines, func(i, j int) bool {
+		return stats.Pipelines[i].Name < stats.Pipelines[j].Name
+	})
+	return stats
+}
+
+// runStatsRow return the table or csv row of pipeline run statistics item, rateFormat is the format of success rate
+func runStatsRow(item pkg.RunStatsItem, rateFormat string) []string {
+	return []string{
+		item.Name,
+		fmt.Sprintf("%d", item.RunCount),
+		fmt.Sprintf("%d", item.SuccessCount),
+		fmt.Sprintf("%d", item.FailCount),
+		fmt.Sprintf("%d", item.AbortCount),
+		fmt.Sprintf(rateFormat, item.SuccessRate),
+		item.DurationMean,
+		item.DurationP95,
+	}
+}
+
+func (o *OptionsRunStats) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	query := client.RunQuery{
+		ProjectNames:  o.ProjectNames,
+		PipelineNames: o.PipelineNames,
+		StartDate:     o.StartDate,
+		EndDate:       o.EndDate,
+	}
+	runs := []pkg.Run{}
+	items := []pkg.Run{}
+	err = client.ListPages(client.PageQuery{All: true}, &items, func(page, perPage int) error {
+		var err error
+		query.Page = page
+		query.PerPage = perPage
+		items, err = o.Client().ListRuns(query)
+		return err
+	}, func() error {
+		runs = append(runs, items...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	stats := GetRunStats(runs)
+	stats.StartDate = o.StartDate
+	stats.EndDate = o.EndDate
+
+	header := []string{"Name", "Runs", "Success", "Fail", "Abort", "SuccessRate", "DurationMean", "DurationP95"}
+	switch o.Output {
+	case "json":
+		bs, _ = json.MarshalIndent(stats, "", "  ")
+		fmt.Println(string(bs))
+	case "yaml":
+		bs, _ = pkg.YamlIndent(stats)
+		fmt.Println(string(bs))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		rows := [][]string{append([]string{"Group"}, header...)}
+		rows = append(rows, append([]string{"total"}, runStatsRow(stats.Total, "%.2f")...))
+		for _, item := range stats.Users {
+			rows = append(rows, append([]string{"user"}, runStatsRow(item, "%.2f")...))
+		}
+		for _, item := range stats.Pipelines {
+			rows = append(rows, append([]string{"pipeline"}, runStatsRow(item, "%.2f")...))
+		}
+		err = w.WriteAll(rows)
+		if err != nil {
+			return err
+		}
+	default:
+		groups := []struct {
+			title string
+			items []pkg.RunStatsItem
+		}{
+			{title: fmt.Sprintf("pipeline runs from %s to %s", o.StartDate, o.EndDate), items: []pkg.RunStatsItem{stats.Total}},
+			{title: "pipeline runs by start user", items: stats.Users},
+			{title: "pipeline runs by pipeline", items: stats.Pipelines},
+		}
+		for i, group := range groups {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("# %s\n", group.title)
+			data := [][]string{}
+			for _, item := range group.items {
+				data = append(data, runStatsRow(item, "%.2f%%"))
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader(header)
+			table.SetAutoWrapText(false)
+			table.SetAutoFormatHeaders(true)
+			table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			table.SetCenterSeparator("")
+			table.SetColumnSeparator("")
+			table.SetRowSeparator("")
+			table.SetHeaderLine(false)
+			table.SetBorder(false)
+			table.SetTablePadding("\t")
+			table.SetNoWhiteSpace(true)
+			table.AppendBulk(data)
+			table.Render()
+		}
+	}
+
+	return err
+}
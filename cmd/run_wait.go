@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+type OptionsRunWait struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	RunTimeout     int    `yaml:"runTimeout" json:"runTimeout" bson:"runTimeout" validate:""`
+	Output         string `yaml:"output" json:"output" bson:"output" validate:""`
+	Param          struct {
+		RunNames []string `yaml:"runNames" json:"runNames" bson:"runNames" validate:""`
+	}
+}
+
+func NewOptionsRunWait() *OptionsRunWait {
+	var o OptionsRunWait
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdRunWait() *cobra.Command {
+	o := NewOptionsRunWait()
+
+	msgUse := fmt.Sprintf("wait [runName] ...")
+	msgShort := fmt.Sprintf("wait until pipeline runs finished")
+	msgLong := fmt.Sprintf(`wait until all pipeline runs finished, then print the pipeline runs summary.
+# the pipeline runs are not aborted if not finished before --run-timeout, process exit codes:
+#   %d: all pipeline runs status are SUCCESS
+#   %d: command error
+#   %d: any pipeline run status is FAIL
+#   %d: any pipeline run status is ABORT
+#   %d: any pipeline run not finished before --run-timeout
+# if pipeline runs finished with different status, the greatest exit code is used.`, pkg.ExitCodeSuccess, pkg.ExitCodeError, pkg.ExitCodeFail, pkg.ExitCodeAbort, pkg.ExitCodeTimeout)
+	msgExample := fmt.Sprintf(`  # wait until pipeline run finished
+  doryctl run wait test-project1-develop-1
+
+  # wait until pipeline runs finished, at most 30 minutes
+  doryctl run wait test-project1-develop-1 test-project2-develop-1 --run-timeout 1800
+
+  # wait until pipeline runs finished, print the pipeline runs summary in JSON format
+  doryctl run wait test-project1-develop-1 test-project2-develop-1 -o json`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().IntVar(&o.RunTimeout, "run-timeout", 0, "wait seconds for the pipeline runs, 0 means no timeout")
+	cmd.Flags().StringVarP(&o.Output, "output", "o", "", "output format (options: yaml / json)")
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsRunWait) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		runNames, err := o.GetRunNames()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return runNames, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsRunWait) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		err = fmt.Errorf("runName error: at least one runName required")
+		return err
+	}
+	m := map[string]bool{}
+	for _, s := range args {
+		s = strings.Trim(s, " ")
+		err = pkg.ValidateMinusNameID(s)
+		if err != nil {
+			err = fmt.Errorf("runName %s error: %s", s, err.Error())
+			return err
+		}
+		if !m[s] {
+			o.Param.RunNames = append(o.Param.RunNames, s)
+			m[s] = true
+		}
+	}
+
+	if o.RunTimeout < 0 {
+		err = fmt.Errorf("--run-timeout must greater than or equal to 0")
+		return err
+	}
+
+	if o.Output != "" {
+		if o.Output != "yaml" && o.Output != "json" {
+			err = fmt.Errorf("--output must be yaml or json")
+			return err
+		}
+	}
+	return err
+}
+
+// RunSummariesTable render pipeline runs summary table
+func RunSummariesTable(summaries []pkg.RunSummary) {
+	data := [][]string{}
+	for _, summary := range summaries {
+		statusResult := summary.Result
+		if summary.TimedOut {
+			statusResult = fmt.Sprintf("%s (timeout)", statusResult)
+		}
+		data = append(data, []string{summary.RunName, summary.StartUser, summary.AbortUser, summary.StartTime, statusResult, summary.Duration, fmt.Sprintf("%d", summary.ExitCode)})
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Name", "StartUser", "AbortUser", "StartTime", "Status", "Duration", "ExitCode"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+	table.AppendBulk(data)
+	table.Render()
+}
+
+func (o *OptionsRunWait) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	c := o.Client()
+	for _, runName := range o.Param.RunNames {
+		run, err := c.GetRun(runName)
+		if err != nil {
+			return err
+		}
+		if run.RunName == "" {
+			err = fmt.Errorf("runName %s not exists", runName)
+			return err
+		}
+	}
+
+	var deadline time.Time
+	if o.RunTimeout > 0 {
+		deadline = time.Now().Add(time.Second * time.Duration(o.RunTimeout))
+	}
+
+	summaries := []pkg.RunSummary{}
+	exitCode := pkg.ExitCodeSuccess
+	for _, runName := range o.Param.RunNames {
+		run, timedOut, err := o.WaitRun(runName, deadline, nil)
+		if err != nil {
+			return err
+		}
+		summary := GetRunSummary(run, timedOut)
+		summaries = append(summaries, summary)
+		if summary.ExitCode > exitCode {
+			exitCode = summary.ExitCode
+		}
+	}
+
+	switch o.Output {
+	case "json":
+		bs, _ = json.MarshalIndent(summaries, "", "  ")
+		fmt.Println(string(bs))
+	case "yaml":
+		bs, _ = pkg.YamlIndent(summaries)
+		fmt.Println(string(bs))
+	default:
+		RunSummariesTable(summaries)
+	}
+
+	if exitCode != pkg.ExitCodeSuccess {
+		var runNames []string
+		for _, summary := range summaries {
+			if summary.ExitCode != pkg.ExitCodeSuccess {
+				runNames = append(runNames, summary.RunName)
+			}
+		}
+		err = &ExitError{Code: exitCode, Err: fmt.Errorf("pipeline runs not succeeded: %s", strings.Join(runNames, ", "))}
+		return err
+	}
+	return err
+}
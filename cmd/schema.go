@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type OptionsSchema struct {
+	*OptionsCommon `yaml:"optionsCommon" json:"optionsCommon" bson:"optionsCommon" validate:""`
+	OutputDir      string `yaml:"outputDir" json:"outputDir" bson:"outputDir" validate:""`
+	Param          struct {
+		SchemaNames []string `yaml:"schemaNames" json:"schemaNames" bson:"schemaNames" validate:""`
+	}
+}
+
+func NewOptionsSchema() *OptionsSchema {
+	var o OptionsSchema
+	o.OptionsCommon = OptCommon
+	return &o
+}
+
+func NewCmdSchema() *cobra.Command {
+	o := NewOptionsSchema()
+
+	msgUse := fmt.Sprintf(`schema [schemaName,...] [--output=directory]`)
+	msgShort := fmt.Sprintf("generate JSON Schema of definitions and configurations")
+	msgLong := fmt.Sprintf(`generate JSON Schema of project definitions kinds, admin configurations kinds and install config, the schema is generated from %s types, properties are the yaml fields and required fields are from the validate rules.
+# schema names: %s
+# use the schema in editors with yaml-language-server modeline, add this line at the top of yaml file:
+# # yaml-language-server: $schema=schemas/buildDefs%s`, pkg.BaseCmdName, strings.Join(pkg.SchemaNames(), " / "), pkg.SchemaFileSuffix)
+	msgExample := fmt.Sprintf(`  # print project build definitions JSON Schema
+  doryctl schema buildDefs
+
+  # write all JSON Schema files to directory
+  doryctl schema --output=schemas
+
+  # write admin configurations JSON Schema files to directory
+  doryctl schema user,customStepConf,envK8s,componentTemplate --output=schemas`)
+
+	cmd := &cobra.Command{
+		Use:                   msgUse,
+		DisableFlagsInUseLine: true,
+		Short:                 msgShort,
+		Long:                  msgLong,
+		Example:               msgExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			CheckError(o.Validate(args))
+			CheckError(o.Run(args))
+		},
+	}
+	cmd.Flags().StringVarP(&o.OutputDir, "output", "o", "", fmt.Sprintf("directory to write JSON Schema files, file name is schemaName%s, print to stdout if not set", pkg.SchemaFileSuffix))
+
+	CheckError(o.Complete(cmd))
+	return cmd
+}
+
+func (o *OptionsSchema) Complete(cmd *cobra.Command) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return pkg.SchemaNames(), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	err = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	})
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsSchema) Validate(args []string) error {
+	var err error
+
+	err = o.GetOptionsCommon()
+	if err != nil {
+		return err
+	}
+
+	schemaNames := []string{}
+	for _, arg := range args {
+		for _, name := range strings.Split(arg, ",") {
+			name = strings.TrimSpace(name)
+			var found bool
+			for _, schemaName := range schemaNames {
+				if schemaName == name {
+					found = true
+					break
+				}
+			}
+			if name != "" && !found {
+				schemaNames = append(schemaNames, name)
+			}
+		}
+	}
+	if len(schemaNames) == 0 {
+		schemaNames = pkg.SchemaNames()
+	}
+	for _, name := range schemaNames {
+		_, err = pkg.GetSchema(name)
+		if err != nil {
+			return err
+		}
+	}
+	o.Param.SchemaNames = schemaNames
+
+	if o.OutputDir == "" && len(o.Param.SchemaNames) > 1 {
+		err = fmt.Errorf("--output required when generate more than one schema, or set one schemaName, options: %s", strings.Join(pkg.SchemaNames(), " / "))
+		return err
+	}
+
+	return err
+}
+
+func (o *OptionsSchema) Run(args []string) error {
+	var err error
+
+	bs, _ := pkg.YamlIndent(o)
+	log.Debug(fmt.Sprintf("command options:\n%s", string(bs)))
+
+	if o.OutputDir != "" {
+		err = os.MkdirAll(o.OutputDir, 0755)
+		if err != nil {
+			err = fmt.Errorf("create directory %s error: %s", o.OutputDir, err.Error())
+			return err
+		}
+	}
+
+	for _, name := range o.Param.SchemaNames {
+		schema, err := pkg.GetSchema(name)
+		if err != nil {
+			return err
+		}
+		bs, err = json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			err = fmt.Errorf("generate schema %s error: %s", name, err.Error())
+			return err
+		}
+		if o.OutputDir == "" {
+			fmt.Println(string(bs))
+			return err
+		}
+		fileName := filepath.Join(o.OutputDir, fmt.Sprintf("%s%s", name, pkg.SchemaFileSuffix))
+		err = os.WriteFile(fileName, append(bs, '\n'), 0644)
+		if err != nil {
+			err = fmt.Errorf("write file %s error: %s", fileName, err.Error())
+			return err
+		}
+		log.Info(fmt.Sprintf("write %s success", fileName))
+	}
+	log.Success(fmt.Sprintf("write %d JSON Schema files to %s success", len(o.Param.SchemaNames), o.OutputDir))
+
+	return err
+}
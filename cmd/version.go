@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/dory-engine/dory-ctl/pkg"
 	"github.com/spf13/cobra"
-	"net/http"
 )
 
 type OptionsVersionRun struct {
@@ -61,13 +60,10 @@ func (o *OptionsVersionRun) Run(args []string) error {
 	if o.ServerURL != "" {
 		fmt.Println(fmt.Sprintf("serverURL: %s", o.ServerURL))
 		if o.AccessToken != "" {
-			param := map[string]interface{}{}
-			result, _, err := o.QueryAPI(fmt.Sprintf("api/public/about"), http.MethodGet, "", param, false)
+			appInfo, versionInfo, err := o.Client().About()
 			if err != nil {
 				return err
 			}
-			appInfo := result.Get("data.app").String()
-			versionInfo := result.Get("data.version").String()
 			fmt.Println(fmt.Sprintf("versionInfo: %s/%s", appInfo, versionInfo))
 		}
 	}
@@ -15,6 +15,7 @@ require (
 	github.com/mitchellh/copystructure v1.2.0 // indirect
 	github.com/olekukonko/tablewriter v0.0.5
 	github.com/spf13/cobra v1.2.1
+	github.com/spf13/pflag v1.0.5
 	github.com/tidwall/gjson v1.12.1
 	github.com/tidwall/sjson v1.2.4
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
@@ -0,0 +1,273 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"net/http"
+)
+
+// ListUsers return users sort by username
+func (c *Client) ListUsers(page, perPage int) ([]pkg.UserDetail, error) {
+	var err error
+	users := []pkg.UserDetail{}
+
+	param := map[string]interface{}{
+		"sortMode": "username",
+		"page":     page,
+		"perPage":  perPage,
+	}
+	_, err = c.QueryData("api/admin/users", http.MethodPost, param, "data.users", &users)
+	if err != nil {
+		return users, err
+	}
+
+	return users, err
+}
+
+// GetUserNames return all usernames
+func (c *Client) GetUserNames() ([]string, error) {
+	var err error
+	userNames := []string{}
+
+	users := []pkg.UserDetail{}
+	param := map[string]interface{}{}
+	_, err = c.QueryData("api/admin/userNames", http.MethodGet, param, "data.users", &users)
+	if err != nil {
+		return userNames, err
+	}
+	for _, user := range users {
+		userNames = append(userNames, user.Username)
+	}
+
+	return userNames, err
+}
+
+// ApplyUser create or update user, return the response message
+func (c *Client) ApplyUser(user pkg.User) (string, error) {
+	var err error
+	param := map[string]interface{}{}
+	bs, _ := json.Marshal(user)
+	_ = json.Unmarshal(bs, &param)
+	result, _, err := c.Query("api/admin/user", http.MethodPut, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
+
+// DeleteUser delete user, return the response message
+func (c *Client) DeleteUser(username string) (string, error) {
+	return c.deleteItem(fmt.Sprintf("api/admin/user/%s", username))
+}
+
+// ListCustomStepConfs return custom step configurations filters by customStepNames
+func (c *Client) ListCustomStepConfs(customStepNames []string, page, perPage int) ([]pkg.CustomStepConfDetail, error) {
+	var err error
+	customStepConfs := []pkg.CustomStepConfDetail{}
+
+	param := map[string]interface{}{
+		"customStepNames": customStepNames,
+		"page":            page,
+		"perPage":         perPage,
+	}
+	_, err = c.QueryData("api/admin/customStepConfs", http.MethodPost, param, "data.customStepConfs", &customStepConfs)
+	if err != nil {
+		return customStepConfs, err
+	}
+
+	return customStepConfs, err
+}
+
+// GetCustomStepNames return all custom step names
+func (c *Client) GetCustomStepNames() ([]string, error) {
+	var err error
+	customStepNames := []string{}
+
+	param := map[string]interface{}{
+		"page":    1,
+		"perPage": 1,
+	}
+	_, err = c.QueryData("api/admin/customStepConfs", http.MethodPost, param, "data.customStepNames", &customStepNames)
+	if err != nil {
+		return customStepNames, err
+	}
+
+	return customStepNames, err
+}
+
+// AddCustomStepConf create custom step configuration, return the response message
+func (c *Client) AddCustomStepConf(customStepConfYaml string) (string, error) {
+	var err error
+	param := map[string]interface{}{
+		"customStepConfYaml": customStepConfYaml,
+	}
+	result, _, err := c.Query("api/admin/customStepConf", http.MethodPost, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
+
+// UpdateCustomStepConf update custom step configuration, return the response message
+func (c *Client) UpdateCustomStepConf(customStepName, customStepConfYaml string) (string, error) {
+	var err error
+	param := map[string]interface{}{
+		"customStepConfYaml": customStepConfYaml,
+	}
+	result, _, err := c.Query(fmt.Sprintf("api/admin/customStepConf/%s", customStepName), http.MethodPost, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
+
+// DeleteCustomStepConf delete custom step configuration, return the response message
+func (c *Client) DeleteCustomStepConf(customStepName string) (string, error) {
+	return c.deleteItem(fmt.Sprintf("api/admin/customStepConf/%s", customStepName))
+}
+
+// ListEnvs return kubernetes environments filters by envNames
+func (c *Client) ListEnvs(envNames []string, page, perPage int) ([]pkg.EnvK8sDetail, error) {
+	var err error
+	envK8ss := []pkg.EnvK8sDetail{}
+
+	param := map[string]interface{}{
+		"envNames": envNames,
+		"page":     page,
+		"perPage":  perPage,
+	}
+	_, err = c.QueryData("api/admin/envs", http.MethodPost, param, "data.envK8ss", &envK8ss)
+	if err != nil {
+		return envK8ss, err
+	}
+
+	return envK8ss, err
+}
+
+// GetEnvNames return all environment names
+func (c *Client) GetEnvNames() ([]string, error) {
+	var err error
+	envNames := []string{}
+
+	param := map[string]interface{}{}
+	_, err = c.QueryData("api/admin/envNames", http.MethodGet, param, "data.envNames", &envNames)
+	if err != nil {
+		return envNames, err
+	}
+
+	return envNames, err
+}
+
+// AddEnv create kubernetes environment, return the response message and the auditID of admin log
+func (c *Client) AddEnv(envK8sYaml string) (string, string, error) {
+	return c.applyEnv("api/admin/env", envK8sYaml)
+}
+
+// UpdateEnv update kubernetes environment, return the response message and the auditID of admin log
+func (c *Client) UpdateEnv(envName, envK8sYaml string) (string, string, error) {
+	return c.applyEnv(fmt.Sprintf("api/admin/env/%s", envName), envK8sYaml)
+}
+
+func (c *Client) applyEnv(url, envK8sYaml string) (string, string, error) {
+	var err error
+	var msg string
+	var auditID string
+
+	param := map[string]interface{}{
+		"envK8sYaml": envK8sYaml,
+	}
+	result, _, err := c.Query(url, http.MethodPost, "", param, false)
+	if err != nil {
+		return msg, auditID, err
+	}
+	msg = result.Get("msg").String()
+	auditID = result.Get("data.auditID").String()
+	if auditID == "" {
+		err = &EmptyFieldError{Field: "auditID"}
+		return msg, auditID, err
+	}
+	return msg, auditID, err
+}
+
+// DeleteEnv delete kubernetes environment, return the response message
+func (c *Client) DeleteEnv(envName string) (string, error) {
+	return c.deleteItem(fmt.Sprintf("api/admin/env/%s", envName))
+}
+
+// ListComponentTemplates return component templates
+func (c *Client) ListComponentTemplates(page, perPage int) ([]pkg.ComponentTemplate, error) {
+	var err error
+	componentTemplates := []pkg.ComponentTemplate{}
+
+	param := map[string]interface{}{
+		"page":    page,
+		"perPage": perPage,
+	}
+	_, err = c.QueryData("api/admin/componentTemplates", http.MethodPost, param, "data.componentTemplates", &componentTemplates)
+	if err != nil {
+		return componentTemplates, err
+	}
+
+	return componentTemplates, err
+}
+
+// ListAllComponentTemplates return component templates of all pages
+func (c *Client) ListAllComponentTemplates() ([]pkg.ComponentTemplate, error) {
+	var err error
+	componentTemplates := []pkg.ComponentTemplate{}
+
+	items := []pkg.ComponentTemplate{}
+	err = ListPages(PageQuery{All: true}, &items, func(page, perPage int) error {
+		var err error
+		items, err = c.ListComponentTemplates(page, perPage)
+		return err
+	}, func() error {
+		componentTemplates = append(componentTemplates, items...)
+		return nil
+	})
+	if err != nil {
+		return componentTemplates, err
+	}
+
+	return componentTemplates, err
+}
+
+// AddComponentTemplate create component template, return the response message
+func (c *Client) AddComponentTemplate(componentTemplateName, componentTemplateDesc, componentTemplateYaml string) (string, error) {
+	return c.applyComponentTemplate("api/admin/componentTemplate", componentTemplateName, componentTemplateDesc, componentTemplateYaml)
+}
+
+// UpdateComponentTemplate update component template, return the response message
+func (c *Client) UpdateComponentTemplate(componentTemplateName, componentTemplateDesc, componentTemplateYaml string) (string, error) {
+	return c.applyComponentTemplate(fmt.Sprintf("api/admin/componentTemplate/%s", componentTemplateName), componentTemplateName, componentTemplateDesc, componentTemplateYaml)
+}
+
+func (c *Client) applyComponentTemplate(url, componentTemplateName, componentTemplateDesc, componentTemplateYaml string) (string, error) {
+	var err error
+	param := map[string]interface{}{
+		"componentTemplateName": componentTemplateName,
+		"componentTemplateDesc": componentTemplateDesc,
+		"componentTemplateYaml": componentTemplateYaml,
+	}
+	result, _, err := c.Query(url, http.MethodPost, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
+
+// DeleteComponentTemplate delete component template, return the response message
+func (c *Client) DeleteComponentTemplate(componentTemplateName string) (string, error) {
+	return c.deleteItem(fmt.Sprintf("api/admin/componentTemplate/%s", componentTemplateName))
+}
+
+func (c *Client) deleteItem(url string) (string, error) {
+	var err error
+	param := map[string]interface{}{}
+	result, _, err := c.Query(url, http.MethodDelete, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
@@ -0,0 +1,349 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Logger receive the debug and success messages of the client, doryctl use it to print verbose logs
+type Logger interface {
+	Debug(msg string)
+	Success(msg string)
+}
+
+type nopLogger struct{}
+
+func (l nopLogger) Debug(msg string)   {}
+func (l nopLogger) Success(msg string) {}
+
+// Client is a dory-core API client
+// Retries is the max retry times of a request, RetryMaxWait is the max wait seconds between retries,
+// RetryMethods are the http methods can be retried, default only GET is retried
+type Client struct {
+	ServerURL            string   `yaml:"serverURL" json:"serverURL" bson:"serverURL" validate:""`
+	AccessToken          string   `yaml:"accessToken" json:"accessToken" bson:"accessToken" validate:""`
+	Language             string   `yaml:"language" json:"language" bson:"language" validate:""`
+	Timeout              int      `yaml:"timeout" json:"timeout" bson:"timeout" validate:""`
+	Insecure             bool     `yaml:"insecure" json:"insecure" bson:"insecure" validate:""`
+	CertificateAuthority string   `yaml:"certificateAuthority" json:"certificateAuthority" bson:"certificateAuthority" validate:""`
+	ClientCertificate    string   `yaml:"clientCertificate" json:"clientCertificate" bson:"clientCertificate" validate:""`
+	ClientKey            string   `yaml:"clientKey" json:"clientKey" bson:"clientKey" validate:""`
+	Retries              int      `yaml:"retries" json:"retries" bson:"retries" validate:""`
+	RetryMaxWait         int      `yaml:"retryMaxWait" json:"retryMaxWait" bson:"retryMaxWait" validate:""`
+	RetryMethods         []string `yaml:"retryMethods" json:"retryMethods" bson:"retryMethods" validate:""`
+	Logger               Logger   `yaml:"-" json:"-" bson:"-" validate:""`
+}
+
+func NewClient(serverURL, accessToken string) *Client {
+	var c Client
+	c.ServerURL = serverURL
+	c.AccessToken = accessToken
+	c.Language = "EN"
+	c.Timeout = pkg.TimeoutDefault
+	c.Retries = pkg.RetriesDefault
+	c.RetryMaxWait = pkg.RetryMaxWaitDefault
+	c.RetryMethods = []string{http.MethodGet}
+	c.Logger = nopLogger{}
+	return &c
+}
+
+func (c *Client) logger() Logger {
+	if c.Logger == nil {
+		return nopLogger{}
+	}
+	return c.Logger
+}
+
+func (c *Client) tlsConfig() (*tls.Config, error) {
+	return pkg.NewTlsConfig(c.Insecure, c.CertificateAuthority, c.ClientCertificate, c.ClientKey)
+}
+
+func (c *Client) httpClient() (*http.Client, error) {
+	var err error
+	var client *http.Client
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return client, err
+	}
+	client = pkg.NewHttpClient(time.Second*time.Duration(c.Timeout), tlsConfig)
+	return client, err
+}
+
+func prettyJson(strJson string) (string, error) {
+	var err error
+	var strPretty string
+	var buf bytes.Buffer
+	err = json.Indent(&buf, []byte(strJson), "", "  ")
+	if err != nil {
+		return strPretty, err
+	}
+	strPretty = buf.String()
+	return strPretty, err
+}
+
+// Query send request to dory-core api, return the response json and X-User-Token header
+// transport errors and 429 / 502 / 503 / 504 responses are retried with backoff if the method is in RetryMethods
+func (c *Client) Query(url, method, userToken string, param map[string]interface{}, showSuccess bool) (gjson.Result, string, error) {
+	var err error
+	var result gjson.Result
+	var strJson string
+	var statusCode int
+	var req *http.Request
+	var resp *http.Response
+	var bs []byte
+	var reqBody []byte
+	var xUserToken string
+	log := c.logger()
+
+	if !strings.HasPrefix(url, "api/public/") && url != "api/account/accessToken" && (c.AccessToken == "" || c.ServerURL == "") {
+		err = ErrLoginRequired
+		return result, xUserToken, err
+	}
+	if c.ServerURL == "" {
+		err = ErrServerURLRequired
+		return result, xUserToken, err
+	}
+	url = fmt.Sprintf("%s/%s", c.ServerURL, url)
+
+	client, err := c.httpClient()
+	if err != nil {
+		return result, xUserToken, err
+	}
+
+	var strReqBody string
+	if len(param) > 0 {
+		reqBody, err = json.Marshal(param)
+		if err != nil {
+			return result, xUserToken, err
+		}
+		strReqBody = string(reqBody)
+	}
+	headerMap := map[string]string{}
+	headerMap["Language"] = c.Language
+	headerMap["Content-Type"] = "application/json"
+	if userToken != "" {
+		headerMap["X-User-Token"] = userToken
+	} else {
+		headerMap["X-Access-Token"] = c.AccessToken
+	}
+
+	headers := []string{}
+	for key, val := range headerMap {
+		if key == "X-User-Token" || key == "X-Access-Token" {
+			val = "******"
+		}
+		header := fmt.Sprintf(`-H "%s: %s"`, key, val)
+		headers = append(headers, header)
+	}
+	msgCurlParam := strings.Join(headers, " ")
+	if strReqBody != "" {
+		msgCurlParam = fmt.Sprintf("%s -d '%s'", msgCurlParam, strReqBody)
+	}
+	msgCurl := fmt.Sprintf(`curl -v -X%s %s '%s'`, method, msgCurlParam, url)
+	log.Debug(msgCurl)
+
+	for attempt := 0; ; attempt++ {
+		var reason string
+		if len(reqBody) > 0 {
+			req, err = http.NewRequest(method, url, bytes.NewReader(reqBody))
+		} else {
+			req, err = http.NewRequest(method, url, nil)
+		}
+		if err != nil {
+			return result, xUserToken, err
+		}
+		for key, val := range headerMap {
+			req.Header.Set(key, val)
+		}
+
+		resp, err = client.Do(req)
+		if err != nil {
+			if !isRetryableError(err) {
+				return result, xUserToken, err
+			}
+			reason = err.Error()
+		} else {
+			statusCode = resp.StatusCode
+			bs, err = ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return result, xUserToken, err
+			}
+			if !isRetryableStatus(statusCode) {
+				break
+			}
+			reason = resp.Status
+		}
+
+		if attempt >= c.Retries || !c.retryMethod(method) {
+			if err != nil {
+				return result, xUserToken, err
+			}
+			break
+		}
+		wait := c.retryWait(attempt, resp)
+		log.Debug(fmt.Sprintf("retry %d/%d %s %s in %s: %s", attempt+1, c.Retries, method, url, wait.String(), reason))
+		time.Sleep(wait)
+	}
+
+	strJson = string(bs)
+	result = gjson.Parse(strJson)
+
+	strPrettyJson, errJson := prettyJson(strJson)
+	if errJson != nil {
+		strPrettyJson = strJson
+	}
+
+	log.Debug(fmt.Sprintf("%s %s %s in %s", method, url, resp.Status, result.Get("duration").String()))
+	log.Debug(fmt.Sprintf("Response Header:"))
+	for key, val := range resp.Header {
+		log.Debug(fmt.Sprintf("  %s: %s", key, strings.Join(val, ",")))
+	}
+	log.Debug(fmt.Sprintf("Response Body:\n%s", strPrettyJson))
+
+	if statusCode < http.StatusOK || statusCode >= http.StatusBadRequest {
+		msg := result.Get("msg").String()
+		if errJson != nil {
+			msg = strings.TrimSpace(strJson)
+		}
+		err = &APIError{
+			Method:     method,
+			URL:        url,
+			StatusCode: statusCode,
+			Status:     result.Get("status").String(),
+			Msg:        msg,
+		}
+		return result, xUserToken, err
+	}
+	if errJson != nil {
+		err = errJson
+		return result, xUserToken, err
+	}
+	xUserToken = resp.Header.Get("X-User-Token")
+
+	msg := fmt.Sprintf("%s %s [%s] %s", method, url, result.Get("status").String(), result.Get("msg").String())
+	if showSuccess {
+		log.Success(msg)
+	} else {
+		log.Debug(msg)
+	}
+
+	return result, xUserToken, err
+}
+
+// QueryData send request to dory-core api and unmarshal the response json path into data
+func (c *Client) QueryData(url, method string, param map[string]interface{}, path string, data interface{}) (gjson.Result, error) {
+	var err error
+	result, _, err := c.Query(url, method, "", param, false)
+	if err != nil {
+		return result, err
+	}
+	r := result.Get(path)
+	if !r.Exists() {
+		return result, err
+	}
+	err = json.Unmarshal([]byte(r.Raw), data)
+	if err != nil {
+		err = fmt.Errorf("parse %s %s error: %s", url, path, err.Error())
+		return result, err
+	}
+	return result, err
+}
+
+// DialWebsocket connect to dory-core websocket api
+func (c *Client) DialWebsocket(url string) (*websocket.Conn, *http.Response, error) {
+	var err error
+	var conn *websocket.Conn
+	var resp *http.Response
+
+	var serverURL string
+	if strings.HasPrefix(c.ServerURL, "http://") {
+		serverURL = strings.Replace(c.ServerURL, "http://", "ws://", 1)
+	} else if strings.HasPrefix(c.ServerURL, "https://") {
+		serverURL = strings.Replace(c.ServerURL, "https://", "wss://", 1)
+	}
+	if serverURL == "" {
+		err = ErrServerURLInvalid
+		return conn, resp, err
+	}
+
+	if c.AccessToken == "" {
+		err = ErrLoginRequired
+		return conn, resp, err
+	}
+	url = fmt.Sprintf("%s/%s", serverURL, url)
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return conn, resp, err
+	}
+
+	header := http.Header{}
+	header.Add("X-Access-Token", c.AccessToken)
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: time.Second * time.Duration(c.Timeout),
+		TLSClientConfig:  tlsConfig,
+	}
+
+	conn, resp, err = dialer.Dial(url, header)
+	if err != nil {
+		return conn, resp, err
+	}
+	c.logger().Debug(fmt.Sprintf("WEBSOCKET %s %s", url, resp.Status))
+
+	return conn, resp, err
+}
+
+// Login with username and password, return the user token
+func (c *Client) Login(username, password string) (string, error) {
+	var err error
+	param := map[string]interface{}{
+		"username": username,
+		"password": password,
+	}
+	_, xUserToken, err := c.Query("api/public/login", http.MethodPost, "", param, true)
+	if err != nil {
+		return xUserToken, err
+	}
+	return xUserToken, err
+}
+
+// CreateAccessToken create an access token by user token
+func (c *Client) CreateAccessToken(userToken, accessTokenName string, expireDays int) (string, error) {
+	var err error
+	param := map[string]interface{}{
+		"accessTokenName": accessTokenName,
+		"expireDays":      expireDays,
+	}
+	result, _, err := c.Query("api/account/accessToken", http.MethodPost, userToken, param, true)
+	if err != nil {
+		return "", err
+	}
+	accessToken := result.Get("data.accessToken").String()
+	if accessToken == "" {
+		err = &EmptyFieldError{Field: "accessToken"}
+		return accessToken, err
+	}
+	return accessToken, err
+}
+
+// About return dory-core app name and version
+func (c *Client) About() (string, string, error) {
+	var err error
+	param := map[string]interface{}{}
+	result, _, err := c.Query("api/public/about", http.MethodGet, "", param, false)
+	if err != nil {
+		return "", "", err
+	}
+	return result.Get("data.app").String(), result.Get("data.version").String(), err
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"net/http"
+)
+
+// DefUpdateURLParam return the url kind and request param of DefUpdate
+func DefUpdateURLParam(defUpdate pkg.DefUpdate) (string, map[string]interface{}, error) {
+	var err error
+	param := map[string]interface{}{
+		"envName":        defUpdate.EnvName,
+		"customStepName": defUpdate.CustomStepName,
+		"branchName":     defUpdate.BranchName,
+	}
+
+	bs, err := pkg.YamlIndent(defUpdate.Def)
+	if err != nil {
+		return "", param, err
+	}
+
+	urlKind := defUpdate.Kind
+	switch defUpdate.Kind {
+	case "buildDefs":
+		param["buildDefsYaml"] = string(bs)
+	case "packageDefs":
+		param["packageDefsYaml"] = string(bs)
+	case "deployContainerDefs":
+		param["deployContainerDefsYaml"] = string(bs)
+	case "customStepDef":
+		param["customStepDefYaml"] = string(bs)
+		if defUpdate.EnvName != "" {
+			urlKind = fmt.Sprintf("%s/env", urlKind)
+		}
+	case "dockerIgnoreDefs":
+		param["dockerIgnoreDefsYaml"] = string(bs)
+	case "customOpsDefs":
+		param["customOpsDefsYaml"] = string(bs)
+	case "pipelineDef":
+		param["pipelineDefYaml"] = string(bs)
+	default:
+		err = fmt.Errorf("kind %s not support", defUpdate.Kind)
+		return urlKind, param, err
+	}
+
+	return urlKind, param, err
+}
+
+// DefUpdateHeader return the log header of DefUpdate, example: [test-project1/buildDefs] {"envName":"test"}
+func DefUpdateHeader(defUpdate pkg.DefUpdate) string {
+	paramOutput := map[string]interface{}{
+		"envName":        defUpdate.EnvName,
+		"customStepName": defUpdate.CustomStepName,
+		"branchName":     defUpdate.BranchName,
+	}
+	paramOutput = pkg.RemoveMapEmptyItems(paramOutput)
+	bs, _ := json.Marshal(paramOutput)
+	return fmt.Sprintf("[%s/%s] %s", defUpdate.ProjectName, defUpdate.Kind, string(bs))
+}
+
+// ApplyDef update project definition, return the response message
+func (c *Client) ApplyDef(defUpdate pkg.DefUpdate) (string, error) {
+	var err error
+	urlKind, param, err := DefUpdateURLParam(defUpdate)
+	if err != nil {
+		return "", err
+	}
+	result, _, err := c.Query(fmt.Sprintf("api/cicd/projectDef/%s/%s", defUpdate.ProjectName, urlKind), http.MethodPost, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
+
+// ApplyDefs update project definitions in order, stop at the first error
+func (c *Client) ApplyDefs(defUpdates []pkg.DefUpdate) ([]string, error) {
+	var err error
+	msgs := []string{}
+	for _, defUpdate := range defUpdates {
+		msg, err := c.ApplyDef(defUpdate)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", DefUpdateHeader(defUpdate), err)
+			return msgs, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, err
+}
+
+// CloneDef clone deployContainerDefs or customStepDef to other envs, return the response message
+func (c *Client) CloneDef(defClone pkg.DefClone, customStepName string, envNames []string) (string, error) {
+	var err error
+
+	bs, err := pkg.YamlIndent(defClone.Def)
+	if err != nil {
+		return "", err
+	}
+	urlKind := defClone.Kind
+	param := map[string]interface{}{
+		"envNames": envNames,
+	}
+	switch defClone.Kind {
+	case "deployContainerDefs":
+		param["deployContainerDefsYaml"] = string(bs)
+	case "customStepDef":
+		urlKind = fmt.Sprintf("%s/env", urlKind)
+		param["customStepName"] = customStepName
+		param["customStepDefYaml"] = string(bs)
+	default:
+		err = fmt.Errorf("kind %s not support", defClone.Kind)
+		return "", err
+	}
+	result, _, err := c.Query(fmt.Sprintf("api/cicd/projectDef/%s/%s", defClone.ProjectName, urlKind), http.MethodPut, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
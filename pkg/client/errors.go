@@ -0,0 +1,77 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrLoginRequired     = errors.New("please login first")
+	ErrServerURLRequired = errors.New("serverURL required")
+	ErrServerURLInvalid  = errors.New("serverURL must start with http:// or https://")
+)
+
+// APIError is returned when dory-core response with http status code < 200 or >= 400
+type APIError struct {
+	Method     string `yaml:"method" json:"method" bson:"method" validate:""`
+	URL        string `yaml:"url" json:"url" bson:"url" validate:""`
+	StatusCode int    `yaml:"statusCode" json:"statusCode" bson:"statusCode" validate:""`
+	Status     string `yaml:"status" json:"status" bson:"status" validate:""`
+	Msg        string `yaml:"msg" json:"msg" bson:"msg" validate:""`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s [%s] %s", e.Method, e.URL, e.Status, e.Msg)
+}
+
+// NotFoundError is returned when dory-core response success but the resource not exists
+type NotFoundError struct {
+	Kind string `yaml:"kind" json:"kind" bson:"kind" validate:""`
+	Name string `yaml:"name" json:"name" bson:"name" validate:""`
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %s not exists", e.Kind, e.Name)
+}
+
+// EmptyFieldError is returned when a required field in dory-core response is empty
+type EmptyFieldError struct {
+	Field string `yaml:"field" json:"field" bson:"field" validate:""`
+}
+
+func (e *EmptyFieldError) Error() string {
+	return fmt.Sprintf("%s is empty", e.Field)
+}
+
+func IsNotFound(err error) bool {
+	var errNotFound *NotFoundError
+	if errors.As(err, &errNotFound) {
+		return true
+	}
+	var errAPI *APIError
+	if errors.As(err, &errAPI) {
+		return errAPI.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+func IsUnauthorized(err error) bool {
+	if errors.Is(err, ErrLoginRequired) {
+		return true
+	}
+	var errAPI *APIError
+	if errors.As(err, &errAPI) {
+		return errAPI.StatusCode == http.StatusUnauthorized || errAPI.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// IsTemporary check the error is a transport error or a 429 / 502 / 503 / 504 response, the request may succeed later
+func IsTemporary(err error) bool {
+	var errAPI *APIError
+	if errors.As(err, &errAPI) {
+		return isRetryableStatus(errAPI.StatusCode)
+	}
+	return isRetryableError(err)
+}
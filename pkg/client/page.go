@@ -0,0 +1,74 @@
+package client
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// PerPageDefault is the default page size of list query
+const PerPageDefault = 200
+
+// PageQuery is the pagination of list query, Page is the first page to list,
+// with All true pages are listed until exhausted, Limit is the max number of items in total, 0 means no limit,
+// if All is false and Limit is 0, only one page is listed
+type PageQuery struct {
+	Page    int  `yaml:"page" json:"page" bson:"page" validate:""`
+	PerPage int  `yaml:"perPage" json:"perPage" bson:"perPage" validate:""`
+	All     bool `yaml:"all" json:"all" bson:"all" validate:""`
+	Limit   int  `yaml:"limit" json:"limit" bson:"limit" validate:""`
+}
+
+// EachPage call listPage from query.Page until the page is not full or the items reached query.Limit,
+// listPage return the number of items in the page, it should only handle the first limit items, limit 0 means all items
+func EachPage(query PageQuery, listPage func(page, perPage, limit int) (int, error)) error {
+	var err error
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PerPage < 1 {
+		query.PerPage = PerPageDefault
+	}
+	var total int
+	for page := query.Page; ; page++ {
+		var limit int
+		if query.Limit > 0 {
+			limit = query.Limit - total
+		}
+		count, err := listPage(page, query.PerPage, limit)
+		if err != nil {
+			return err
+		}
+		total = total + count
+		if count < query.PerPage {
+			break
+		}
+		if query.Limit > 0 && total >= query.Limit {
+			break
+		}
+		if !query.All && query.Limit == 0 {
+			break
+		}
+	}
+	return err
+}
+
+// ListPages list items page by page, listPage query the page and decode the items of the page into items,
+// items must be a pointer to slice, it is cut to the first items of query.Limit, then handle process the items of the page
+func ListPages(query PageQuery, items interface{}, listPage func(page, perPage int) error, handle func() error) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("list pages error: items must be a pointer to slice")
+	}
+	v = v.Elem()
+	return EachPage(query, func(page, perPage, limit int) (int, error) {
+		err := listPage(page, perPage)
+		if err != nil {
+			return 0, err
+		}
+		count := v.Len()
+		if limit > 0 && count > limit {
+			v.Set(v.Slice(0, limit))
+		}
+		return count, handle()
+	})
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"net/http"
+)
+
+// GetProjectNames return all project names which current user can access
+func (c *Client) GetProjectNames() ([]string, error) {
+	var err error
+	projectNames := []string{}
+	param := map[string]interface{}{}
+	_, err = c.QueryData("api/cicd/projectNames", http.MethodGet, param, "data.projectNames", &projectNames)
+	if err != nil {
+		return projectNames, err
+	}
+	return projectNames, err
+}
+
+// GetProjectDef return project definitions
+func (c *Client) GetProjectDef(projectName string) (pkg.ProjectOutput, error) {
+	var err error
+	var project pkg.ProjectOutput
+
+	param := map[string]interface{}{}
+	_, err = c.QueryData(fmt.Sprintf("api/cicd/projectDef/%s", projectName), http.MethodGet, param, "data.project", &project)
+	if err != nil {
+		return project, err
+	}
+	if project.ProjectInfo.ProjectName == "" {
+		err = &NotFoundError{Kind: "projectName", Name: projectName}
+		return project, err
+	}
+
+	return project, err
+}
+
+// ListProjects return projects and their pipelines, filters by projectNames and projectTeam
+func (c *Client) ListProjects(projectNames []string, projectTeam string, page, perPage int) ([]pkg.Project, error) {
+	var err error
+	projects := []pkg.Project{}
+
+	param := map[string]interface{}{
+		"projectNames": projectNames,
+		"projectTeam":  projectTeam,
+		"page":         page,
+		"perPage":      perPage,
+	}
+	_, err = c.QueryData("api/cicd/projects", http.MethodPost, param, "data.projects", &projects)
+	if err != nil {
+		return projects, err
+	}
+
+	return projects, err
+}
+
+// ListAllProjects return projects and their pipelines of all pages, filters by projectNames and projectTeam
+func (c *Client) ListAllProjects(projectNames []string, projectTeam string) ([]pkg.Project, error) {
+	var err error
+	projects := []pkg.Project{}
+
+	items := []pkg.Project{}
+	err = ListPages(PageQuery{All: true}, &items, func(page, perPage int) error {
+		var err error
+		items, err = c.ListProjects(projectNames, projectTeam, page, perPage)
+		return err
+	}, func() error {
+		projects = append(projects, items...)
+		return nil
+	})
+	if err != nil {
+		return projects, err
+	}
+
+	return projects, err
+}
+
+// ListPipelines return pipelines of projects, filters by projectNames
+func (c *Client) ListPipelines(projectNames []string) ([]pkg.Pipeline, error) {
+	var err error
+	pipelines := []pkg.Pipeline{}
+
+	projects, err := c.ListAllProjects(projectNames, "")
+	if err != nil {
+		return pipelines, err
+	}
+	for _, project := range projects {
+		pipelines = append(pipelines, project.Pipelines...)
+	}
+
+	return pipelines, err
+}
+
+// AddProject create a project, return the auditID of the create project admin log
+func (c *Client) AddProject(projectAdd pkg.ProjectAdd) (string, error) {
+	var err error
+	var auditID string
+
+	param := map[string]interface{}{
+		"projectName":      projectAdd.ProjectName,
+		"projectDesc":      projectAdd.ProjectDesc,
+		"projectShortName": projectAdd.ProjectShortName,
+		"projectTeam":      projectAdd.ProjectTeam,
+		"envName":          projectAdd.EnvName,
+	}
+	result, _, err := c.Query("api/admin/project", http.MethodPost, "", param, false)
+	if err != nil {
+		return auditID, err
+	}
+	auditID = result.Get("data.auditID").String()
+	if auditID == "" {
+		err = &EmptyFieldError{Field: "auditID"}
+		return auditID, err
+	}
+
+	return auditID, err
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const retryBaseWait = time.Millisecond * 500
+
+// retryMethod return true if the http method can be retried
+func (c *Client) retryMethod(method string) bool {
+	for _, m := range c.RetryMethods {
+		if strings.ToUpper(m) == method {
+			return true
+		}
+	}
+	return false
+}
+
+// retryWait return the wait duration before next retry, use exponential backoff with jitter,
+// Retry-After header is honored if response set it, both are limited by RetryMaxWait
+func (c *Client) retryWait(attempt int, resp *http.Response) time.Duration {
+	maxWait := time.Second * time.Duration(c.RetryMaxWait)
+	if maxWait <= 0 {
+		maxWait = retryBaseWait
+	}
+
+	if resp != nil {
+		seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if err == nil && seconds >= 0 {
+			wait := time.Second * time.Duration(seconds)
+			if wait > maxWait {
+				wait = maxWait
+			}
+			return wait
+		}
+	}
+
+	wait := retryBaseWait << uint(attempt)
+	if wait <= 0 || wait > maxWait {
+		wait = maxWait
+	}
+	// equal jitter: keep half of the backoff, randomize the other half
+	wait = wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	return wait
+}
+
+// isRetryableStatus return true if the response status code is 429, 502, 503 or 504
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRetryableError return true if the request error is a transport error: connection refused or reset, timeout,
+// dns or connection closed before response, certificate errors and other errors are not retried
+func isRetryableError(err error) bool {
+	var errUnknownAuthority x509.UnknownAuthorityError
+	var errHostname x509.HostnameError
+	var errCertificateInvalid x509.CertificateInvalidError
+	if errors.As(err, &errUnknownAuthority) || errors.As(err, &errHostname) || errors.As(err, &errCertificateInvalid) {
+		return false
+	}
+	if strings.Contains(err.Error(), "tls: ") {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	var errNet net.Error
+	if errors.As(err, &errNet) && errNet.Timeout() {
+		return true
+	}
+	var errOp *net.OpError
+	var errDNS *net.DNSError
+	if errors.As(err, &errOp) || errors.As(err, &errDNS) {
+		return true
+	}
+	return false
+}
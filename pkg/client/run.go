@@ -0,0 +1,126 @@
+package client
+
+import (
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"net/http"
+)
+
+// RunQuery is the filters of ListRuns
+type RunQuery struct {
+	ProjectNames  []string `yaml:"projectNames" json:"projectNames" bson:"projectNames" validate:""`
+	PipelineNames []string `yaml:"pipelineNames" json:"pipelineNames" bson:"pipelineNames" validate:""`
+	RunNames      []string `yaml:"runNames" json:"runNames" bson:"runNames" validate:""`
+	StatusResults []string `yaml:"statusResults" json:"statusResults" bson:"statusResults" validate:""`
+	StartDate     string   `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
+	EndDate       string   `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
+	Page          int      `yaml:"page" json:"page" bson:"page" validate:""`
+	PerPage       int      `yaml:"perPage" json:"perPage" bson:"perPage" validate:""`
+}
+
+// ListRuns return pipeline runs filters by RunQuery
+func (c *Client) ListRuns(query RunQuery) ([]pkg.Run, error) {
+	var err error
+	runs := []pkg.Run{}
+
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PerPage < 1 {
+		query.PerPage = 200
+	}
+	param := map[string]interface{}{
+		"projectNames":  query.ProjectNames,
+		"pipelineNames": query.PipelineNames,
+		"runNames":      query.RunNames,
+		"statusResults": query.StatusResults,
+		"startTimeRage": map[string]string{
+			"startDate": query.StartDate,
+			"endDate":   query.EndDate,
+		},
+		"page":    query.Page,
+		"perPage": query.PerPage,
+	}
+	_, err = c.QueryData("api/cicd/runs", http.MethodPost, param, "data.runs", &runs)
+	if err != nil {
+		return runs, err
+	}
+
+	return runs, err
+}
+
+// GetRun return pipeline run by runName
+func (c *Client) GetRun(runName string) (pkg.Run, error) {
+	var err error
+	run := pkg.Run{}
+
+	param := map[string]interface{}{}
+	_, err = c.QueryData(fmt.Sprintf("api/cicd/run/%s", runName), http.MethodGet, param, "data.run", &run)
+	if err != nil {
+		return run, err
+	}
+	if run.RunName == "" {
+		err = &NotFoundError{Kind: "runName", Name: runName}
+		return run, err
+	}
+
+	return run, err
+}
+
+// AbortRun abort a running pipeline run, return the response message
+func (c *Client) AbortRun(runName string) (string, error) {
+	var err error
+	param := map[string]interface{}{}
+	result, _, err := c.Query(fmt.Sprintf("api/cicd/run/%s", runName), http.MethodPatch, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
+
+// GetRunInput return the waiting input of pipeline run
+func (c *Client) GetRunInput(runName string) (pkg.RunInput, error) {
+	var err error
+	var runInput pkg.RunInput
+
+	param := map[string]interface{}{}
+	_, err = c.QueryData(fmt.Sprintf("api/cicd/run/%s/input", runName), http.MethodGet, param, "data", &runInput)
+	if err != nil {
+		return runInput, err
+	}
+
+	return runInput, err
+}
+
+// InputRun post the input value of pipeline run phase
+func (c *Client) InputRun(runName, phaseID, inputValue string) (string, error) {
+	var err error
+	param := map[string]interface{}{
+		"phaseID":    phaseID,
+		"inputValue": inputValue,
+	}
+	result, _, err := c.Query(fmt.Sprintf("api/cicd/run/%s/input", runName), http.MethodPost, "", param, false)
+	if err != nil {
+		return "", err
+	}
+	return result.Get("msg").String(), err
+}
+
+// ExecutePipeline start a pipeline run, return the runName
+func (c *Client) ExecutePipeline(pipelineName string) (string, error) {
+	var err error
+	var runName string
+
+	param := map[string]interface{}{}
+	result, _, err := c.Query(fmt.Sprintf("api/cicd/pipeline/%s", pipelineName), http.MethodPost, "", param, false)
+	if err != nil {
+		return runName, err
+	}
+	runName = result.Get("data.runName").String()
+	if runName == "" {
+		err = &EmptyFieldError{Field: "runName"}
+		return runName, err
+	}
+
+	return runName, err
+}
@@ -2,6 +2,8 @@ package pkg
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"embed"
 	"fmt"
 	"gopkg.in/yaml.v3"
@@ -9,6 +11,7 @@ import (
 	"io/fs"
 	"math/rand"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"reflect"
@@ -289,3 +292,55 @@ func RemoveMapEmptyItems(m map[string]interface{}) map[string]interface{} {
 	m2 := m
 	return m2
 }
+
+// NewTlsConfig return tls config, verify server certificate unless insecure is true
+// caFile is the certificate authority bundle to verify server certificate, certFile and keyFile are the client certificate and key
+func NewTlsConfig(insecure bool, caFile, certFile, keyFile string) (*tls.Config, error) {
+	var err error
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecure,
+	}
+
+	if caFile != "" {
+		bs, err := os.ReadFile(caFile)
+		if err != nil {
+			err = fmt.Errorf("read certificate authority %s error: %s", caFile, err.Error())
+			return tlsConfig, err
+		}
+		certPool, err := x509.SystemCertPool()
+		if err != nil || certPool == nil {
+			certPool = x509.NewCertPool()
+		}
+		if !certPool.AppendCertsFromPEM(bs) {
+			err = fmt.Errorf("read certificate authority %s error: no valid PEM certificates", caFile)
+			return tlsConfig, err
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			err = fmt.Errorf("client certificate and client key must be set together")
+			return tlsConfig, err
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			err = fmt.Errorf("read client certificate %s and key %s error: %s", certFile, keyFile, err.Error())
+			return tlsConfig, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, err
+}
+
+// NewHttpClient return http client with its own transport, do not change http.DefaultTransport
+func NewHttpClient(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+	return client
+}
@@ -2,7 +2,6 @@ package pkg
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"github.com/tidwall/gjson"
@@ -10,6 +9,7 @@ import (
 	"gopkg.in/yaml.v3"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -327,6 +327,37 @@ func (ic *InstallConfig) UnmarshalMapValues() (map[string]interface{}, error) {
 	return vals, err
 }
 
+// HarborCaCrtPath return the certificate authority to verify harbor server certificate
+// default is the ca.crt in docker certs directory, harbor self signed certificates are copied there when installing
+func (ic *InstallConfig) HarborCaCrtPath() string {
+	if ic.ImageRepo.CaCrtPath != "" {
+		return ic.ImageRepo.CaCrtPath
+	}
+	domainName := ic.ImageRepo.Internal.DomainName
+	if domainName == "" {
+		domainName = ic.ImageRepo.External.Url
+	}
+	caCrtPath := fmt.Sprintf("%s/%s/ca.crt", DirDockerCerts, domainName)
+	_, err := os.Stat(caCrtPath)
+	if err != nil {
+		return ""
+	}
+	return caCrtPath
+}
+
+// KubernetesCaCrtPath return the certificate authority to verify kubernetes api server certificate
+// default is the kubernetes cluster ca.crt on master node
+func (ic *InstallConfig) KubernetesCaCrtPath() string {
+	if ic.Kubernetes.CaCrtPath != "" {
+		return ic.Kubernetes.CaCrtPath
+	}
+	_, err := os.Stat(KubernetesCaCrtPath)
+	if err != nil {
+		return ""
+	}
+	return KubernetesCaCrtPath
+}
+
 func (ic *InstallConfig) HarborQuery(url, method string, param map[string]interface{}) (string, int, error) {
 	var err error
 	var strJson string
@@ -334,10 +365,11 @@ func (ic *InstallConfig) HarborQuery(url, method string, param map[string]interf
 	var req *http.Request
 	var resp *http.Response
 	var bs []byte
-	client := &http.Client{
-		Timeout: time.Second * time.Duration(time.Second*5),
+	tlsConfig, err := NewTlsConfig(ic.ImageRepo.Insecure, ic.HarborCaCrtPath(), "", "")
+	if err != nil {
+		return strJson, statusCode, err
 	}
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	client := NewHttpClient(time.Second*5, tlsConfig)
 
 	domainName := ic.ImageRepo.Internal.DomainName
 	username := "admin"
@@ -413,10 +445,11 @@ func (ic *InstallConfig) KubernetesQuery(url, method string, param map[string]in
 	var req *http.Request
 	var resp *http.Response
 	var bs []byte
-	client := &http.Client{
-		Timeout: time.Second * time.Duration(time.Second*5),
+	tlsConfig, err := NewTlsConfig(ic.Kubernetes.Insecure, ic.KubernetesCaCrtPath(), ic.Kubernetes.ClientCrtPath, ic.Kubernetes.ClientKeyPath)
+	if err != nil {
+		return strJson, statusCode, err
 	}
-	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	client := NewHttpClient(time.Second*5, tlsConfig)
 
 	url = fmt.Sprintf("https://%s:%d%s", ic.Kubernetes.Host, ic.Kubernetes.Port, url)
 
@@ -481,3 +514,86 @@ func (ic *InstallConfig) KubernetesPodsGet(namespace string) ([]KubePod, error)
 	pods = podList.Items
 	return pods, err
 }
+
+// MigrateLegacy move the legacy single server settings into context default
+func (dc *DoryConfig) MigrateLegacy() bool {
+	var migrated bool
+	if dc.ServerURL == "" && dc.AccessToken == "" {
+		return migrated
+	}
+	_, idx := dc.GetContext(ContextNameDefault)
+	if len(dc.Contexts) == 0 || idx < 0 {
+		doryContext := DoryContext{
+			Name:        ContextNameDefault,
+			ServerURL:   dc.ServerURL,
+			Insecure:    dc.Insecure,
+			Timeout:     dc.Timeout,
+			AccessToken: dc.AccessToken,
+			Language:    dc.Language,
+		}
+		dc.Contexts = append(dc.Contexts, doryContext)
+		if dc.CurrentContext == "" {
+			dc.CurrentContext = ContextNameDefault
+		}
+	}
+	dc.ServerURL = ""
+	dc.Insecure = false
+	dc.Timeout = 0
+	dc.AccessToken = ""
+	dc.Language = ""
+	migrated = true
+	return migrated
+}
+
+// GetContext return the context and its index, index is -1 if context not exists
+func (dc *DoryConfig) GetContext(name string) (DoryContext, int) {
+	for i, doryContext := range dc.Contexts {
+		if doryContext.Name == name {
+			return doryContext, i
+		}
+	}
+	return DoryContext{}, -1
+}
+
+// SetContext add the context or replace the context with the same name
+func (dc *DoryConfig) SetContext(doryContext DoryContext) {
+	_, idx := dc.GetContext(doryContext.Name)
+	if idx < 0 {
+		dc.Contexts = append(dc.Contexts, doryContext)
+	} else {
+		dc.Contexts[idx] = doryContext
+	}
+}
+
+func (dc *DoryConfig) DeleteContext(name string) error {
+	var err error
+	_, idx := dc.GetContext(name)
+	if idx < 0 {
+		err = fmt.Errorf("context %s not exists", name)
+		return err
+	}
+	dc.Contexts = append(dc.Contexts[:idx], dc.Contexts[idx+1:]...)
+	if dc.CurrentContext == name {
+		dc.CurrentContext = ""
+	}
+	return err
+}
+
+func (dc *DoryConfig) RenameContext(name, newName string) error {
+	var err error
+	_, idx := dc.GetContext(name)
+	if idx < 0 {
+		err = fmt.Errorf("context %s not exists", name)
+		return err
+	}
+	_, idxNew := dc.GetContext(newName)
+	if idxNew >= 0 {
+		err = fmt.Errorf("context %s already exists", newName)
+		return err
+	}
+	dc.Contexts[idx].Name = newName
+	if dc.CurrentContext == name {
+		dc.CurrentContext = newName
+	}
+	return err
+}
@@ -10,10 +10,73 @@ const (
 	ConfigDirDefault     = ".doryctl"
 	ConfigFileDefault    = "config.yaml"
 	EnvVarConfigFile     = "DORYCONFIG"
+	ContextNameDefault   = "default"
 	DirInstallScripts    = "install_scripts"
 	DirInstallConfigs    = "install_configs"
+	ProjectExportFile    = "project.yaml"
+	ProjectExportDefsDir = "defs"
+	RunInputsRecordDir   = "inputs" // pipeline run input answers recorded by doryctl, in the directory of config file
 
-	TimeoutDefault = 5
+	RunInputsRecordKeepDays = 30 // pipeline run input answers records not modified in days are removed
+
+	TimeoutDefault      = 5
+	RetriesDefault      = 3
+	RetryMaxWaitDefault = 10
+
+	DefManagedSelectorDefault = "dory-engine.io/managed-by=doryctl"
+
+	// operators of definitions metadata selector requirements
+	SelectorOpEquals    = "="
+	SelectorOpNotEquals = "!="
+	SelectorOpIn        = "in"
+	SelectorOpNotIn     = "notin"
+	SelectorOpExists    = "exists"
+	SelectorOpNotExists = "!"
+
+	NamePatternRegexPrefix = "regex:" // module name pattern prefix of regular expression, otherwise glob pattern
+
+	// def patch types
+	PatchTypeSjson = "sjson"
+	PatchTypeJson  = "json"
+	PatchTypeMerge = "merge"
+
+	// operations of JSON Patch (RFC 6902)
+	JSONPatchOpAdd     = "add"
+	JSONPatchOpRemove  = "remove"
+	JSONPatchOpReplace = "replace"
+	JSONPatchOpMove    = "move"
+	JSONPatchOpCopy    = "copy"
+	JSONPatchOpTest    = "test"
+
+	EditorDefault = "vi" // editor used by def edit and admin edit when $EDITOR is not set
+
+	// def lint rules
+	LintRuleParse     = "parse"
+	LintRuleMetadata  = "metadata"
+	LintRuleValidate  = "validate"
+	LintRuleName      = "name-format"
+	LintRuleDuplicate = "duplicate"
+	LintRuleReference = "reference"
+	LintRulePort      = "port"
+	LintRuleQuantity  = "quantity"
+
+	// project check rules and severities
+	CheckRuleReference   = "reference"
+	CheckRuleNodePort    = "node-port"
+	CheckRuleDuplicate   = "duplicate"
+	CheckRuleMissingEnv  = "missing-env"
+	CheckRuleServerError = "server-error"
+	SeverityError        = "error"
+	SeverityWarning      = "warning"
+	SeverityInfo         = "info"
+
+	// JSON Schema generated by doryctl schema
+	JSONSchemaDraft         = "http://json-schema.org/draft-07/schema#"
+	SchemaNameInstallConfig = "installConfig"
+	SchemaFileSuffix        = ".schema.json"
+
+	DirDockerCerts      = "/etc/docker/certs.d"
+	KubernetesCaCrtPath = "/etc/kubernetes/pki/ca.crt"
 
 	LogTypeInfo    = "INFO"
 	LogTypeWarning = "WARNING"
@@ -21,10 +84,32 @@ const (
 
 	StatusSuccess = "SUCCESS"
 	StatusFail    = "FAIL"
+	StatusAbort   = "ABORT"
+	StatusRunning = "RUNNING"
+	StatusInput   = "INPUT"
+
+	RunTimeLayout = "2006-01-02 15:04:05"
+
+	// process exit codes of waiting pipeline run
+	ExitCodeSuccess = 0
+	ExitCodeError   = 1
+	ExitCodeFail    = 2
+	ExitCodeAbort   = 3
+	ExitCodeTimeout = 4
+	ExitCodeInput   = 5
+
+	// process exit codes of def diff, the same as diff(1)
+	ExitCodeDiffFound   = 1
+	ExitCodeDiffTrouble = 2
 
 	InputValueAbort   = "ABORT"
 	InputValueConfirm = "CONFIRM"
 
+	// policies for pipeline run inputs not answered
+	InputPolicyPrompt = "prompt"
+	InputPolicyAbort  = "abort"
+	InputPolicyFirst  = "first"
+
 	LogStatusInput = "INPUT" // special usage for websocket send notice directives
 )
 
@@ -46,6 +131,17 @@ var (
 		"ignore":   "dockerIgnoreDefs",
 	}
 
+	// DefKindsApplyOrder is the order to apply project definitions, definitions depend on the previous kinds
+	DefKindsApplyOrder = []string{
+		"buildDefs",
+		"packageDefs",
+		"deployContainerDefs",
+		"customStepDef",
+		"pipelineDef",
+		"customOpsDefs",
+		"dockerIgnoreDefs",
+	}
+
 	AdminCmdKinds = map[string]string{
 		"all":    "",
 		"user":   "user",
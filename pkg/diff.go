@@ -0,0 +1,131 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+type diffLine struct {
+	Op   byte
+	Text string
+	ANum int
+	BNum int
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines return the line operations to change a into b, base on the longest common subsequence
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < n && j < m {
+		if a[i] == b[j] {
+			lines = append(lines, diffLine{Op: ' ', Text: a[i], ANum: i, BNum: j})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			lines = append(lines, diffLine{Op: '-', Text: a[i], ANum: i, BNum: j})
+			i++
+		} else {
+			lines = append(lines, diffLine{Op: '+', Text: b[j], ANum: i, BNum: j})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, diffLine{Op: '-', Text: a[i], ANum: i, BNum: j})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, diffLine{Op: '+', Text: b[j], ANum: i, BNum: j})
+	}
+	return lines
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// UnifiedDiff return the unified format diff of a and b, return empty string if a and b are the same
+// contextLines is the number of unchanged lines around the changes
+func UnifiedDiff(fromName, toName, a, b string, contextLines int) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	// find the changed lines, then merge them into hunks with context lines
+	type hunk struct {
+		begin int
+		end   int
+	}
+	hunks := []hunk{}
+	for idx, line := range lines {
+		if line.Op == ' ' {
+			continue
+		}
+		begin := idx - contextLines
+		if begin < 0 {
+			begin = 0
+		}
+		end := idx + contextLines + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		if len(hunks) > 0 && begin <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+		} else {
+			hunks = append(hunks, hunk{begin: begin, end: end})
+		}
+	}
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n", fromName))
+	sb.WriteString(fmt.Sprintf("+++ %s\n", toName))
+	for _, h := range hunks {
+		var aCount, bCount int
+		for _, line := range lines[h.begin:h.end] {
+			if line.Op != '+' {
+				aCount++
+			}
+			if line.Op != '-' {
+				bCount++
+			}
+		}
+		first := lines[h.begin]
+		sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(first.ANum, aCount), hunkRange(first.BNum, bCount)))
+		for _, line := range lines[h.begin:h.end] {
+			sb.WriteString(fmt.Sprintf("%c%s\n", line.Op, line.Text))
+		}
+	}
+	return sb.String()
+}
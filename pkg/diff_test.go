@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// seqLines return lines 1 to n, the lines in replaces are replaced
+func seqLines(n int, replaces map[int]string) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		line := fmt.Sprintf("%d", i)
+		if s, ok := replaces[i]; ok {
+			line = s
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	// the expected hunks are the same as GNU diff -U
+	tests := []struct {
+		name         string
+		a            string
+		b            string
+		contextLines int
+		want         string
+	}{
+		{name: "same", a: "a\nb\n", b: "a\nb\n", contextLines: 3, want: ""},
+		{name: "both empty", a: "", b: "", contextLines: 3, want: ""},
+		{name: "empty from", a: "", b: "a\nb\n", contextLines: 3, want: "--- from\n+++ to\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{name: "empty to", a: "a\nb\n", b: "", contextLines: 3, want: "--- from\n+++ to\n@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{name: "all lines changed", a: "a\nb\n", b: "c\nd\n", contextLines: 3, want: "--- from\n+++ to\n@@ -1,2 +1,2 @@\n-a\n-b\n+c\n+d\n"},
+		{name: "all lines changed without context", a: "a\nb\n", b: "c\nd\n", contextLines: 0, want: "--- from\n+++ to\n@@ -1,2 +1,2 @@\n-a\n-b\n+c\n+d\n"},
+		{name: "only trailing newline changed", a: "a\nb", b: "a\nb\n", contextLines: 3, want: ""},
+		{
+			name:         "one change with context",
+			a:            seqLines(10, nil),
+			b:            seqLines(10, map[int]string{5: "X"}),
+			contextLines: 3,
+			want:         "--- from\n+++ to\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+X\n 6\n 7\n 8\n",
+		},
+		{
+			name:         "hunks merged with -U 3",
+			a:            seqLines(20, nil),
+			b:            seqLines(20, map[int]string{3: "X", 10: "Y"}),
+			contextLines: 3,
+			want:         "--- from\n+++ to\n@@ -1,13 +1,13 @@\n 1\n 2\n-3\n+X\n 4\n 5\n 6\n 7\n 8\n 9\n-10\n+Y\n 11\n 12\n 13\n",
+		},
+		{
+			name:         "hunks not merged with -U 3",
+			a:            seqLines(20, nil),
+			b:            seqLines(20, map[int]string{3: "X", 11: "Y"}),
+			contextLines: 3,
+			want:         "--- from\n+++ to\n@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+X\n 4\n 5\n 6\n@@ -8,7 +8,7 @@\n 8\n 9\n 10\n-11\n+Y\n 12\n 13\n 14\n",
+		},
+		{
+			name:         "hunks not merged with -U 0",
+			a:            seqLines(10, nil),
+			b:            seqLines(10, map[int]string{3: "X", 5: "Y"}),
+			contextLines: 0,
+			want:         "--- from\n+++ to\n@@ -3 +3 @@\n-3\n+X\n@@ -5 +5 @@\n-5\n+Y\n",
+		},
+		{
+			name:         "adjacent changes merged with -U 0",
+			a:            seqLines(10, nil),
+			b:            seqLines(10, map[int]string{3: "X", 4: "Y"}),
+			contextLines: 0,
+			want:         "--- from\n+++ to\n@@ -3,2 +3,2 @@\n-3\n-4\n+X\n+Y\n",
+		},
+		{name: "insert with -U 0", a: "a\nb\n", b: "a\nX\nb\n", contextLines: 0, want: "--- from\n+++ to\n@@ -1,0 +2 @@\n+X\n"},
+		{name: "delete with -U 0", a: "a\nX\nb\n", b: "a\nb\n", contextLines: 0, want: "--- from\n+++ to\n@@ -2 +1,0 @@\n-X\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := UnifiedDiff("from", "to", tt.a, tt.b, tt.contextLines)
+			if got != tt.want {
+				t.Errorf("UnifiedDiff(%q, %q, %d) = %q, want %q", tt.a, tt.b, tt.contextLines, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package pkg
+
+import (
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LintIssue is a problem found by def lint, Path is the field path in the item, example: deployNodePorts[0].port,
+// Line and Column are 0 if the position is unknown
+type LintIssue struct {
+	FileName string `yaml:"fileName" json:"fileName" bson:"fileName" validate:""`
+	Line     int    `yaml:"line" json:"line" bson:"line" validate:""`
+	Column   int    `yaml:"column" json:"column" bson:"column" validate:""`
+	Rule     string `yaml:"rule" json:"rule" bson:"rule" validate:""`
+	Path     string `yaml:"path" json:"path" bson:"path" validate:""`
+	Message  string `yaml:"message" json:"message" bson:"message" validate:""`
+}
+
+// Position return the file position of the issue, format: fileName:line:column
+func (li LintIssue) Position() string {
+	switch {
+	case li.Line > 0 && li.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", li.FileName, li.Line, li.Column)
+	case li.Line > 0:
+		return fmt.Sprintf("%s:%d", li.FileName, li.Line)
+	}
+	return li.FileName
+}
+
+var lintValidate = newLintValidate()
+
+func newLintValidate() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("yaml"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return validate
+}
+
+// ValidateStruct check the validate tags of the struct, return all the violations, Path is the yaml field path
+func ValidateStruct(obj interface{}) []LintIssue {
+	issues := []LintIssue{}
+	err := lintValidate.Struct(obj)
+	if err == nil {
+		return issues
+	}
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		issues = append(issues, LintIssue{Rule: LintRuleValidate, Message: err.Error()})
+		return issues
+	}
+	for _, fe := range fieldErrors {
+		path := fe.Namespace()
+		arr := strings.SplitN(path, ".", 2)
+		if len(arr) == 2 {
+			path = arr[1]
+		}
+		var msg string
+		switch fe.Tag() {
+		case "required":
+			msg = fmt.Sprintf("%s is required", path)
+		case "gt", "gte", "lt", "lte", "min", "max":
+			msg = fmt.Sprintf("%s must be %s %s", path, fe.Tag(), fe.Param())
+		case "oneof":
+			msg = fmt.Sprintf("%s must be one of %s", path, strings.Join(strings.Fields(fe.Param()), " / "))
+		case "ip":
+			msg = fmt.Sprintf("%s must be an IP address", path)
+		default:
+			msg = fmt.Sprintf("%s failed on %s validation", path, fe.Tag())
+		}
+		issues = append(issues, LintIssue{
+			Rule:    LintRuleValidate,
+			Path:    path,
+			Message: msg,
+		})
+	}
+	return issues
+}
+
+var fieldPathRegexp = regexp.MustCompile(`\[(\d+)\]`)
+
+// ParseFieldPath split the field path to keys and sequence indexes, example: deployNodePorts[0].port => deployNodePorts, 0, port
+func ParseFieldPath(path string) []string {
+	tokens := []string{}
+	for _, s := range strings.Split(fieldPathRegexp.ReplaceAllString(path, ".$1"), ".") {
+		if s != "" {
+			tokens = append(tokens, s)
+		}
+	}
+	return tokens
+}
+
+// YamlNodePosition return the line and column of the field path in the yaml node, the key position is returned for mapping fields,
+// if the field not exists, the position of the nearest parent is returned
+func YamlNodePosition(node *yaml.Node, path string) (int, int) {
+	if node == nil {
+		return 0, 0
+	}
+	line, column := node.Line, node.Column
+	current := node
+	for _, token := range ParseFieldPath(path) {
+		if current.Kind == yaml.DocumentNode && len(current.Content) > 0 {
+			current = current.Content[0]
+		}
+		var next *yaml.Node
+		switch current.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(current.Content); i += 2 {
+				if current.Content[i].Value == token {
+					line, column = current.Content[i].Line, current.Content[i].Column
+					next = current.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			index, err := strconv.Atoi(token)
+			if err == nil && index >= 0 && index < len(current.Content) {
+				next = current.Content[index]
+				line, column = next.Line, next.Column
+			}
+		}
+		if next == nil {
+			break
+		}
+		current = next
+	}
+	return line, column
+}
+
+// YamlMappingValue return the value node of the key in the mapping node, nil if not found
+func YamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+var quantityRegexp = regexp.MustCompile(`^\+?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+|Ki|Mi|Gi|Ti|Pi|Ei|n|u|m|k|M|G|T|P|E)?$`)
+
+// ValidateQuantity check the kubernetes resource quantity format, example: 100m, 0.5, 128Mi, 1Gi
+func ValidateQuantity(s string) error {
+	var err error
+	if !quantityRegexp.MatchString(s) {
+		err = fmt.Errorf(`quantity %s format error, format should like "100m", "0.5", "128Mi" or "1Gi"`, s)
+		return err
+	}
+	return err
+}
+
+// ValidatePort check the port number is between 1 and 65535
+func ValidatePort(port int) error {
+	var err error
+	if port < 1 || port > 65535 {
+		err = fmt.Errorf("port %d out of range 1-65535", port)
+		return err
+	}
+	return err
+}
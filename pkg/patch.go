@@ -0,0 +1,345 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchOperation is the operation of JSON Patch (RFC 6902)
+type JSONPatchOperation struct {
+	Op    string      `yaml:"op" json:"op" bson:"op" validate:"required"`
+	Path  string      `yaml:"path" json:"path" bson:"path" validate:""`
+	From  string      `yaml:"from,omitempty" json:"from,omitempty" bson:"from" validate:""`
+	Value interface{} `yaml:"value,omitempty" json:"value,omitempty" bson:"value" validate:""`
+}
+
+// Validate check the operation and the JSON pointers format
+func (jpo JSONPatchOperation) Validate() error {
+	var err error
+	switch jpo.Op {
+	case JSONPatchOpAdd, JSONPatchOpRemove, JSONPatchOpReplace, JSONPatchOpTest:
+	case JSONPatchOpMove, JSONPatchOpCopy:
+		_, err = parseJSONPointer(jpo.From)
+		if err != nil {
+			err = fmt.Errorf("from %s", err.Error())
+			return err
+		}
+	default:
+		err = fmt.Errorf("op must be %s", strings.Join([]string{JSONPatchOpAdd, JSONPatchOpRemove, JSONPatchOpReplace, JSONPatchOpMove, JSONPatchOpCopy, JSONPatchOpTest}, " / "))
+		return err
+	}
+	_, err = parseJSONPointer(jpo.Path)
+	if err != nil {
+		err = fmt.Errorf("path %s", err.Error())
+		return err
+	}
+	return err
+}
+
+// ApplyJSONPatch apply the JSON Patch (RFC 6902) operations to the JSON document in order,
+// the patch is aborted if any operation failed, including the test operation
+func ApplyJSONPatch(doc []byte, operations []JSONPatchOperation) ([]byte, error) {
+	var err error
+	data, err := decodeJSON(doc)
+	if err != nil {
+		return doc, err
+	}
+	for _, jpo := range operations {
+		data, err = applyJSONPatchOperation(data, jpo)
+		if err != nil {
+			err = fmt.Errorf("op=%s path=%s error: %s", jpo.Op, jpo.Path, err.Error())
+			return doc, err
+		}
+	}
+	bs, err := json.Marshal(data)
+	if err != nil {
+		return doc, err
+	}
+	return bs, err
+}
+
+// ApplyMergePatch apply the JSON Merge Patch (RFC 7386) to the JSON document, null values in the patch remove the fields
+func ApplyMergePatch(doc []byte, patch interface{}) ([]byte, error) {
+	var err error
+	data, err := decodeJSON(doc)
+	if err != nil {
+		return doc, err
+	}
+	p, err := normalizeJSON(patch)
+	if err != nil {
+		return doc, err
+	}
+	bs, err := json.Marshal(mergePatch(data, p))
+	if err != nil {
+		return doc, err
+	}
+	return bs, err
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+func applyJSONPatchOperation(data interface{}, jpo JSONPatchOperation) (interface{}, error) {
+	var err error
+	var value interface{}
+	tokens, err := parseJSONPointer(jpo.Path)
+	if err != nil {
+		return data, err
+	}
+	switch jpo.Op {
+	case JSONPatchOpAdd, JSONPatchOpReplace:
+		value, err = normalizeJSON(jpo.Value)
+		if err != nil {
+			return data, err
+		}
+		data, err = patchJSONPointer(data, tokens, jpo.Op, value)
+	case JSONPatchOpRemove:
+		if len(tokens) == 0 {
+			err = fmt.Errorf("can not remove the whole document")
+			return data, err
+		}
+		data, err = patchJSONPointer(data, tokens, jpo.Op, nil)
+	case JSONPatchOpMove, JSONPatchOpCopy:
+		var fromTokens []string
+		fromTokens, err = parseJSONPointer(jpo.From)
+		if err != nil {
+			return data, err
+		}
+		value, err = getJSONPointer(data, fromTokens)
+		if err != nil {
+			err = fmt.Errorf("from %s", err.Error())
+			return data, err
+		}
+		if jpo.Op == JSONPatchOpMove {
+			if jpo.Path == jpo.From {
+				return data, err
+			}
+			if strings.HasPrefix(jpo.Path, fmt.Sprintf("%s/", jpo.From)) || len(fromTokens) == 0 {
+				err = fmt.Errorf("can not move %s to its child", jpo.From)
+				return data, err
+			}
+			data, err = patchJSONPointer(data, fromTokens, JSONPatchOpRemove, nil)
+			if err != nil {
+				return data, err
+			}
+		} else {
+			value, err = normalizeJSON(value)
+			if err != nil {
+				return data, err
+			}
+		}
+		data, err = patchJSONPointer(data, tokens, JSONPatchOpAdd, value)
+	case JSONPatchOpTest:
+		var current interface{}
+		value, err = normalizeJSON(jpo.Value)
+		if err != nil {
+			return data, err
+		}
+		current, err = getJSONPointer(data, tokens)
+		if err != nil {
+			err = fmt.Errorf("test failed: %s", err.Error())
+			return data, err
+		}
+		if !equalJSON(current, value) {
+			bsCurrent, _ := json.Marshal(current)
+			bsValue, _ := json.Marshal(value)
+			err = fmt.Errorf("test failed: value is %s, not %s", string(bsCurrent), string(bsValue))
+			return data, err
+		}
+	}
+	return data, err
+}
+
+// parseJSONPointer parse the JSON pointer (RFC 6901) to reference tokens, empty pointer is the whole document
+func parseJSONPointer(pointer string) ([]string, error) {
+	tokens := []string{}
+	if pointer == "" {
+		return tokens, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return tokens, fmt.Errorf("%s must start with /", pointer)
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(token, "~1", "/")
+		token = strings.ReplaceAll(token, "~0", "~")
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+func jsonArrayIndex(token string, length int, isAdd bool) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && strings.HasPrefix(token, "0")) {
+		return index, fmt.Errorf("array index %s format error", token)
+	}
+	if index > length || (!isAdd && index == length) {
+		return index, fmt.Errorf("array index %s out of range", token)
+	}
+	return index, nil
+}
+
+// getJSONPointer return the value referenced by the tokens
+func getJSONPointer(data interface{}, tokens []string) (interface{}, error) {
+	var err error
+	node := data
+	for i, token := range tokens {
+		switch v := node.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if !ok {
+				err = fmt.Errorf("/%s not exists", strings.Join(tokens[:i+1], "/"))
+				return nil, err
+			}
+			node = child
+		case []interface{}:
+			index, err := jsonArrayIndex(token, len(v), false)
+			if err != nil {
+				return nil, err
+			}
+			node = v[index]
+		default:
+			err = fmt.Errorf("/%s not exists", strings.Join(tokens[:i+1], "/"))
+			return nil, err
+		}
+	}
+	return node, err
+}
+
+// patchJSONPointer add, replace or remove the value referenced by the tokens, return the patched node
+func patchJSONPointer(node interface{}, tokens []string, op string, value interface{}) (interface{}, error) {
+	var err error
+	if len(tokens) == 0 {
+		return value, err
+	}
+	token := tokens[0]
+	last := len(tokens) == 1
+	switch v := node.(type) {
+	case map[string]interface{}:
+		child, ok := v[token]
+		if !ok && (!last || op != JSONPatchOpAdd) {
+			err = fmt.Errorf("%s not exists", token)
+			return node, err
+		}
+		if last {
+			if op == JSONPatchOpRemove {
+				delete(v, token)
+			} else {
+				v[token] = value
+			}
+			return v, err
+		}
+		child, err = patchJSONPointer(child, tokens[1:], op, value)
+		if err != nil {
+			return node, err
+		}
+		v[token] = child
+		return v, err
+	case []interface{}:
+		if last && op == JSONPatchOpAdd && token == "-" {
+			return append(v, value), err
+		}
+		index, err := jsonArrayIndex(token, len(v), last && op == JSONPatchOpAdd)
+		if err != nil {
+			return node, err
+		}
+		if last {
+			switch op {
+			case JSONPatchOpAdd:
+				v = append(v, nil)
+				copy(v[index+1:], v[index:])
+				v[index] = value
+			case JSONPatchOpReplace:
+				v[index] = value
+			case JSONPatchOpRemove:
+				v = append(v[:index], v[index+1:]...)
+			}
+			return v, err
+		}
+		child, err := patchJSONPointer(v[index], tokens[1:], op, value)
+		if err != nil {
+			return node, err
+		}
+		v[index] = child
+		return v, err
+	default:
+		err = fmt.Errorf("parent of %s is not object or array", token)
+		return node, err
+	}
+}
+
+func decodeJSON(bs []byte) (interface{}, error) {
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(bs))
+	decoder.UseNumber()
+	err := decoder.Decode(&data)
+	return data, err
+}
+
+// normalizeJSON convert the value decoded from JSON or YAML to the generic JSON value, numbers are json.Number
+func normalizeJSON(value interface{}) (interface{}, error) {
+	bs, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSON(bs)
+}
+
+// equalJSON compare the generic JSON values, numbers are compared by value
+func equalJSON(a, b interface{}) bool {
+	na, okA := a.(json.Number)
+	nb, okB := b.(json.Number)
+	if okA && okB {
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		if errA == nil && errB == nil {
+			return fa == fb
+		}
+		return na == nb
+	}
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for k, v := range va {
+			w, ok := vb[k]
+			if !ok || !equalJSON(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !equalJSON(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
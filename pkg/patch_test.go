@@ -0,0 +1,175 @@
+package pkg
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		// RFC 6902 Appendix A
+		{name: "A.1 add an object member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, want: `{"baz":"qux","foo":"bar"}`},
+		{name: "A.2 add an array element", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, want: `{"foo":["bar","qux","baz"]}`},
+		{name: "A.3 remove an object member", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, want: `{"foo":"bar"}`},
+		{name: "A.4 remove an array element", doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, want: `{"foo":["bar","baz"]}`},
+		{name: "A.5 replace a value", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, want: `{"baz":"boo","foo":"bar"}`},
+		{name: "A.6 move a value", doc: `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, want: `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{name: "A.7 move an array element", doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, want: `{"foo":["all","cows","eat","grass"]}`},
+		{name: "A.8 test a value success", doc: `{"baz":"qux","foo":["a",2,"c"]}`, patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, want: `{"baz":"qux","foo":["a",2,"c"]}`},
+		{name: "A.9 test a value error", doc: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, wantErr: true},
+		{name: "A.10 add a nested member object", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, want: `{"foo":"bar","child":{"grandchild":{}}}`},
+		{name: "A.11 ignore unrecognized elements", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux","xyz":123}]`, want: `{"foo":"bar","baz":"qux"}`},
+		{name: "A.12 add to a nonexistent target", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, wantErr: true},
+		{name: "A.14 escape ordering", doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10}]`, want: `{"/":9,"~1":10}`},
+		{name: "A.15 comparing strings and numbers", doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":"10"}]`, wantErr: true},
+		{name: "A.16 add an array value", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, want: `{"foo":["bar",["abc","def"]]}`},
+
+		{name: "add to array end", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":"baz"}]`, want: `{"foo":["bar","baz"]}`},
+		{name: "add to array at length", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/1","value":"baz"}]`, want: `{"foo":["bar","baz"]}`},
+		{name: "add to array out of range", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/2","value":"baz"}]`, wantErr: true},
+		{name: "add to array at first", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/0","value":"baz"}]`, want: `{"foo":["baz","bar"]}`},
+		{name: "add replace existing member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/foo","value":"baz"}]`, want: `{"foo":"baz"}`},
+		{name: "add replace the whole document", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"","value":{"baz":1}}]`, want: `{"baz":1}`},
+		{name: "replace not exists", doc: `{"foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"qux"}]`, wantErr: true},
+		{name: "replace array end not allowed", doc: `{"foo":["bar"]}`, patch: `[{"op":"replace","path":"/foo/-","value":"baz"}]`, wantErr: true},
+		{name: "remove not exists", doc: `{"foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, wantErr: true},
+		{name: "remove array index at length", doc: `{"foo":["bar"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, wantErr: true},
+		{name: "remove the whole document", doc: `{"foo":"bar"}`, patch: `[{"op":"remove","path":""}]`, wantErr: true},
+		{name: "array index leading zero", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"replace","path":"/foo/01","value":"qux"}]`, wantErr: true},
+		{name: "array index negative", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"remove","path":"/foo/-1"}]`, wantErr: true},
+		{name: "array index not number", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"remove","path":"/foo/a"}]`, wantErr: true},
+		{name: "array index zero", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"remove","path":"/foo/0"}]`, want: `{"foo":["baz"]}`},
+		{name: "escape tilde", doc: `{"a~b":1}`, patch: `[{"op":"replace","path":"/a~0b","value":2}]`, want: `{"a~b":2}`},
+		{name: "escape slash", doc: `{"a/b":1}`, patch: `[{"op":"replace","path":"/a~1b","value":2}]`, want: `{"a/b":2}`},
+		{name: "empty key", doc: `{"":1}`, patch: `[{"op":"replace","path":"/","value":2}]`, want: `{"":2}`},
+		{name: "path without leading slash", doc: `{"foo":"bar"}`, patch: `[{"op":"replace","path":"foo","value":"baz"}]`, wantErr: true},
+		{name: "parent is not object or array", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/foo/bar","value":1}]`, wantErr: true},
+		{name: "move into a child", doc: `{"foo":{"bar":{"baz":1}}}`, patch: `[{"op":"move","from":"/foo","path":"/foo/bar/qux"}]`, wantErr: true},
+		{name: "move to the same path", doc: `{"foo":1}`, patch: `[{"op":"move","from":"/foo","path":"/foo"}]`, want: `{"foo":1}`},
+		{name: "move to a sibling with the same prefix", doc: `{"foo":1}`, patch: `[{"op":"move","from":"/foo","path":"/foobar"}]`, want: `{"foobar":1}`},
+		{name: "move from not exists", doc: `{"foo":1}`, patch: `[{"op":"move","from":"/bar","path":"/baz"}]`, wantErr: true},
+		{name: "copy a value", doc: `{"foo":{"bar":[1,2]}}`, patch: `[{"op":"copy","from":"/foo/bar","path":"/baz"},{"op":"add","path":"/baz/-","value":3}]`, want: `{"foo":{"bar":[1,2]},"baz":[1,2,3]}`},
+		{name: "test object equal in any key order", doc: `{"foo":{"a":1,"b":[1,{"c":null}]}}`, patch: `[{"op":"test","path":"/foo","value":{"b":[1,{"c":null}],"a":1.0}}]`, want: `{"foo":{"a":1,"b":[1,{"c":null}]}}`},
+		{name: "test not exists", doc: `{"foo":1}`, patch: `[{"op":"test","path":"/bar","value":1}]`, wantErr: true},
+		{name: "failing test aborts the whole patch", doc: `{"foo":1}`, patch: `[{"op":"add","path":"/bar","value":2},{"op":"test","path":"/foo","value":2},{"op":"add","path":"/baz","value":3}]`, wantErr: true},
+		{name: "failing operation after changes", doc: `{"foo":[1]}`, patch: `[{"op":"remove","path":"/foo/0"},{"op":"remove","path":"/foo/0"}]`, wantErr: true},
+		{name: "operations applied in order", doc: `{"foo":[]}`, patch: `[{"op":"add","path":"/foo/-","value":1},{"op":"add","path":"/foo/0","value":0},{"op":"replace","path":"/foo/1","value":2}]`, want: `{"foo":[0,2]}`},
+		{name: "invalid document", doc: `{"foo":`, patch: `[]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []JSONPatchOperation
+			err := json.Unmarshal([]byte(tt.patch), &operations)
+			if err != nil {
+				t.Fatalf("parse patch error: %s", err.Error())
+			}
+			bs, err := ApplyJSONPatch([]byte(tt.doc), operations)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ApplyJSONPatch = %s, want error", string(bs))
+				}
+				if string(bs) != tt.doc {
+					t.Errorf("ApplyJSONPatch error returns %s, want the original document %s", string(bs), tt.doc)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyJSONPatch error: %s", err.Error())
+			}
+			assertJSONEqual(t, bs, tt.want)
+		})
+	}
+}
+
+func TestJSONPatchOperationValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		jpo     JSONPatchOperation
+		wantErr bool
+	}{
+		{name: "add", jpo: JSONPatchOperation{Op: JSONPatchOpAdd, Path: "/foo", Value: 1}},
+		{name: "remove", jpo: JSONPatchOperation{Op: JSONPatchOpRemove, Path: "/foo"}},
+		{name: "test the whole document", jpo: JSONPatchOperation{Op: JSONPatchOpTest, Path: ""}},
+		{name: "move", jpo: JSONPatchOperation{Op: JSONPatchOpMove, From: "/foo", Path: "/bar"}},
+		{name: "copy from the whole document", jpo: JSONPatchOperation{Op: JSONPatchOpCopy, From: "", Path: "/bar"}},
+		{name: "unknown op", jpo: JSONPatchOperation{Op: "merge", Path: "/foo"}, wantErr: true},
+		{name: "empty op", jpo: JSONPatchOperation{Path: "/foo"}, wantErr: true},
+		{name: "path without leading slash", jpo: JSONPatchOperation{Op: JSONPatchOpAdd, Path: "foo"}, wantErr: true},
+		{name: "from without leading slash", jpo: JSONPatchOperation{Op: JSONPatchOpMove, From: "foo", Path: "/bar"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.jpo.Validate()
+			if tt.wantErr && err == nil {
+				t.Errorf("Validate want error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate error: %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		// RFC 7386 Appendix A
+		{name: "replace member", doc: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "add member", doc: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{name: "null removes member", doc: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{name: "null removes member keep others", doc: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{name: "replace array with string", doc: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "replace string with array", doc: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{name: "nested merge and remove", doc: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{name: "arrays are replaced", doc: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{name: "replace array document", doc: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{name: "replace object with array", doc: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{name: "null patch", doc: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{name: "string patch", doc: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{name: "null in target kept", doc: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{name: "array target replaced by object", doc: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{name: "nested null in new member", doc: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+
+		{name: "remove not exists member", doc: `{"a":1}`, patch: `{"b":null}`, want: `{"a":1}`},
+		{name: "empty patch", doc: `{"a":1}`, patch: `{}`, want: `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch interface{}
+			err := json.Unmarshal([]byte(tt.patch), &patch)
+			if err != nil {
+				t.Fatalf("parse patch error: %s", err.Error())
+			}
+			bs, err := ApplyMergePatch([]byte(tt.doc), patch)
+			if err != nil {
+				t.Fatalf("ApplyMergePatch error: %s", err.Error())
+			}
+			assertJSONEqual(t, bs, tt.want)
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	a, err := decodeJSON(got)
+	if err != nil {
+		t.Fatalf("decode result %s error: %s", string(got), err.Error())
+	}
+	b, err := decodeJSON([]byte(want))
+	if err != nil {
+		t.Fatalf("decode want %s error: %s", want, err.Error())
+	}
+	if !equalJSON(a, b) {
+		t.Errorf("result is %s, want %s", string(got), want)
+	}
+}
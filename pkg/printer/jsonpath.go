@@ -0,0 +1,346 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	nodeText = iota
+	nodePath
+	nodeRange
+)
+
+// JSONPath is a parsed template of the JSONPath subset supported by doryctl, the syntax is the same as kubectl,
+// supports {.field.sub}, {['field']}, {.list[0]}, {.list[-1]}, {.list[*]}, {.map.*}, {$} the root object, {@} the current object,
+// {range .list[*]}...{end}, {"literal"} and plain text outside the braces, filters, slices and recursive descent are not supported
+type JSONPath struct {
+	nodes []jsonPathNode
+}
+
+type jsonPathNode struct {
+	kind  int
+	text  string
+	path  jsonPathExpr
+	nodes []jsonPathNode
+}
+
+type jsonPathExpr struct {
+	root  bool
+	steps []jsonPathStep
+}
+
+type jsonPathStep struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// ParseJSONPath parse the JSONPath template, text outside the braces is printed as it is
+func ParseJSONPath(tpl string) (*JSONPath, error) {
+	var err error
+	nodes := []jsonPathNode{}
+	stack := [][]jsonPathNode{}
+	ranges := []jsonPathNode{}
+	s := tpl
+	for s != "" {
+		start := strings.Index(s, "{")
+		if start < 0 {
+			nodes = append(nodes, jsonPathNode{kind: nodeText, text: s})
+			break
+		}
+		if start > 0 {
+			nodes = append(nodes, jsonPathNode{kind: nodeText, text: s[:start]})
+		}
+		end := findActionEnd(s, start+1)
+		if end < 0 {
+			err = fmt.Errorf("jsonpath %s error: unclosed action", tpl)
+			return nil, err
+		}
+		action := strings.TrimSpace(s[start+1 : end])
+		s = s[end+1:]
+
+		switch {
+		case action == "end":
+			if len(stack) == 0 {
+				err = fmt.Errorf("jsonpath %s error: {end} without {range}", tpl)
+				return nil, err
+			}
+			node := ranges[len(ranges)-1]
+			node.nodes = nodes
+			ranges = ranges[:len(ranges)-1]
+			nodes = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			nodes = append(nodes, node)
+		case strings.HasPrefix(action, "range ") || strings.HasPrefix(action, "range\t"):
+			path, err := parseJSONPathExpr(strings.TrimSpace(action[len("range"):]))
+			if err != nil {
+				err = fmt.Errorf("jsonpath %s error: %s", tpl, err.Error())
+				return nil, err
+			}
+			ranges = append(ranges, jsonPathNode{kind: nodeRange, path: path})
+			stack = append(stack, nodes)
+			nodes = []jsonPathNode{}
+		case strings.HasPrefix(action, `"`) || strings.HasPrefix(action, `'`):
+			text, err := unquote(action)
+			if err != nil {
+				err = fmt.Errorf("jsonpath %s error: %s", tpl, err.Error())
+				return nil, err
+			}
+			nodes = append(nodes, jsonPathNode{kind: nodeText, text: text})
+		default:
+			path, err := parseJSONPathExpr(action)
+			if err != nil {
+				err = fmt.Errorf("jsonpath %s error: %s", tpl, err.Error())
+				return nil, err
+			}
+			nodes = append(nodes, jsonPathNode{kind: nodePath, path: path})
+		}
+	}
+	if len(stack) > 0 {
+		err = fmt.Errorf("jsonpath %s error: {range} without {end}", tpl)
+		return nil, err
+	}
+	return &JSONPath{nodes: nodes}, err
+}
+
+// ParseRelaxedJSONPath parse the JSONPath expression used by --sort-by and custom-columns,
+// the braces and the leading dot are optional, example: .metadata.name, {.metadata.name}, metadata.name
+func ParseRelaxedJSONPath(expr string) (*JSONPath, error) {
+	s := strings.TrimSpace(expr)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	if s == "" {
+		return nil, fmt.Errorf("jsonpath %s error: empty expression", expr)
+	}
+	if !strings.HasPrefix(s, ".") && !strings.HasPrefix(s, "[") && !strings.HasPrefix(s, "$") && !strings.HasPrefix(s, "@") {
+		s = fmt.Sprintf(".%s", s)
+	}
+	return ParseJSONPath(fmt.Sprintf("{%s}", s))
+}
+
+// findActionEnd return the index of the brace closing the action begin at start, braces in quoted strings are ignored
+func findActionEnd(s string, start int) int {
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '}':
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, "'") {
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid quoted string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
+func parseJSONPathExpr(s string) (jsonPathExpr, error) {
+	var err error
+	var expr jsonPathExpr
+	if strings.HasPrefix(s, "$") {
+		expr.root = true
+		s = s[1:]
+	} else if strings.HasPrefix(s, "@") {
+		s = s[1:]
+	}
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			err = fmt.Errorf("recursive descent %s not supported", s)
+			return expr, err
+		case strings.HasPrefix(s, "."):
+			s = s[1:]
+			if strings.HasPrefix(s, "*") {
+				expr.steps = append(expr.steps, jsonPathStep{wildcard: true})
+				s = s[1:]
+				continue
+			}
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			name := s[:end]
+			s = s[end:]
+			if name == "" {
+				if s == "" {
+					continue
+				}
+				err = fmt.Errorf("empty field name before %s", s)
+				return expr, err
+			}
+			expr.steps = append(expr.steps, jsonPathStep{field: name})
+		case strings.HasPrefix(s, "["):
+			end := strings.Index(s, "]")
+			if end < 0 {
+				err = fmt.Errorf("unclosed bracket %s", s)
+				return expr, err
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			switch {
+			case inner == "*":
+				expr.steps = append(expr.steps, jsonPathStep{wildcard: true})
+			case strings.HasPrefix(inner, "'") || strings.HasPrefix(inner, `"`):
+				name, err := unquote(inner)
+				if err != nil {
+					return expr, err
+				}
+				expr.steps = append(expr.steps, jsonPathStep{field: name})
+			case strings.HasPrefix(inner, "?") || strings.Contains(inner, ":") || strings.Contains(inner, ","):
+				err = fmt.Errorf("filter or slice [%s] not supported", inner)
+				return expr, err
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					err = fmt.Errorf("invalid index [%s]", inner)
+					return expr, err
+				}
+				expr.steps = append(expr.steps, jsonPathStep{index: index, isIndex: true})
+			}
+		default:
+			err = fmt.Errorf("invalid expression %s, field must start with .", s)
+			return expr, err
+		}
+	}
+	return expr, err
+}
+
+// evaluate the expression on the current object, return all the matched values
+func (expr jsonPathExpr) evaluate(root, current interface{}) []interface{} {
+	values := []interface{}{current}
+	if expr.root {
+		values = []interface{}{root}
+	}
+	for _, step := range expr.steps {
+		results := []interface{}{}
+		for _, value := range values {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				if step.wildcard {
+					keys := []string{}
+					for k := range v {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					for _, k := range keys {
+						results = append(results, v[k])
+					}
+				} else if !step.isIndex {
+					if item, ok := v[step.field]; ok {
+						results = append(results, item)
+					}
+				}
+			case []interface{}:
+				if step.wildcard {
+					results = append(results, v...)
+				} else if step.isIndex {
+					index := step.index
+					if index < 0 {
+						index = len(v) + index
+					}
+					if index >= 0 && index < len(v) {
+						results = append(results, v[index])
+					}
+				}
+			}
+		}
+		values = results
+	}
+	return values
+}
+
+// Execute write the template result of the object to buffer
+func (j *JSONPath) Execute(b *bytes.Buffer, obj interface{}) error {
+	data, err := ToGeneric(obj)
+	if err != nil {
+		return err
+	}
+	executeNodes(b, j.nodes, data, data)
+	return err
+}
+
+// Values return the values matched by the first expression of the template
+func (j *JSONPath) Values(obj interface{}) ([]interface{}, error) {
+	values := []interface{}{}
+	data, err := ToGeneric(obj)
+	if err != nil {
+		return values, err
+	}
+	for _, node := range j.nodes {
+		if node.kind == nodePath {
+			values = node.path.evaluate(data, data)
+			break
+		}
+	}
+	return values, err
+}
+
+func executeNodes(b *bytes.Buffer, nodes []jsonPathNode, root, current interface{}) {
+	for _, node := range nodes {
+		switch node.kind {
+		case nodeText:
+			b.WriteString(node.text)
+		case nodePath:
+			values := []string{}
+			for _, value := range node.path.evaluate(root, current) {
+				values = append(values, FormatValue(value))
+			}
+			b.WriteString(strings.Join(values, " "))
+		case nodeRange:
+			for _, value := range node.path.evaluate(root, current) {
+				executeNodes(b, node.nodes, root, value)
+			}
+		}
+	}
+}
+
+// ToGeneric convert the object to the generic JSON object with json field names, numbers are json.Number
+func ToGeneric(obj interface{}) (interface{}, error) {
+	var data interface{}
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return data, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(bs))
+	decoder.UseNumber()
+	err = decoder.Decode(&data)
+	return data, err
+}
+
+// FormatValue format the generic JSON value, objects and arrays are printed in compact JSON format
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		bs, _ := json.Marshal(v)
+		return string(bs)
+	}
+}
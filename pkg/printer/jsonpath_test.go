@@ -0,0 +1,186 @@
+package printer
+
+import (
+	"bytes"
+	"testing"
+)
+
+var jsonPathTestData = map[string]interface{}{
+	"kind": "list",
+	"metadata": map[string]interface{}{
+		"name":   "test-project1",
+		"labels": map[string]interface{}{"envName": "test", "stepName": "scanCode"},
+	},
+	"items": []interface{}{
+		map[string]interface{}{"name": "tp1-go-demo", "port": 8000, "enabled": true},
+		map[string]interface{}{"name": "tp1-gin-demo", "port": 9000, "enabled": false},
+		map[string]interface{}{"name": "tp1-node-demo", "port": 3000, "tags": []interface{}{"a", "b"}},
+	},
+	"key.with.dots": "dots",
+	"empty":         nil,
+}
+
+func TestJSONPathExecute(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  string
+		want string
+	}{
+		{name: "plain text", tpl: "hello", want: "hello"},
+		{name: "field", tpl: "{.kind}", want: "list"},
+		{name: "nested field", tpl: "{.metadata.name}", want: "test-project1"},
+		{name: "root", tpl: "{$.metadata.name}", want: "test-project1"},
+		{name: "current", tpl: "{@.kind}", want: "list"},
+		{name: "bracket field", tpl: "{['key.with.dots']}", want: "dots"},
+		{name: "bracket field double quote", tpl: `{.metadata["name"]}`, want: "test-project1"},
+		{name: "index", tpl: "{.items[1].name}", want: "tp1-gin-demo"},
+		{name: "negative index", tpl: "{.items[-1].name}", want: "tp1-node-demo"},
+		{name: "index out of range", tpl: "{.items[5].name}", want: ""},
+		{name: "negative index out of range", tpl: "{.items[-5].name}", want: ""},
+		{name: "array wildcard", tpl: "{.items[*].name}", want: "tp1-go-demo tp1-gin-demo tp1-node-demo"},
+		{name: "map wildcard sorted by key", tpl: "{.metadata.labels.*}", want: "test scanCode"},
+		{name: "number", tpl: "{.items[0].port}", want: "8000"},
+		{name: "bool", tpl: "{.items[1].enabled}", want: "false"},
+		{name: "null", tpl: "{.empty}", want: ""},
+		{name: "missing field", tpl: "{.notExists.name}", want: ""},
+		{name: "index on map", tpl: "{.metadata[0]}", want: ""},
+		{name: "field on array", tpl: "{.items.name}", want: ""},
+		{name: "array printed as json", tpl: "{.items[2].tags}", want: `["a","b"]`},
+		{name: "text and fields", tpl: "kind={.kind}, name={.metadata.name}", want: "kind=list, name=test-project1"},
+		{name: "quoted literal", tpl: `{.kind}{"\t"}{.metadata.name}{'\n'}`, want: "list\ttest-project1\\n"},
+		{name: "braces in quoted literal", tpl: `{"{}"}`, want: "{}"},
+		{name: "range", tpl: `{range .items[*]}{.name}={.port}{"\n"}{end}`, want: "tp1-go-demo=8000\ntp1-gin-demo=9000\ntp1-node-demo=3000\n"},
+		{name: "range with root", tpl: `{range .items[*]}{$.kind}/{.name} {end}`, want: "list/tp1-go-demo list/tp1-gin-demo list/tp1-node-demo "},
+		{name: "nested range", tpl: `{range .items[*]}{range .tags[*]}{@}{end}{end}`, want: "ab"},
+		{name: "range over missing", tpl: `{range .notExists[*]}x{end}done`, want: "done"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := ParseJSONPath(tt.tpl)
+			if err != nil {
+				t.Fatalf("ParseJSONPath(%q) error: %s", tt.tpl, err.Error())
+			}
+			var b bytes.Buffer
+			err = j.Execute(&b, jsonPathTestData)
+			if err != nil {
+				t.Fatalf("Execute(%q) error: %s", tt.tpl, err.Error())
+			}
+			if b.String() != tt.want {
+				t.Errorf("Execute(%q) = %q, want %q", tt.tpl, b.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  string
+	}{
+		{name: "unclosed action", tpl: "{.kind"},
+		{name: "unclosed action after text", tpl: "kind: {"},
+		{name: "unclosed quote", tpl: `{"abc}`},
+		{name: "end without range", tpl: "{.kind}{end}"},
+		{name: "range without end", tpl: "{range .items[*]}{.name}"},
+		{name: "nested range without end", tpl: "{range .items[*]}{range .tags[*]}{end}"},
+		{name: "invalid range expression", tpl: "{range items}{end}"},
+		{name: "recursive descent", tpl: "{..name}"},
+		{name: "recursive descent after field", tpl: "{.items..name}"},
+		{name: "filter", tpl: "{.items[?(@.port>8000)].name}"},
+		{name: "slice", tpl: "{.items[0:2].name}"},
+		{name: "slice with step", tpl: "{.items[::2]}"},
+		{name: "union", tpl: "{.items[0,1]}"},
+		{name: "unclosed bracket", tpl: "{.items[0}"},
+		{name: "invalid index", tpl: "{.items[x]}"},
+		{name: "empty index", tpl: "{.items[]}"},
+		{name: "unclosed quoted field", tpl: "{['name]}"},
+		{name: "empty field name", tpl: "{.items.[0]}"},
+		{name: "field without dot", tpl: "{kind}"},
+		{name: "quoted literal with invalid escape", tpl: `{"\q"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, err := ParseJSONPath(tt.tpl)
+			if err == nil {
+				t.Errorf("ParseJSONPath(%q) = %v, want error", tt.tpl, j)
+			}
+		})
+	}
+}
+
+func TestParseRelaxedJSONPath(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    []string
+		wantErr bool
+	}{
+		{expr: ".metadata.name", want: []string{"test-project1"}},
+		{expr: "{.metadata.name}", want: []string{"test-project1"}},
+		{expr: "metadata.name", want: []string{"test-project1"}},
+		{expr: "['kind']", want: []string{"list"}},
+		{expr: "$.kind", want: []string{"list"}},
+		{expr: "@.kind", want: []string{"list"}},
+		{expr: "items[*].port", want: []string{"8000", "9000", "3000"}},
+		{expr: "notExists", want: []string{}},
+		{expr: "", wantErr: true},
+		{expr: "{}", wantErr: true},
+		{expr: "  ", wantErr: true},
+		{expr: "items[?(@.port)]", wantErr: true},
+		{expr: "..name", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			j, err := ParseRelaxedJSONPath(tt.expr)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseRelaxedJSONPath(%q) want error", tt.expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRelaxedJSONPath(%q) error: %s", tt.expr, err.Error())
+			}
+			values, err := j.Values(jsonPathTestData)
+			if err != nil {
+				t.Fatalf("Values(%q) error: %s", tt.expr, err.Error())
+			}
+			got := []string{}
+			for _, value := range values {
+				got = append(got, FormatValue(value))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Values(%q) = %q, want %q", tt.expr, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Values(%q) = %q, want %q", tt.expr, got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestJSONPathExecuteStruct(t *testing.T) {
+	type item struct {
+		ItemName string `json:"itemName"`
+		Count    int    `json:"count"`
+	}
+	obj := struct {
+		Items []item `json:"items"`
+	}{Items: []item{{ItemName: "a", Count: 1}, {ItemName: "b", Count: 2}}}
+
+	j, err := ParseJSONPath(`{range .items[*]}{.itemName}:{.count} {end}`)
+	if err != nil {
+		t.Fatalf("ParseJSONPath error: %s", err.Error())
+	}
+	var b bytes.Buffer
+	err = j.Execute(&b, obj)
+	if err != nil {
+		t.Fatalf("Execute error: %s", err.Error())
+	}
+	want := "a:1 b:2 "
+	if b.String() != want {
+		t.Errorf("Execute = %q, want %q", b.String(), want)
+	}
+}
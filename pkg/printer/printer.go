@@ -0,0 +1,396 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/sprig"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"github.com/olekukonko/tablewriter"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	OutputTable         = ""
+	OutputJson          = "json"
+	OutputYaml          = "yaml"
+	OutputWide          = "wide"
+	OutputName          = "name"
+	OutputCsv           = "csv"
+	OutputJsonPath      = "jsonpath="
+	OutputGoTemplate    = "go-template="
+	OutputCustomColumns = "custom-columns="
+
+	// ValueNone is the custom-columns cell value when the expression matched nothing
+	ValueNone = "<none>"
+)
+
+// Outputs are the output formats supported by get commands
+var Outputs = []string{
+	OutputJson,
+	OutputYaml,
+	OutputWide,
+	OutputName,
+	OutputCsv,
+	OutputJsonPath,
+	OutputGoTemplate,
+	OutputCustomColumns,
+}
+
+// Options is the output options of get commands
+type Options struct {
+	Output    string `yaml:"output" json:"output" bson:"output" validate:""`
+	NoHeaders bool   `yaml:"noHeaders" json:"noHeaders" bson:"noHeaders" validate:""`
+	SortBy    string `yaml:"sortBy" json:"sortBy" bson:"sortBy" validate:""`
+}
+
+// Row is a table row of an item, Name is printed in name output, Cells are printed in table output,
+// WideCells are appended in wide output, Object is the item to evaluate custom-columns expressions
+type Row struct {
+	Name      string
+	Cells     []string
+	WideCells []string
+	Object    interface{}
+}
+
+// Table is the table of items, WideHeaders are appended in wide output
+type Table struct {
+	Headers     []string
+	WideHeaders []string
+	Rows        []Row
+}
+
+type column struct {
+	header string
+	path   *JSONPath
+}
+
+// Printer print the output object and tables in output format
+type Printer struct {
+	Options
+	jsonPath *JSONPath
+	template *template.Template
+	columns  []column
+	sortBy   *JSONPath
+}
+
+// NewPrinter validate the output options and parse the templates
+func NewPrinter(options Options) (*Printer, error) {
+	var err error
+	p := &Printer{Options: options}
+	output := options.Output
+	switch {
+	case output == OutputTable, output == OutputJson, output == OutputYaml, output == OutputWide, output == OutputName, output == OutputCsv:
+	case strings.HasPrefix(output, OutputJsonPath):
+		p.jsonPath, err = ParseJSONPath(strings.TrimPrefix(output, OutputJsonPath))
+		if err != nil {
+			err = fmt.Errorf("--output error: %s", err.Error())
+			return p, err
+		}
+	case strings.HasPrefix(output, OutputGoTemplate):
+		p.template, err = template.New("output").Funcs(sprig.TxtFuncMap()).Parse(strings.TrimPrefix(output, OutputGoTemplate))
+		if err != nil {
+			err = fmt.Errorf("--output go-template error: %s", err.Error())
+			return p, err
+		}
+	case strings.HasPrefix(output, OutputCustomColumns):
+		spec := strings.TrimPrefix(output, OutputCustomColumns)
+		for _, s := range strings.Split(spec, ",") {
+			arr := strings.SplitN(s, ":", 2)
+			if len(arr) != 2 || strings.TrimSpace(arr[0]) == "" {
+				err = fmt.Errorf("--output custom-columns error: %s format must be HEADER:EXPRESSION", s)
+				return p, err
+			}
+			path, err := ParseRelaxedJSONPath(arr[1])
+			if err != nil {
+				err = fmt.Errorf("--output custom-columns error: %s", err.Error())
+				return p, err
+			}
+			p.columns = append(p.columns, column{header: strings.TrimSpace(arr[0]), path: path})
+		}
+	default:
+		err = fmt.Errorf("--output must be %s", strings.Join(Outputs, " / "))
+		return p, err
+	}
+
+	if options.SortBy != "" {
+		p.sortBy, err = ParseRelaxedJSONPath(options.SortBy)
+		if err != nil {
+			err = fmt.Errorf("--sort-by error: %s", err.Error())
+			return p, err
+		}
+	}
+	return p, err
+}
+
+// IsTable check the output is table or wide, the tables are rendered by PrintTable
+func (p *Printer) IsTable() bool {
+	return p.Output == OutputTable || p.Output == OutputWide
+}
+
+// IsObject check the output is printed from the whole output object: json, yaml, jsonpath and go-template
+func (p *Printer) IsObject() bool {
+	return p.Output == OutputJson || p.Output == OutputYaml || p.jsonPath != nil || p.template != nil
+}
+
+// Sort sort the slice by --sort-by expression evaluated on the slice items, the parallel slices are sorted in the same order,
+// items not matched are sorted first, numbers are compared by value, nothing is changed if --sort-by is empty
+func (p *Printer) Sort(slice interface{}, parallels ...interface{}) error {
+	var err error
+	if p.sortBy == nil {
+		return err
+	}
+	v := reflect.ValueOf(slice)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	keys := []interface{}{}
+	for i := 0; i < v.Len(); i++ {
+		values, err := p.sortBy.Values(v.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		var key interface{}
+		if len(values) > 0 {
+			key = values[0]
+		}
+		keys = append(keys, key)
+	}
+	indexes := make([]int, len(keys))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return lessValue(keys[indexes[i]], keys[indexes[j]])
+	})
+	for _, s := range append([]interface{}{slice}, parallels...) {
+		sv := reflect.ValueOf(s)
+		if sv.Kind() == reflect.Ptr {
+			sv = sv.Elem()
+		}
+		if sv.Len() != len(indexes) {
+			err = fmt.Errorf("sort error: slices length mismatch")
+			return err
+		}
+		sorted := reflect.MakeSlice(sv.Type(), sv.Len(), sv.Len())
+		for i, index := range indexes {
+			sorted.Index(i).Set(sv.Index(index))
+		}
+		reflect.Copy(sv, sorted)
+	}
+	return err
+}
+
+func lessValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b != nil
+	}
+	na, okA := a.(json.Number)
+	nb, okB := b.(json.Number)
+	if okA && okB {
+		fa, errA := na.Float64()
+		fb, errB := nb.Float64()
+		if errA == nil && errB == nil {
+			return fa < fb
+		}
+	}
+	return FormatValue(a) < FormatValue(b)
+}
+
+// Print print the output object in json, yaml, jsonpath or go-template output, otherwise print the tables,
+// the tables must be sorted by the caller
+func (p *Printer) Print(w io.Writer, obj interface{}, tables []Table) error {
+	var err error
+	switch {
+	case p.IsObject():
+		err = p.PrintObject(w, obj)
+	case p.IsTable():
+		for _, table := range tables {
+			err = p.PrintTable(w, table, true)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		rows := []Row{}
+		for _, table := range tables {
+			rows = append(rows, table.Rows...)
+		}
+		if p.Output == OutputCsv {
+			for i, table := range tables {
+				if i > 0 {
+					_, err = io.WriteString(w, "\n")
+					if err != nil {
+						return err
+					}
+				}
+				err = p.PrintRows(w, table, true)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			err = p.PrintRows(w, Table{Rows: rows}, true)
+		}
+	}
+	return err
+}
+
+// PrintObject print the whole output object in json, yaml, jsonpath or go-template output
+func (p *Printer) PrintObject(w io.Writer, obj interface{}) error {
+	var err error
+	var s string
+	switch {
+	case p.Output == OutputJson:
+		bs, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		s = fmt.Sprintf("%s\n", string(bs))
+	case p.Output == OutputYaml:
+		bs, err := pkg.YamlIndent(obj)
+		if err != nil {
+			return err
+		}
+		s = fmt.Sprintf("%s\n", string(bs))
+	case p.jsonPath != nil:
+		var b bytes.Buffer
+		err = p.jsonPath.Execute(&b, obj)
+		if err != nil {
+			return err
+		}
+		s = b.String()
+	case p.template != nil:
+		data, err := ToGeneric(obj)
+		if err != nil {
+			return err
+		}
+		var b bytes.Buffer
+		err = p.template.Execute(&b, data)
+		if err != nil {
+			err = fmt.Errorf("--output go-template error: %s", err.Error())
+			return err
+		}
+		s = b.String()
+	default:
+		err = fmt.Errorf("output %s not support object", p.Output)
+		return err
+	}
+	_, err = io.WriteString(w, s)
+	return err
+}
+
+// PrintTable render the table in table or wide output, header is false when render the following pages of the same table,
+// rows shorter than the headers are padded with empty cells
+func (p *Printer) PrintTable(w io.Writer, table Table, header bool) error {
+	var err error
+	headers := table.Headers
+	if p.Output == OutputWide {
+		headers = append(append([]string{}, table.Headers...), table.WideHeaders...)
+	}
+	data := [][]string{}
+	for _, row := range table.Rows {
+		cells := append([]string{}, row.Cells...)
+		for len(cells) < len(table.Headers) {
+			cells = append(cells, "")
+		}
+		if p.Output == OutputWide {
+			cells = append(cells, row.WideCells...)
+		}
+		data = append(data, cells)
+	}
+	RenderTable(w, headers, data, header && !p.NoHeaders)
+	return err
+}
+
+// PrintRows print the table rows in name, csv or custom-columns output, header is false when print the following pages of the same table
+func (p *Printer) PrintRows(w io.Writer, table Table, header bool) error {
+	var err error
+	switch {
+	case p.Output == OutputName:
+		for _, row := range table.Rows {
+			if row.Name == "" {
+				continue
+			}
+			_, err = fmt.Fprintln(w, row.Name)
+			if err != nil {
+				return err
+			}
+		}
+	case p.Output == OutputCsv:
+		cw := csv.NewWriter(w)
+		if header && !p.NoHeaders {
+			err = cw.Write(append(append([]string{}, table.Headers...), table.WideHeaders...))
+			if err != nil {
+				return err
+			}
+		}
+		for _, row := range table.Rows {
+			cells := append([]string{}, row.Cells...)
+			for len(cells) < len(table.Headers) {
+				cells = append(cells, "")
+			}
+			err = cw.Write(append(cells, row.WideCells...))
+			if err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		err = cw.Error()
+	case len(p.columns) > 0:
+		headers := []string{}
+		for _, col := range p.columns {
+			headers = append(headers, col.header)
+		}
+		data := [][]string{}
+		for _, row := range table.Rows {
+			cells := []string{}
+			for _, col := range p.columns {
+				values, err := col.path.Values(row.Object)
+				if err != nil {
+					return err
+				}
+				strs := []string{}
+				for _, value := range values {
+					strs = append(strs, FormatValue(value))
+				}
+				cell := strings.Join(strs, ",")
+				if len(values) == 0 {
+					cell = ValueNone
+				}
+				cells = append(cells, cell)
+			}
+			data = append(data, cells)
+		}
+		RenderTable(w, headers, data, header && !p.NoHeaders)
+	default:
+		err = fmt.Errorf("output %s not support rows", p.Output)
+	}
+	return err
+}
+
+// RenderTable render the table in doryctl table style
+func RenderTable(w io.Writer, headers []string, data [][]string, header bool) {
+	table := tablewriter.NewWriter(w)
+	if header {
+		table.SetHeader(headers)
+	}
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+	table.AppendBulk(data)
+	table.Render()
+}
@@ -0,0 +1,155 @@
+package printer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/dory-engine/dory-ctl/pkg"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ListPrinter print list items page by page without buffering all items,
+// the output is the same as printing map[string]interface{}{Key: items} and the table at once,
+// jsonpath, go-template and --sort-by need all items, the items are buffered until Close
+type ListPrinter struct {
+	Printer *Printer
+	Key     string
+	Writer  io.Writer
+	count   int
+	items   []interface{}
+	table   Table
+}
+
+// NewListPrinter create the list printer, Key is the key of items in the output object
+func (p *Printer) NewListPrinter(w io.Writer, key string) *ListPrinter {
+	return &ListPrinter{Printer: p, Key: key, Writer: w}
+}
+
+// IsBuffered check the items are buffered until Close
+func (lp *ListPrinter) IsBuffered() bool {
+	return lp.Printer.jsonPath != nil || lp.Printer.template != nil || lp.Printer.sortBy != nil
+}
+
+// Print print the items of a page and the table rows of the items
+func (lp *ListPrinter) Print(items []interface{}, table Table) error {
+	var err error
+	if lp.IsBuffered() {
+		lp.items = append(lp.items, items...)
+		if len(table.Headers) >= len(lp.table.Headers) {
+			lp.table.Headers = table.Headers
+		}
+		lp.table.WideHeaders = table.WideHeaders
+		lp.table.Rows = append(lp.table.Rows, table.Rows...)
+		return err
+	}
+
+	switch lp.Printer.Output {
+	case OutputJson, OutputYaml:
+		for _, item := range items {
+			err = lp.printItem(item)
+			if err != nil {
+				return err
+			}
+			lp.count++
+		}
+		return err
+	case OutputTable, OutputWide:
+		if len(table.Rows) > 0 {
+			err = lp.Printer.PrintTable(lp.Writer, table, lp.count == 0)
+		}
+	default:
+		if len(table.Rows) > 0 {
+			err = lp.Printer.PrintRows(lp.Writer, table, lp.count == 0)
+		}
+	}
+	lp.count = lp.count + len(table.Rows)
+	return err
+}
+
+func (lp *ListPrinter) printItem(item interface{}) error {
+	var err error
+	var s string
+	switch lp.Printer.Output {
+	case OutputJson:
+		bs, err := json.MarshalIndent(item, "    ", "  ")
+		if err != nil {
+			return err
+		}
+		if lp.count == 0 {
+			s = fmt.Sprintf("{\n  %q: [\n    %s", lp.Key, string(bs))
+		} else {
+			s = fmt.Sprintf(",\n    %s", string(bs))
+		}
+	case OutputYaml:
+		bs, err := pkg.YamlIndent([]interface{}{item})
+		if err != nil {
+			return err
+		}
+		lines := strings.Split(strings.TrimSuffix(string(bs), "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = fmt.Sprintf("  %s", line)
+		}
+		s = fmt.Sprintf("%s\n", strings.Join(lines, "\n"))
+		if lp.count == 0 {
+			s = fmt.Sprintf("%s:\n%s", lp.Key, s)
+		}
+	}
+	_, err = io.WriteString(lp.Writer, s)
+	return err
+}
+
+// Close finish the output with the extra keys after the items, buffered items are sorted and printed,
+// nothing is printed if no items
+func (lp *ListPrinter) Close(extra map[string]interface{}) error {
+	var err error
+	if lp.IsBuffered() {
+		if len(lp.items) == 0 {
+			return err
+		}
+		err = lp.Printer.Sort(lp.items, lp.table.Rows)
+		if err != nil {
+			return err
+		}
+		obj := map[string]interface{}{}
+		for k, v := range extra {
+			obj[k] = v
+		}
+		obj[lp.Key] = lp.items
+		err = lp.Printer.Print(lp.Writer, obj, []Table{lp.table})
+		return err
+	}
+
+	if lp.count == 0 {
+		return err
+	}
+	var s string
+	switch lp.Printer.Output {
+	case OutputJson:
+		s = "\n  ]"
+		keys := []string{}
+		for k := range extra {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			bs, err := json.MarshalIndent(extra[k], "  ", "  ")
+			if err != nil {
+				return err
+			}
+			s = fmt.Sprintf("%s,\n  %q: %s", s, k, string(bs))
+		}
+		s = fmt.Sprintf("%s\n}\n", s)
+	case OutputYaml:
+		if len(extra) > 0 {
+			bs, err := pkg.YamlIndent(extra)
+			if err != nil {
+				return err
+			}
+			s = string(bs)
+		}
+		s = fmt.Sprintf("%s\n", s)
+	}
+	_, err = io.WriteString(lp.Writer, s)
+	return err
+}
@@ -0,0 +1,226 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema is the JSON Schema (draft-07) document generated from the go types
+type JSONSchema struct {
+	Schema               string                 `yaml:"$schema,omitempty" json:"$schema,omitempty" bson:"$schema" validate:""`
+	Title                string                 `yaml:"title,omitempty" json:"title,omitempty" bson:"title" validate:""`
+	Type                 string                 `yaml:"type,omitempty" json:"type,omitempty" bson:"type" validate:""`
+	Format               string                 `yaml:"format,omitempty" json:"format,omitempty" bson:"format" validate:""`
+	Enum                 []interface{}          `yaml:"enum,omitempty" json:"enum,omitempty" bson:"enum" validate:""`
+	MinLength            *int                   `yaml:"minLength,omitempty" json:"minLength,omitempty" bson:"minLength" validate:""`
+	Minimum              *float64               `yaml:"minimum,omitempty" json:"minimum,omitempty" bson:"minimum" validate:""`
+	Maximum              *float64               `yaml:"maximum,omitempty" json:"maximum,omitempty" bson:"maximum" validate:""`
+	ExclusiveMinimum     *float64               `yaml:"exclusiveMinimum,omitempty" json:"exclusiveMinimum,omitempty" bson:"exclusiveMinimum" validate:""`
+	ExclusiveMaximum     *float64               `yaml:"exclusiveMaximum,omitempty" json:"exclusiveMaximum,omitempty" bson:"exclusiveMaximum" validate:""`
+	Properties           map[string]*JSONSchema `yaml:"properties,omitempty" json:"properties,omitempty" bson:"properties" validate:""`
+	Required             []string               `yaml:"required,omitempty" json:"required,omitempty" bson:"required" validate:""`
+	AdditionalProperties interface{}            `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty" bson:"additionalProperties" validate:""`
+	Items                *JSONSchema            `yaml:"items,omitempty" json:"items,omitempty" bson:"items" validate:""`
+}
+
+var (
+	// DefKindItemTypes is the item type of each project definitions kind
+	DefKindItemTypes = map[string]reflect.Type{
+		"buildDefs":           reflect.TypeOf(BuildDef{}),
+		"packageDefs":         reflect.TypeOf(PackageDef{}),
+		"deployContainerDefs": reflect.TypeOf(DeployContainerDef{}),
+		"customStepDef":       reflect.TypeOf(CustomStepModuleDef{}),
+		"pipelineDef":         reflect.TypeOf(PipelineDef{}),
+		"customOpsDefs":       reflect.TypeOf(CustomOpsDef{}),
+		"dockerIgnoreDefs":    reflect.TypeOf(""),
+	}
+
+	// AdminKindSpecTypes is the spec type of each admin configurations kind
+	AdminKindSpecTypes = map[string]reflect.Type{
+		"user":              reflect.TypeOf(User{}),
+		"customStepConf":    reflect.TypeOf(CustomStepConf{}),
+		"envK8s":            reflect.TypeOf(EnvK8s{}),
+		"componentTemplate": reflect.TypeOf(ComponentTemplate{}),
+	}
+)
+
+// SchemaNames return all the schema names in order: project definitions kinds, admin configurations kinds and installConfig
+func SchemaNames() []string {
+	names := []string{}
+	names = append(names, DefKindsApplyOrder...)
+	adminKinds := []string{}
+	for kind := range AdminKindSpecTypes {
+		adminKinds = append(adminKinds, kind)
+	}
+	sort.Strings(adminKinds)
+	names = append(names, adminKinds...)
+	names = append(names, SchemaNameInstallConfig)
+	return names
+}
+
+// GetSchema return the JSON Schema document of the project definitions kind, admin configurations kind or installConfig
+func GetSchema(name string) (*JSONSchema, error) {
+	var err error
+	var schema *JSONSchema
+	if itemType, ok := DefKindItemTypes[name]; ok {
+		schema = NewJSONSchema(reflect.TypeOf(DefKind{}))
+		schema.Title = fmt.Sprintf("%s project definitions %s", BaseCmdName, name)
+		schema.Properties["kind"].Enum = []interface{}{name}
+		schema.Properties["items"].Items = NewJSONSchema(itemType)
+	} else if specType, ok := AdminKindSpecTypes[name]; ok {
+		schema = NewJSONSchema(reflect.TypeOf(AdminKind{}))
+		schema.Title = fmt.Sprintf("%s admin configurations %s", BaseCmdName, name)
+		schema.Properties["kind"].Enum = []interface{}{name}
+		schema.Properties["spec"] = NewJSONSchema(specType)
+	} else if name == SchemaNameInstallConfig {
+		schema = NewJSONSchema(reflect.TypeOf(InstallConfig{}))
+		schema.Title = fmt.Sprintf("%s install config", BaseCmdName)
+	} else {
+		err = fmt.Errorf("schema %s not exists, options: %s", name, strings.Join(SchemaNames(), " / "))
+		return schema, err
+	}
+	schema.Schema = JSONSchemaDraft
+	return schema, err
+}
+
+// NewJSONSchema generate the JSON Schema of the go type, properties names are the yaml tags,
+// validate tags required, oneof, gt, gte, lt, lte, min and max are converted to the schema keywords
+func NewJSONSchema(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	schema := &JSONSchema{}
+	if t == reflect.TypeOf(time.Time{}) {
+		schema.Type = "string"
+		schema.Format = "date-time"
+		return schema
+	}
+	switch t.Kind() {
+	case reflect.String:
+		schema.Type = "string"
+	case reflect.Bool:
+		schema.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema.Type = "number"
+	case reflect.Slice, reflect.Array:
+		schema.Type = "array"
+		schema.Items = NewJSONSchema(t.Elem())
+	case reflect.Map:
+		schema.Type = "object"
+		schema.AdditionalProperties = NewJSONSchema(t.Elem())
+	case reflect.Struct:
+		schema.Type = "object"
+		schema.Properties = map[string]*JSONSchema{}
+		schema.AdditionalProperties = false
+		addSchemaFields(schema, t)
+	}
+	return schema
+}
+
+func addSchemaFields(schema *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag, ok := field.Tag.Lookup("yaml")
+		name := strings.SplitN(tag, ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && (!ok || strings.Contains(tag, ",inline")) {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addSchemaFields(schema, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fieldSchema := NewJSONSchema(field.Type)
+		if addSchemaValidate(fieldSchema, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fieldSchema
+	}
+}
+
+// addSchemaValidate convert the validate tag to the schema keywords, return true if the field is required,
+// rules after dive are for the array items
+func addSchemaValidate(schema *JSONSchema, tag string) bool {
+	var required bool
+	var omitEmpty bool
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		arr := strings.SplitN(rule, "=", 2)
+		var param string
+		if len(arr) == 2 {
+			param = arr[1]
+		}
+		switch arr[0] {
+		case "dive":
+			if schema.Items != nil {
+				addSchemaValidate(schema.Items, strings.Join(rules[i+1:], ","))
+			} else if schema.AdditionalProperties != nil {
+				if itemSchema, ok := schema.AdditionalProperties.(*JSONSchema); ok {
+					addSchemaValidate(itemSchema, strings.Join(rules[i+1:], ","))
+				}
+			}
+			return required
+		case "required":
+			// required is not checked on struct fields by the validator
+			if schema.Type == "object" && schema.Properties != nil {
+				continue
+			}
+			required = true
+			if schema.Type == "string" {
+				minLength := 1
+				schema.MinLength = &minLength
+			}
+		case "omitempty":
+			omitEmpty = true
+		case "oneof":
+			for _, s := range strings.Fields(param) {
+				switch schema.Type {
+				case "integer", "number":
+					f, err := strconv.ParseFloat(s, 64)
+					if err == nil {
+						schema.Enum = append(schema.Enum, f)
+					}
+				default:
+					schema.Enum = append(schema.Enum, s)
+				}
+			}
+			if omitEmpty && schema.Type == "string" {
+				schema.Enum = append(schema.Enum, "")
+			}
+		case "gt", "gte", "lt", "lte", "min", "max":
+			f, err := strconv.ParseFloat(param, 64)
+			if err != nil || (schema.Type != "integer" && schema.Type != "number") {
+				continue
+			}
+			switch arr[0] {
+			case "gt":
+				schema.ExclusiveMinimum = &f
+			case "gte", "min":
+				schema.Minimum = &f
+			case "lt":
+				schema.ExclusiveMaximum = &f
+			case "lte", "max":
+				schema.Maximum = &f
+			}
+		}
+	}
+	return required
+}
@@ -0,0 +1,185 @@
+package pkg
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// SelectorRequirement is one requirement of the selector, example: envName=test, envName!=test, envName in (test,uat), envName, !envName
+type SelectorRequirement struct {
+	Key      string   `yaml:"key" json:"key" bson:"key" validate:""`
+	Operator string   `yaml:"operator" json:"operator" bson:"operator" validate:""`
+	Values   []string `yaml:"values" json:"values" bson:"values" validate:""`
+}
+
+// Selector select definitions by metadata labels or annotations, all requirements must be matched
+type Selector []SelectorRequirement
+
+var selectorSetRegexp = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// ParseSelector parse selector string to requirements, requirements are separated by comma,
+// format: key=value, key==value, key!=value, key in (value1,value2), key notin (value1,value2), key, !key
+func ParseSelector(s string) (Selector, error) {
+	var err error
+	selector := Selector{}
+	for _, item := range splitSelector(s) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		var sr SelectorRequirement
+		switch {
+		case selectorSetRegexp.MatchString(item):
+			arr := selectorSetRegexp.FindStringSubmatch(item)
+			sr.Key = arr[1]
+			sr.Operator = SelectorOpIn
+			if arr[2] == "notin" {
+				sr.Operator = SelectorOpNotIn
+			}
+			for _, value := range strings.Split(arr[3], ",") {
+				value = strings.TrimSpace(value)
+				if value != "" {
+					sr.Values = append(sr.Values, value)
+				}
+			}
+			if len(sr.Values) == 0 {
+				err = fmt.Errorf("%s values required", item)
+				return selector, err
+			}
+		case strings.HasPrefix(item, "!") && !strings.Contains(item, "="):
+			sr.Key = strings.TrimSpace(strings.TrimPrefix(item, "!"))
+			sr.Operator = SelectorOpNotExists
+		case strings.Contains(item, "!="):
+			arr := strings.SplitN(item, "!=", 2)
+			sr.Key = strings.TrimSpace(arr[0])
+			sr.Operator = SelectorOpNotEquals
+			sr.Values = []string{strings.TrimSpace(arr[1])}
+		case strings.Contains(item, "=="):
+			arr := strings.SplitN(item, "==", 2)
+			sr.Key = strings.TrimSpace(arr[0])
+			sr.Operator = SelectorOpEquals
+			sr.Values = []string{strings.TrimSpace(arr[1])}
+		case strings.Contains(item, "="):
+			arr := strings.SplitN(item, "=", 2)
+			sr.Key = strings.TrimSpace(arr[0])
+			sr.Operator = SelectorOpEquals
+			sr.Values = []string{strings.TrimSpace(arr[1])}
+		default:
+			sr.Key = item
+			sr.Operator = SelectorOpExists
+		}
+		if sr.Key == "" || strings.ContainsAny(sr.Key, " \t()!=") {
+			err = fmt.Errorf("%s key format error", item)
+			return selector, err
+		}
+		selector = append(selector, sr)
+	}
+	return selector, err
+}
+
+// splitSelector split selector string by comma, commas in parentheses are ignored
+func splitSelector(s string) []string {
+	items := []string{}
+	var depth, start int
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				items = append(items, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	items = append(items, s[start:])
+	return items
+}
+
+// Match check metadata labels or annotations match all requirements, empty selector match all metadata
+func (s Selector) Match(metadata DefMetadata) bool {
+	for _, sr := range s {
+		if !sr.Match(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match check metadata labels or annotations match the requirement, a key can be found in both labels and annotations
+func (sr SelectorRequirement) Match(metadata DefMetadata) bool {
+	values := []string{}
+	if v, ok := metadata.Labels[sr.Key]; ok {
+		values = append(values, v)
+	}
+	if v, ok := metadata.Annotations[sr.Key]; ok {
+		values = append(values, v)
+	}
+	var found bool
+	for _, v := range values {
+		for _, value := range sr.Values {
+			if v == value {
+				found = true
+				break
+			}
+		}
+	}
+	switch sr.Operator {
+	case SelectorOpEquals, SelectorOpIn:
+		return found
+	case SelectorOpNotEquals, SelectorOpNotIn:
+		return !found
+	case SelectorOpExists:
+		return len(values) > 0
+	case SelectorOpNotExists:
+		return len(values) == 0
+	}
+	return false
+}
+
+// NamePattern match names by glob pattern, or by regular expression with regex: prefix, the regular expression must match the whole name
+type NamePattern struct {
+	Pattern string `yaml:"pattern" json:"pattern" bson:"pattern" validate:""`
+	regexp  *regexp.Regexp
+}
+
+// ParseNamePattern parse the glob pattern or regular expression, example: *-api, tp1-*-demo, regex:tp1-(go|gin)-demo
+func ParseNamePattern(s string) (NamePattern, error) {
+	var err error
+	np := NamePattern{Pattern: s}
+	if strings.HasPrefix(s, NamePatternRegexPrefix) {
+		expr := strings.TrimPrefix(s, NamePatternRegexPrefix)
+		if expr == "" {
+			err = fmt.Errorf("regular expression required")
+			return np, err
+		}
+		np.regexp, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", expr))
+		if err != nil {
+			return np, err
+		}
+	} else {
+		_, err = path.Match(s, "")
+		if err != nil {
+			return np, err
+		}
+	}
+	return np, err
+}
+
+// Match check the name match the pattern, empty pattern match all names
+func (np NamePattern) Match(name string) bool {
+	if np.Pattern == "" {
+		return true
+	}
+	if np.regexp != nil {
+		return np.regexp.MatchString(name)
+	}
+	ok, _ := path.Match(np.Pattern, name)
+	return ok
+}
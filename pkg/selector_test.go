@@ -0,0 +1,152 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Selector
+	}{
+		{s: "", want: Selector{}},
+		{s: " , ", want: Selector{}},
+		{s: "envName=test", want: Selector{{Key: "envName", Operator: SelectorOpEquals, Values: []string{"test"}}}},
+		{s: "envName==test", want: Selector{{Key: "envName", Operator: SelectorOpEquals, Values: []string{"test"}}}},
+		{s: " envName = test ", want: Selector{{Key: "envName", Operator: SelectorOpEquals, Values: []string{"test"}}}},
+		{s: "envName=", want: Selector{{Key: "envName", Operator: SelectorOpEquals, Values: []string{""}}}},
+		{s: "envName=a=b", want: Selector{{Key: "envName", Operator: SelectorOpEquals, Values: []string{"a=b"}}}},
+		{s: "envName!=test", want: Selector{{Key: "envName", Operator: SelectorOpNotEquals, Values: []string{"test"}}}},
+		{s: "envName in (test,uat)", want: Selector{{Key: "envName", Operator: SelectorOpIn, Values: []string{"test", "uat"}}}},
+		{s: "envName in(test, uat, )", want: Selector{{Key: "envName", Operator: SelectorOpIn, Values: []string{"test", "uat"}}}},
+		{s: "envName notin (prod)", want: Selector{{Key: "envName", Operator: SelectorOpNotIn, Values: []string{"prod"}}}},
+		{s: "envName", want: Selector{{Key: "envName", Operator: SelectorOpExists}}},
+		{s: "!envName", want: Selector{{Key: "envName", Operator: SelectorOpNotExists}}},
+		{s: "! envName", want: Selector{{Key: "envName", Operator: SelectorOpNotExists}}},
+		{
+			s: "envName in (test,uat),stepName!=scanCode,!skip,team",
+			want: Selector{
+				{Key: "envName", Operator: SelectorOpIn, Values: []string{"test", "uat"}},
+				{Key: "stepName", Operator: SelectorOpNotEquals, Values: []string{"scanCode"}},
+				{Key: "skip", Operator: SelectorOpNotExists},
+				{Key: "team", Operator: SelectorOpExists},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseSelector(tt.s)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q) error: %s", tt.s, err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSelector(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []string{
+		"=test",
+		"!=test",
+		"==test",
+		"!",
+		"!envName=test",
+		"env name=test",
+		"envName in ()",
+		"envName in ( , )",
+		"envName notin ()",
+		"envName in (test",
+		"envName in test",
+		"in (test)",
+		"envName=test,!",
+	}
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			got, err := ParseSelector(s)
+			if err == nil {
+				t.Errorf("ParseSelector(%q) = %v, want error", s, got)
+			}
+		})
+	}
+}
+
+func TestSelectorMatch(t *testing.T) {
+	metadata := DefMetadata{
+		Labels:      map[string]string{"envName": "test", "team": ""},
+		Annotations: map[string]string{"stepName": "scanCode"},
+	}
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "", want: true},
+		{s: "envName=test", want: true},
+		{s: "envName=uat", want: false},
+		{s: "envName!=uat", want: true},
+		{s: "envName!=test", want: false},
+		{s: "missing!=test", want: true},
+		{s: "envName in (uat,test)", want: true},
+		{s: "envName in (uat,prod)", want: false},
+		{s: "envName notin (uat,prod)", want: true},
+		{s: "envName notin (test)", want: false},
+		{s: "missing notin (test)", want: true},
+		{s: "envName", want: true},
+		{s: "team", want: true},
+		{s: "missing", want: false},
+		{s: "!missing", want: true},
+		{s: "!envName", want: false},
+		{s: "stepName=scanCode", want: true},
+		{s: "!stepName", want: false},
+		{s: "envName=test,stepName=scanCode", want: true},
+		{s: "envName=test,stepName=build", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			selector, err := ParseSelector(tt.s)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q) error: %s", tt.s, err.Error())
+			}
+			got := selector.Match(metadata)
+			if got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "", name: "tp1-go-demo", want: true},
+		{pattern: "tp1-*-demo", name: "tp1-go-demo", want: true},
+		{pattern: "*-api", name: "tp1-go-demo", want: false},
+		{pattern: "regex:tp1-(go|gin)-demo", name: "tp1-gin-demo", want: true},
+		{pattern: "regex:tp1-(go|gin)-demo", name: "tp1-node-demo", want: false},
+		{pattern: "regex:go", name: "tp1-go-demo", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			np, err := ParseNamePattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("ParseNamePattern(%q) error: %s", tt.pattern, err.Error())
+			}
+			got := np.Match(tt.name)
+			if got != tt.want {
+				t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"regex:", "regex:(", "tp1-[-demo"} {
+		_, err := ParseNamePattern(pattern)
+		if err == nil {
+			t.Errorf("ParseNamePattern(%q) want error", pattern)
+		}
+	}
+}
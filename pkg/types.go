@@ -2,12 +2,30 @@ package pkg
 
 import "time"
 
+type DoryContext struct {
+	Name                 string   `yaml:"name" json:"name" bson:"name" validate:""`
+	ServerURL            string   `yaml:"serverURL" json:"serverURL" bson:"serverURL" validate:""`
+	Insecure             bool     `yaml:"insecure" json:"insecure" bson:"insecure" validate:""`
+	CertificateAuthority string   `yaml:"certificateAuthority,omitempty" json:"certificateAuthority,omitempty" bson:"certificateAuthority,omitempty" validate:""`
+	ClientCertificate    string   `yaml:"clientCertificate,omitempty" json:"clientCertificate,omitempty" bson:"clientCertificate,omitempty" validate:""`
+	ClientKey            string   `yaml:"clientKey,omitempty" json:"clientKey,omitempty" bson:"clientKey,omitempty" validate:""`
+	Timeout              int      `yaml:"timeout" json:"timeout" bson:"timeout" validate:""`
+	Retries              *int     `yaml:"retries,omitempty" json:"retries,omitempty" bson:"retries,omitempty" validate:""`
+	RetryMaxWait         int      `yaml:"retryMaxWait,omitempty" json:"retryMaxWait,omitempty" bson:"retryMaxWait,omitempty" validate:""`
+	RetryMethods         []string `yaml:"retryMethods,omitempty" json:"retryMethods,omitempty" bson:"retryMethods,omitempty" validate:""`
+	AccessToken          string   `yaml:"accessToken" json:"accessToken" bson:"accessToken" validate:""`
+	Language             string   `yaml:"language" json:"language" bson:"language" validate:""`
+}
+
 type DoryConfig struct {
-	ServerURL   string `yaml:"serverURL" json:"serverURL" bson:"serverURL" validate:""`
-	Insecure    bool   `yaml:"insecure" json:"insecure" bson:"insecure" validate:""`
-	Timeout     int    `yaml:"timeout" json:"timeout" bson:"timeout" validate:""`
-	AccessToken string `yaml:"accessToken" json:"accessToken" bson:"accessToken" validate:""`
-	Language    string `yaml:"language" json:"language" bson:"language" validate:""`
+	CurrentContext string        `yaml:"currentContext" json:"currentContext" bson:"currentContext" validate:""`
+	Contexts       []DoryContext `yaml:"contexts" json:"contexts" bson:"contexts" validate:""`
+	// legacy single server settings, migrate to context default when read
+	ServerURL   string `yaml:"serverURL,omitempty" json:"serverURL,omitempty" bson:"serverURL,omitempty" validate:""`
+	Insecure    bool   `yaml:"insecure,omitempty" json:"insecure,omitempty" bson:"insecure,omitempty" validate:""`
+	Timeout     int    `yaml:"timeout,omitempty" json:"timeout,omitempty" bson:"timeout,omitempty" validate:""`
+	AccessToken string `yaml:"accessToken,omitempty" json:"accessToken,omitempty" bson:"accessToken,omitempty" validate:""`
+	Language    string `yaml:"language,omitempty" json:"language,omitempty" bson:"language,omitempty" validate:""`
 }
 
 type InstallDockerImage struct {
@@ -97,8 +115,10 @@ type InstallConfig struct {
 		} `yaml:"dorycore" json:"dorycore" bson:"dorycore" validate:"required"`
 	} `yaml:"dory" json:"dory" bson:"dory" validate:"required"`
 	ImageRepo struct {
-		Type     string `yaml:"type" json:"type" bson:"type" validate:"required"`
-		Internal struct {
+		Type      string `yaml:"type" json:"type" bson:"type" validate:"required"`
+		Insecure  bool   `yaml:"insecure" json:"insecure" bson:"insecure" validate:""`
+		CaCrtPath string `yaml:"caCrtPath" json:"caCrtPath" bson:"caCrtPath" validate:""`
+		Internal  struct {
 			DomainName       string `yaml:"domainName" json:"domainName" bson:"domainName" validate:"required_with=DomainName Namespace Version"`
 			Namespace        string `yaml:"namespace" json:"namespace" bson:"namespace" validate:"required_with=DomainName Namespace Version"`
 			Version          string `yaml:"version" json:"version" bson:"version" validate:"required_with=DomainName Namespace Version"`
@@ -139,6 +159,10 @@ type InstallConfig struct {
 		Host          string `yaml:"host" json:"host" bson:"host" validate:"required"`
 		Port          int    `yaml:"port" json:"port" bson:"port" validate:"required"`
 		Token         string `yaml:"token" json:"token" bson:"token" validate:"required"`
+		Insecure      bool   `yaml:"insecure" json:"insecure" bson:"insecure" validate:""`
+		CaCrtPath     string `yaml:"caCrtPath" json:"caCrtPath" bson:"caCrtPath" validate:""`
+		ClientCrtPath string `yaml:"clientCrtPath" json:"clientCrtPath" bson:"clientCrtPath" validate:""`
+		ClientKeyPath string `yaml:"clientKeyPath" json:"clientKeyPath" bson:"clientKeyPath" validate:""`
 		PvConfigLocal struct {
 			LocalPath string `yaml:"localPath" json:"localPath" bson:"localPath" validate:""`
 		} `yaml:"pvConfigLocal" json:"pvConfigLocal" bson:"pvConfigLocal" validate:""`
@@ -301,6 +325,38 @@ type Run struct {
 	} `yaml:"status" json:"status" bson:"status" validate:""`
 }
 
+type RunSummary struct {
+	RunName      string `yaml:"runName" json:"runName" bson:"runName" validate:""`
+	ProjectName  string `yaml:"projectName" json:"projectName" bson:"projectName" validate:""`
+	PipelineName string `yaml:"pipelineName" json:"pipelineName" bson:"pipelineName" validate:""`
+	StartUser    string `yaml:"startUser" json:"startUser" bson:"startUser" validate:""`
+	AbortUser    string `yaml:"abortUser" json:"abortUser" bson:"abortUser" validate:""`
+	Result       string `yaml:"result" json:"result" bson:"result" validate:""`
+	StartTime    string `yaml:"startTime" json:"startTime" bson:"startTime" validate:""`
+	Duration     string `yaml:"duration" json:"duration" bson:"duration" validate:""`
+	TimedOut     bool   `yaml:"timedOut" json:"timedOut" bson:"timedOut" validate:""`
+	ExitCode     int    `yaml:"exitCode" json:"exitCode" bson:"exitCode" validate:""`
+}
+
+type RunStatsItem struct {
+	Name         string  `yaml:"name" json:"name" bson:"name" validate:""`
+	RunCount     int     `yaml:"runCount" json:"runCount" bson:"runCount" validate:""`
+	SuccessCount int     `yaml:"successCount" json:"successCount" bson:"successCount" validate:""`
+	FailCount    int     `yaml:"failCount" json:"failCount" bson:"failCount" validate:""`
+	AbortCount   int     `yaml:"abortCount" json:"abortCount" bson:"abortCount" validate:""`
+	SuccessRate  float64 `yaml:"successRate" json:"successRate" bson:"successRate" validate:""`
+	DurationMean string  `yaml:"durationMean" json:"durationMean" bson:"durationMean" validate:""`
+	DurationP95  string  `yaml:"durationP95" json:"durationP95" bson:"durationP95" validate:""`
+}
+
+type RunStats struct {
+	StartDate string         `yaml:"startDate" json:"startDate" bson:"startDate" validate:""`
+	EndDate   string         `yaml:"endDate" json:"endDate" bson:"endDate" validate:""`
+	Total     RunStatsItem   `yaml:"total" json:"total" bson:"total" validate:""`
+	Users     []RunStatsItem `yaml:"users" json:"users" bson:"users" validate:""`
+	Pipelines []RunStatsItem `yaml:"pipelines" json:"pipelines" bson:"pipelines" validate:""`
+}
+
 type RunInputOption struct {
 	Name  string `yaml:"name" json:"name" bson:"name" validate:""`
 	Value string `yaml:"value" json:"value" bson:"value" validate:""`
@@ -314,6 +370,17 @@ type RunInput struct {
 	Options    []RunInputOption `yaml:"options" json:"options" bson:"options" validate:""`
 }
 
+type RunInputAnswer struct {
+	PhaseID string   `yaml:"phaseID" json:"phaseID" bson:"phaseID" validate:""`
+	Title   string   `yaml:"title" json:"title" bson:"title" validate:""`
+	Values  []string `yaml:"values" json:"values" bson:"values" validate:""`
+}
+
+type RunInputAnswers struct {
+	Unanswered string           `yaml:"unanswered" json:"unanswered" bson:"unanswered" validate:""`
+	Answers    []RunInputAnswer `yaml:"answers" json:"answers" bson:"answers" validate:""`
+}
+
 type WsRunLog struct {
 	ID         string `yaml:"ID" json:"ID" bson:"ID" validate:""`
 	LogType    string `yaml:"logType" json:"logType" bson:"logType" validate:""`
@@ -848,6 +915,31 @@ type ProjectAdd struct {
 	EnvName          string `yaml:"envName" json:"envName" bson:"envName" validate:"required"`
 }
 
+type ProjectExportPipeline struct {
+	BranchName       string   `yaml:"branchName" json:"branchName" bson:"branchName" validate:"required"`
+	IsDefault        bool     `yaml:"isDefault" json:"isDefault" bson:"isDefault" validate:""`
+	WebhookPushEvent bool     `yaml:"webhookPushEvent" json:"webhookPushEvent" bson:"webhookPushEvent" validate:""`
+	TagSuffix        string   `yaml:"tagSuffix" json:"tagSuffix" bson:"tagSuffix" validate:""`
+	Envs             []string `yaml:"envs" json:"envs" bson:"envs" validate:""`
+	EnvProductions   []string `yaml:"envProductions" json:"envProductions" bson:"envProductions" validate:""`
+}
+
+type ProjectExport struct {
+	Kind        string                  `yaml:"kind" json:"kind" bson:"kind" validate:"required"`
+	Metadata    DefMetadata             `yaml:"metadata" json:"metadata" bson:"metadata" validate:"required"`
+	ProjectInfo ProjectInfo             `yaml:"projectInfo" json:"projectInfo" bson:"projectInfo" validate:""`
+	EnvNames    []string                `yaml:"envNames" json:"envNames" bson:"envNames" validate:""`
+	Pipelines   []ProjectExportPipeline `yaml:"pipelines" json:"pipelines" bson:"pipelines" validate:""`
+}
+
+type ProjectCheckIssue struct {
+	Severity string `yaml:"severity" json:"severity" bson:"severity" validate:""`
+	Rule     string `yaml:"rule" json:"rule" bson:"rule" validate:""`
+	Resource string `yaml:"resource" json:"resource" bson:"resource" validate:""`
+	EnvName  string `yaml:"envName" json:"envName" bson:"envName" validate:""`
+	Message  string `yaml:"message" json:"message" bson:"message" validate:""`
+}
+
 type AdminMetadata struct {
 	Name        string            `yaml:"name" json:"name" bson:"name" validate:""`
 	Annotations map[string]string `yaml:"annotations" json:"annotations" bson:"annotations" validate:""`